@@ -30,6 +30,22 @@ type CustomArgs struct {
 	// by API linter. If specified, API rule violations will be printed to report file.
 	// Otherwise default value "-" will be used which indicates stdout.
 	ReportFilename string
+
+	// MaxErrors is the number of type errors after which generation stops.
+	// The default of 1 stops at the first error; 0 reports all of them.
+	MaxErrors int
+
+	// EmitDefinitionHashes adds a GetOpenAPIDefinitionHashes function to the
+	// generated file, returning a content hash of each generated definition.
+	EmitDefinitionHashes bool
+
+	// EmitV3Definitions adds a GetOpenAPIV3Definitions function to the
+	// generated file, returning the native OpenAPI v3 definitions of the types.
+	EmitV3Definitions bool
+
+	// ClosedStructs sets additionalProperties to false in the schemas of all
+	// structs, except the ones tagged with +k8s:openapi-gen=open.
+	ClosedStructs bool
 }
 
 // NewDefaults returns default arguments for the generator. Returning the arguments instead
@@ -46,6 +62,8 @@ func NewDefaults() (*args.GeneratorArgs, *CustomArgs) {
 
 	// Default value for report filename is "-", which stands for stdout
 	customArgs.ReportFilename = "-"
+	// Default value for max errors, which fails on the first error
+	customArgs.MaxErrors = 1
 	// Default value for output file base name
 	genericArgs.OutputFileBaseName = "openapi_generated"
 
@@ -55,6 +73,10 @@ func NewDefaults() (*args.GeneratorArgs, *CustomArgs) {
 // AddFlags add the generator flags to the flag set.
 func (c *CustomArgs) AddFlags(fs *pflag.FlagSet) {
 	fs.StringVarP(&c.ReportFilename, "report-filename", "r", c.ReportFilename, "Name of report file used by API linter to print API violations. Default \"-\" stands for standard output. NOTE that if valid filename other than \"-\" is specified, API linter won't return error on detected API violations. This allows further check of existing API violations without stopping the OpenAPI generation toolchain.")
+	fs.IntVar(&c.MaxErrors, "max-errors", c.MaxErrors, "Number of type errors after which generation stops. Each error reports the file, line and type it was found at. 0 reports all errors.")
+	fs.BoolVar(&c.EmitDefinitionHashes, "emit-definition-hashes", c.EmitDefinitionHashes, "Generate a GetOpenAPIDefinitionHashes function returning a content hash of the generated definition of each type, to detect which definitions changed between builds.")
+	fs.BoolVar(&c.EmitV3Definitions, "emit-v3-definitions", c.EmitV3Definitions, "Generate a GetOpenAPIV3Definitions function returning the OpenAPI v3 definitions of the types with v3-only constructs such as oneOf and nullable preserved, for use as the GetDefinitions of an OpenAPIV3Config.")
+	fs.BoolVar(&c.ClosedStructs, "closed-structs", c.ClosedStructs, "Set additionalProperties to false in the schemas of all structs, so that properties other than their fields are rejected, except for the structs tagged with +k8s:openapi-gen=open. Structs inlining maps are left open. Individual structs can be closed with +k8s:openapi-gen=closed.")
 }
 
 // Validate checks the given arguments.
@@ -66,6 +88,9 @@ func Validate(genericArgs *args.GeneratorArgs) error {
 	if len(c.ReportFilename) == 0 {
 		return fmt.Errorf("report filename cannot be empty. specify a valid filename or use \"-\" for stdout")
 	}
+	if c.MaxErrors < 0 {
+		return fmt.Errorf("max errors cannot be negative")
+	}
 	if len(genericArgs.OutputFileBaseName) == 0 {
 		return fmt.Errorf("output file base name cannot be empty")
 	}
@@ -0,0 +1,56 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// openapi-mock serves mock responses for the operations of an OpenAPI v3
+// document read from a JSON file.
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/spf13/pflag"
+
+	"k8s.io/kube-openapi/pkg/mockserver"
+	"k8s.io/kube-openapi/pkg/spec3"
+)
+
+func main() {
+	specFile := pflag.String("spec", "", "Path to the OpenAPI v3 document to serve, in JSON.")
+	address := pflag.String("address", "localhost:8080", "Address to listen on.")
+	pflag.Parse()
+
+	if *specFile == "" {
+		log.Fatal("--spec is required")
+	}
+	data, err := os.ReadFile(*specFile)
+	if err != nil {
+		log.Fatalf("error reading %s: %v", *specFile, err)
+	}
+	doc := &spec3.OpenAPI{}
+	if err := json.Unmarshal(data, doc); err != nil {
+		log.Fatalf("error interpreting %s: %v", *specFile, err)
+	}
+	server, err := mockserver.New(doc)
+	if err != nil {
+		log.Fatalf("error routing %s: %v", *specFile, err)
+	}
+
+	log.Printf("serving %s on %s", *specFile, *address)
+	log.Fatal(http.ListenAndServe(*address, server))
+}
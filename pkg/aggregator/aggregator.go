@@ -139,26 +139,51 @@ func renameDefinition(s *spec.Swagger, renames map[string]string) *spec.Swagger
 // conflicts by keeping the paths of destination. It will rename definition conflicts.
 // The source is not mutated.
 func MergeSpecsIgnorePathConflict(dest, source *spec.Swagger) error {
-	return mergeSpecs(dest, source, true, true)
+	return MergeSpecsWithOptions(dest, source, MergeOptions{RenameDefinitionConflicts: true, IgnorePathConflicts: true})
 }
 
 // MergeSpecsFailOnDefinitionConflict is differ from MergeSpecs as it fails if there is
 // a definition conflict.
 // The source is not mutated.
 func MergeSpecsFailOnDefinitionConflict(dest, source *spec.Swagger) error {
-	return mergeSpecs(dest, source, false, false)
+	return MergeSpecsWithOptions(dest, source, MergeOptions{})
 }
 
 // MergeSpecs copies paths and definitions from source to dest, rename definitions if needed.
 // dest will be mutated, and source will not be changed. It will fail on path conflicts.
 // The source is not mutated.
 func MergeSpecs(dest, source *spec.Swagger) error {
-	return mergeSpecs(dest, source, true, false)
+	return MergeSpecsWithOptions(dest, source, MergeOptions{RenameDefinitionConflicts: true})
+}
+
+// MergeOptions tells MergeSpecsWithOptions how to merge a source spec.
+type MergeOptions struct {
+	// Source names the source spec in the errors returned, e.g. the
+	// APIService it is served by.
+	Source string
+	// RenameDefinitionConflicts renames the definitions of the source that
+	// the destination defines differently, instead of failing with a
+	// DefinitionConflictError.
+	RenameDefinitionConflicts bool
+	// IgnorePathConflicts keeps the paths of the destination that the source
+	// has as well, instead of failing with a PathConflictError.
+	IgnorePathConflicts bool
+}
+
+// MergeSpecsWithOptions merges source into dest like MergeSpecs, resolving
+// conflicts as told by opts. The errors returned are a *PathConflictError,
+// a *DefinitionConflictError or an *InvalidSourceError naming opts.Source.
+// The source is not mutated.
+func MergeSpecsWithOptions(dest, source *spec.Swagger, opts MergeOptions) error {
+	if source == nil {
+		return &InvalidSourceError{Source: opts.Source, Err: fmt.Errorf("spec is nil")}
+	}
+	return mergeSpecs(dest, source, opts)
 }
 
 // mergeSpecs merges source into dest while resolving conflicts.
 // The source is not mutated.
-func mergeSpecs(dest, source *spec.Swagger, renameModelConflicts, ignorePathConflicts bool) (err error) {
+func mergeSpecs(dest, source *spec.Swagger, opts MergeOptions) (err error) {
 	// Paths may be empty, due to [ACL constraints](http://goo.gl/8us55a#securityFiltering).
 	if source.Paths == nil {
 		// When a source spec does not have any path, that means none of the definitions
@@ -168,7 +193,7 @@ func mergeSpecs(dest, source *spec.Swagger, renameModelConflicts, ignorePathConf
 	if dest.Paths == nil {
 		dest.Paths = &spec.Paths{}
 	}
-	if ignorePathConflicts {
+	if opts.IgnorePathConflicts {
 		keepPaths := []string{}
 		hasConflictingPath := false
 		for k := range source.Paths.Paths {
@@ -201,8 +226,8 @@ DEFINITIONLOOP:
 			continue
 		}
 
-		if !renameModelConflicts {
-			return fmt.Errorf("model name conflict in merging OpenAPI spec: %s", k)
+		if !opts.RenameDefinitionConflicts {
+			return &DefinitionConflictError{Source: opts.Source, Definition: k}
 		}
 
 		// Reuse previously renamed model if one exists
@@ -237,7 +262,7 @@ DEFINITIONLOOP:
 			}
 			dest.Definitions[k] = v
 		} else if merged, changed, err := mergedGVKs(&existing, &v); err != nil {
-			return err
+			return &InvalidSourceError{Source: opts.Source, Definition: k, Err: err}
 		} else if changed {
 			existing.Extensions[gvkKey] = merged
 		}
@@ -246,7 +271,7 @@ DEFINITIONLOOP:
 	// Check for path conflicts
 	for k, v := range source.Paths.Paths {
 		if _, found := dest.Paths.Paths[k]; found {
-			return fmt.Errorf("unable to merge: duplicated path %s", k)
+			return &PathConflictError{Source: opts.Source, Path: k}
 		}
 		// PathItem may be empty, due to [ACL constraints](http://goo.gl/8us55a#securityFiltering).
 		if dest.Paths.Paths == nil {
@@ -316,11 +341,11 @@ func mergedGVKs(s1, s2 *spec.Schema) (interface{}, bool, error) {
 
 	slice1, ok := gvk1.([]interface{})
 	if !ok {
-		return nil, false, fmt.Errorf("expected slice of GroupVersionKinds, got: %+v", slice1)
+		return nil, false, fmt.Errorf("expected slice of GroupVersionKinds, got: %+v", gvk1)
 	}
 	slice2, ok := gvk2.([]interface{})
 	if !ok {
-		return nil, false, fmt.Errorf("expected slice of GroupVersionKinds, got: %+v", slice2)
+		return nil, false, fmt.Errorf("expected slice of GroupVersionKinds, got: %+v", gvk2)
 	}
 
 	ret := make([]interface{}, len(slice1), len(slice1)+len(slice2))
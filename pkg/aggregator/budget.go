@@ -0,0 +1,125 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aggregator
+
+import (
+	"encoding/json"
+	"sync"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+// EstimateSize returns the approximate memory retained by s, in bytes.
+// It is estimated by the size of the JSON serialization of s, which grows
+// in proportion to the number and size of its paths and definitions.
+func EstimateSize(s *spec.Swagger) (int64, error) {
+	if s == nil {
+		return 0, nil
+	}
+	b, err := json.Marshal(s)
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(b)), nil
+}
+
+// MemoryUsage is the approximate memory retained by a merged spec.
+type MemoryUsage struct {
+	// Total is the sum of the contributions of all sources, in bytes.
+	// Definitions merged from several sources are counted for each of
+	// them, so it may overestimate the size of the merged spec.
+	Total int64
+	// Sources holds the contribution of each source, in bytes.
+	Sources map[string]int64
+}
+
+// MemoryBudget accounts for the memory retained by the sources merged into
+// a spec, and reports the sources that take it over budget. It is safe for
+// concurrent use.
+//
+// Callers record the source specs they merge with Record, and forget them
+// with Remove once they are not merged anymore. Usage can be exported as a
+// metric, for instance to find the CRDs that grow the spec the most.
+type MemoryBudget struct {
+	// Limit is the budget of the total usage in bytes. Zero means no limit.
+	Limit int64
+	// SourceLimit is the budget of a single source in bytes. Zero means no
+	// limit.
+	SourceLimit int64
+	// OnExceeded, if not nil, is called with the source and the usage when
+	// recording the source takes the usage over one of the limits.
+	OnExceeded func(source string, usage MemoryUsage)
+
+	mu      sync.Mutex
+	total   int64
+	sources map[string]int64
+}
+
+// Record sets the contribution of the named source to the size of spec s,
+// replacing any previously recorded contribution of that source.
+func (b *MemoryBudget) Record(source string, s *spec.Swagger) error {
+	size, err := EstimateSize(s)
+	if err != nil {
+		return err
+	}
+	b.RecordSize(source, size)
+	return nil
+}
+
+// RecordSize sets the contribution of the named source to size bytes.
+func (b *MemoryBudget) RecordSize(source string, size int64) {
+	b.mu.Lock()
+	if b.sources == nil {
+		b.sources = map[string]int64{}
+	}
+	b.total += size - b.sources[source]
+	b.sources[source] = size
+	exceeded := (b.Limit > 0 && b.total > b.Limit) || (b.SourceLimit > 0 && size > b.SourceLimit)
+	var usage MemoryUsage
+	if exceeded && b.OnExceeded != nil {
+		usage = b.usageLocked()
+	}
+	b.mu.Unlock()
+
+	// called without holding the lock, so that it can use the budget
+	if exceeded && b.OnExceeded != nil {
+		b.OnExceeded(source, usage)
+	}
+}
+
+// Remove forgets the contribution of the named source.
+func (b *MemoryBudget) Remove(source string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.total -= b.sources[source]
+	delete(b.sources, source)
+}
+
+// Usage returns the current usage.
+func (b *MemoryBudget) Usage() MemoryUsage {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.usageLocked()
+}
+
+func (b *MemoryBudget) usageLocked() MemoryUsage {
+	usage := MemoryUsage{Total: b.total, Sources: make(map[string]int64, len(b.sources))}
+	for source, size := range b.sources {
+		usage.Sources[source] = size
+	}
+	return usage
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aggregator
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+func TestEstimateSize(t *testing.T) {
+	size, err := EstimateSize(nil)
+	assert.NoError(t, err)
+	assert.Zero(t, size)
+
+	s := &spec.Swagger{SwaggerProps: spec.SwaggerProps{
+		Definitions: spec.Definitions{"Foo": *spec.StringProperty()},
+	}}
+	b, err := json.Marshal(s)
+	assert.NoError(t, err)
+	size, err = EstimateSize(s)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(len(b)), size)
+}
+
+func TestMemoryBudget(t *testing.T) {
+	type exceeded struct {
+		source string
+		total  int64
+	}
+	var calls []exceeded
+	b := &MemoryBudget{
+		Limit:       100,
+		SourceLimit: 60,
+		OnExceeded: func(source string, usage MemoryUsage) {
+			calls = append(calls, exceeded{source, usage.Total})
+		},
+	}
+
+	b.RecordSize("a", 40)
+	b.RecordSize("b", 50)
+	assert.Empty(t, calls)
+	assert.Equal(t, MemoryUsage{Total: 90, Sources: map[string]int64{"a": 40, "b": 50}}, b.Usage())
+
+	b.RecordSize("c", 20)
+	assert.Equal(t, []exceeded{{"c", 110}}, calls, "total limit")
+
+	b.Remove("c")
+	b.RecordSize("a", 45)
+	assert.Len(t, calls, 1, "replacing a contribution should not count it twice")
+	assert.Equal(t, int64(95), b.Usage().Total)
+
+	b.Remove("a")
+	b.RecordSize("b", 70)
+	assert.Equal(t, exceeded{"b", 70}, calls[1], "source limit")
+
+	usage := b.Usage()
+	usage.Sources["b"] = 0
+	assert.Equal(t, int64(70), b.Usage().Sources["b"], "usage should be a copy")
+}
@@ -0,0 +1,281 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aggregator
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+// DeduplicateParametersAndResponses moves the inline parameters and
+// responses that are used more than once across the operations of sp into
+// the top-level parameters and responses sections, and replaces them by
+// references. This considerably shrinks specs made of many similar paths,
+// like the ones of custom resources.
+//
+// Shared parameters are named after the parameter and a hash of its
+// content, e.g. "fieldManager-Qy4HdaTW", and shared responses "response-"
+// followed by such a hash. Top-level parameters and responses identical to
+// an inline one are reused.
+//
+// It does not modify the input, but the output shares data structures with
+// the input.
+func DeduplicateParametersAndResponses(sp *spec.Swagger) (*spec.Swagger, error) {
+	if sp.Paths == nil {
+		return sp, nil
+	}
+	d := &deduplicator{
+		paramCounts:    map[string]int{},
+		responseCounts: map[string]int{},
+		paramNames:     map[string]string{},
+		responseNames:  map[string]string{},
+	}
+	if err := d.count(sp); err != nil {
+		return nil, err
+	}
+
+	ret := *sp
+	ret.Parameters = make(map[string]spec.Parameter, len(sp.Parameters))
+	for name, p := range sp.Parameters {
+		ret.Parameters[name] = p
+		if key, err := contentKey(p); err == nil {
+			d.paramNames[key] = name
+		}
+	}
+	ret.Responses = make(map[string]spec.Response, len(sp.Responses))
+	for name, r := range sp.Responses {
+		ret.Responses[name] = r
+		if key, err := contentKey(r); err == nil {
+			d.responseNames[key] = name
+		}
+	}
+	d.params = ret.Parameters
+	d.responses = ret.Responses
+
+	ret.Paths = &spec.Paths{
+		VendorExtensible: sp.Paths.VendorExtensible,
+		Paths:            make(map[string]spec.PathItem, len(sp.Paths.Paths)),
+	}
+	for path, item := range sp.Paths.Paths {
+		item.Parameters = d.replaceParameters(item.Parameters)
+		for _, op := range []**spec.Operation{&item.Get, &item.Put, &item.Post, &item.Delete, &item.Options, &item.Head, &item.Patch} {
+			if *op != nil {
+				*op = d.replaceInOperation(*op)
+			}
+		}
+		ret.Paths.Paths[path] = item
+	}
+	if len(ret.Parameters) == 0 {
+		ret.Parameters = sp.Parameters
+	}
+	if len(ret.Responses) == 0 {
+		ret.Responses = sp.Responses
+	}
+	return &ret, nil
+}
+
+type deduplicator struct {
+	// paramCounts and responseCounts count the occurrences of each inline
+	// parameter and response, keyed by content.
+	paramCounts    map[string]int
+	responseCounts map[string]int
+	// paramNames and responseNames hold the top-level name of shared
+	// parameters and responses, keyed by content.
+	paramNames    map[string]string
+	responseNames map[string]string
+	// params and responses are the top-level sections of the output.
+	params    map[string]spec.Parameter
+	responses map[string]spec.Response
+}
+
+func (d *deduplicator) count(sp *spec.Swagger) error {
+	countParams := func(params []spec.Parameter) error {
+		for _, p := range params {
+			if p.Ref.String() != "" {
+				continue
+			}
+			key, err := contentKey(p)
+			if err != nil {
+				return err
+			}
+			d.paramCounts[key]++
+		}
+		return nil
+	}
+	countResponse := func(r *spec.Response) error {
+		if r == nil || r.Ref.String() != "" {
+			return nil
+		}
+		key, err := contentKey(r)
+		if err != nil {
+			return err
+		}
+		d.responseCounts[key]++
+		return nil
+	}
+
+	for _, item := range sp.Paths.Paths {
+		if err := countParams(item.Parameters); err != nil {
+			return err
+		}
+		for _, op := range []*spec.Operation{item.Get, item.Put, item.Post, item.Delete, item.Options, item.Head, item.Patch} {
+			if op == nil {
+				continue
+			}
+			if err := countParams(op.Parameters); err != nil {
+				return err
+			}
+			if op.Responses == nil {
+				continue
+			}
+			if err := countResponse(op.Responses.Default); err != nil {
+				return err
+			}
+			for code := range op.Responses.StatusCodeResponses {
+				r := op.Responses.StatusCodeResponses[code]
+				if err := countResponse(&r); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// replaceInOperation returns op with its shared parameters and responses
+// replaced by references, copying op if anything is replaced.
+func (d *deduplicator) replaceInOperation(op *spec.Operation) *spec.Operation {
+	params := d.replaceParameters(op.Parameters)
+	responses := op.Responses
+	if op.Responses != nil {
+		var changed bool
+		ret := *op.Responses
+		if ret.Default != nil {
+			if r, ok := d.replaceResponse(*ret.Default); ok {
+				ret.Default = &r
+				changed = true
+			}
+		}
+		codes := make(map[int]spec.Response, len(ret.StatusCodeResponses))
+		for code, r := range ret.StatusCodeResponses {
+			if ref, ok := d.replaceResponse(r); ok {
+				r = ref
+				changed = true
+			}
+			codes[code] = r
+		}
+		if changed {
+			ret.StatusCodeResponses = codes
+			responses = &ret
+		}
+	}
+	if responses == op.Responses && sameSlice(params, op.Parameters) {
+		return op
+	}
+	ret := *op
+	ret.Parameters = params
+	ret.Responses = responses
+	return &ret
+}
+
+// replaceParameters returns params with the shared parameters replaced by
+// references. params is returned as is if none is shared.
+func (d *deduplicator) replaceParameters(params []spec.Parameter) []spec.Parameter {
+	var ret []spec.Parameter
+	for i, p := range params {
+		if p.Ref.String() != "" {
+			continue
+		}
+		key, err := contentKey(p)
+		if err != nil || d.paramCounts[key] < 2 {
+			continue
+		}
+		name, ok := d.paramNames[key]
+		if !ok {
+			name = uniqueName(p.Name+"-", key, func(name string) bool {
+				_, exists := d.params[name]
+				return exists
+			})
+			d.params[name] = p
+			d.paramNames[key] = name
+		}
+		if ret == nil {
+			ret = make([]spec.Parameter, len(params))
+			copy(ret, params)
+		}
+		ret[i] = spec.Parameter{Refable: spec.Refable{Ref: spec.MustCreateRef("#/parameters/" + escapeRefName(name))}}
+	}
+	if ret == nil {
+		return params
+	}
+	return ret
+}
+
+// replaceResponse returns a reference to the top-level response replacing r
+// if it is shared.
+func (d *deduplicator) replaceResponse(r spec.Response) (spec.Response, bool) {
+	if r.Ref.String() != "" {
+		return r, false
+	}
+	key, err := contentKey(r)
+	if err != nil || d.responseCounts[key] < 2 {
+		return r, false
+	}
+	name, ok := d.responseNames[key]
+	if !ok {
+		name = uniqueName("response-", key, func(name string) bool {
+			_, exists := d.responses[name]
+			return exists
+		})
+		d.responses[name] = r
+		d.responseNames[key] = name
+	}
+	return spec.Response{Refable: spec.Refable{Ref: spec.MustCreateRef("#/responses/" + escapeRefName(name))}}, true
+}
+
+// contentKey returns a key identifying the content of v.
+func contentKey(v interface{}) (string, error) {
+	b, err := json.Marshal(v)
+	return string(b), err
+}
+
+// uniqueName returns prefix followed by the shortest prefix of the hash of
+// key, of at least 8 characters, that exists does not report as taken.
+func uniqueName(prefix, key string, exists func(string) bool) string {
+	sum := sha256.Sum256([]byte(key))
+	hash := base64.RawURLEncoding.EncodeToString(sum[:])
+	for n := 8; n < len(hash); n++ {
+		if name := prefix + hash[:n]; !exists(name) {
+			return name
+		}
+	}
+	return prefix + hash
+}
+
+// escapeRefName escapes name to be used in a JSON pointer, as per rfc6901.
+func escapeRefName(name string) string {
+	name = strings.Replace(name, "~", "~0", -1)
+	return strings.Replace(name, "/", "~1", -1)
+}
+
+func sameSlice(a, b []spec.Parameter) bool {
+	return len(a) == len(b) && (len(a) == 0 || &a[0] == &b[0])
+}
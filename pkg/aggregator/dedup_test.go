@@ -0,0 +1,114 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aggregator
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+const dedupSpec = `{
+  "swagger": "2.0",
+  "paths": {
+    "/apis/a/v1/foos": {
+      "parameters": [
+        {"name": "pretty", "in": "query", "type": "string", "uniqueItems": true}
+      ],
+      "get": {
+        "parameters": [
+          {"name": "limit", "in": "query", "type": "integer", "uniqueItems": true},
+          {"$ref": "#/parameters/body"}
+        ],
+        "responses": {
+          "200": {"description": "OK", "schema": {"$ref": "#/definitions/Foo"}},
+          "401": {"description": "Unauthorized"}
+        }
+      }
+    },
+    "/apis/b/v1/bars": {
+      "parameters": [
+        {"name": "pretty", "in": "query", "type": "string", "uniqueItems": true}
+      ],
+      "get": {
+        "parameters": [
+          {"name": "limit", "in": "query", "type": "integer", "uniqueItems": true},
+          {"name": "unique", "in": "query", "type": "string"}
+        ],
+        "responses": {
+          "200": {"description": "OK", "schema": {"$ref": "#/definitions/Bar"}},
+          "401": {"description": "Unauthorized"}
+        }
+      }
+    }
+  },
+  "parameters": {
+    "body": {"name": "body", "in": "body", "required": true, "schema": {"type": "object"}},
+    "limit": {"name": "limit", "in": "query", "type": "integer", "uniqueItems": true}
+  }
+}`
+
+func TestDeduplicateParametersAndResponses(t *testing.T) {
+	var sp *spec.Swagger
+	require.NoError(t, json.Unmarshal([]byte(dedupSpec), &sp))
+	before, err := json.Marshal(sp)
+	require.NoError(t, err)
+
+	got, err := DeduplicateParametersAndResponses(sp)
+	require.NoError(t, err)
+
+	after, err := json.Marshal(sp)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(before), string(after), "input should not be modified")
+
+	foos := got.Paths.Paths["/apis/a/v1/foos"]
+	bars := got.Paths.Paths["/apis/b/v1/bars"]
+
+	// the existing identical top-level parameter is reused
+	assert.Equal(t, "#/parameters/limit", foos.Get.Parameters[0].Ref.String())
+	assert.Equal(t, "#/parameters/limit", bars.Get.Parameters[0].Ref.String())
+	assert.Equal(t, "#/parameters/body", foos.Get.Parameters[1].Ref.String())
+	assert.Equal(t, "unique", bars.Get.Parameters[1].Name, "parameters used once should stay inline")
+
+	pretty := foos.Parameters[0].Ref.String()
+	assert.True(t, strings.HasPrefix(pretty, "#/parameters/pretty-"), pretty)
+	assert.Equal(t, pretty, bars.Parameters[0].Ref.String())
+	assert.Equal(t, "pretty", got.Parameters[strings.TrimPrefix(pretty, "#/parameters/")].Name)
+	assert.Len(t, got.Parameters, 3)
+
+	fooUnauthorized := foos.Get.Responses.StatusCodeResponses[401]
+	barUnauthorized := bars.Get.Responses.StatusCodeResponses[401]
+	unauthorized := fooUnauthorized.Ref.String()
+	assert.True(t, strings.HasPrefix(unauthorized, "#/responses/response-"), unauthorized)
+	assert.Equal(t, unauthorized, barUnauthorized.Ref.String())
+	assert.Equal(t, "Unauthorized", got.Responses[strings.TrimPrefix(unauthorized, "#/responses/")].Description)
+	assert.Len(t, got.Responses, 1)
+	assert.Equal(t, "OK", foos.Get.Responses.StatusCodeResponses[200].Description, "responses used once should stay inline")
+
+	again, err := DeduplicateParametersAndResponses(got)
+	require.NoError(t, err)
+	assert.Equal(t, got, again, "deduplication should be idempotent")
+}
+
+func TestEscapeRefName(t *testing.T) {
+	assert.Equal(t, "a~1b~0c", escapeRefName("a/b~c"))
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aggregator
+
+import "fmt"
+
+// PathConflictError is returned when merging a source spec with a path that
+// the destination spec already has.
+type PathConflictError struct {
+	// Source is the name of the source spec, see MergeOptions. It is empty
+	// if the source is not named.
+	Source string
+	// Path is the conflicting path.
+	Path string
+}
+
+func (e *PathConflictError) Error() string {
+	return fmt.Sprintf("unable to merge%s: duplicated path %s", sourceSuffix(e.Source), e.Path)
+}
+
+// DefinitionConflictError is returned when merging a source spec with a
+// definition that the destination spec defines differently, and definitions
+// are not renamed.
+type DefinitionConflictError struct {
+	// Source is the name of the source spec, see MergeOptions. It is empty
+	// if the source is not named.
+	Source string
+	// Definition is the name of the conflicting definition.
+	Definition string
+}
+
+func (e *DefinitionConflictError) Error() string {
+	return fmt.Sprintf("model name conflict in merging OpenAPI spec%s: %s", sourceSuffix(e.Source), e.Definition)
+}
+
+// InvalidSourceError is returned when a source spec cannot be merged because
+// it is malformed, e.g. when the x-kubernetes-group-version-kind extension of
+// a definition is not a list of group-version-kinds.
+type InvalidSourceError struct {
+	// Source is the name of the source spec, see MergeOptions. It is empty
+	// if the source is not named.
+	Source string
+	// Definition is the name of the malformed definition, if any.
+	Definition string
+	// Err is the cause of the error.
+	Err error
+}
+
+func (e *InvalidSourceError) Error() string {
+	if e.Definition == "" {
+		return fmt.Sprintf("invalid OpenAPI spec%s: %v", sourceSuffix(e.Source), e.Err)
+	}
+	return fmt.Sprintf("invalid OpenAPI spec%s: definition %s: %v", sourceSuffix(e.Source), e.Definition, e.Err)
+}
+
+func (e *InvalidSourceError) Unwrap() error {
+	return e.Err
+}
+
+func sourceSuffix(source string) string {
+	if source == "" {
+		return ""
+	}
+	return fmt.Sprintf(" %q", source)
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aggregator
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+func specWith(path string, definitions spec.Definitions) *spec.Swagger {
+	return &spec.Swagger{SwaggerProps: spec.SwaggerProps{
+		Paths:       &spec.Paths{Paths: map[string]spec.PathItem{path: {}}},
+		Definitions: definitions,
+	}}
+}
+
+func TestMergeSpecsWithOptionsErrors(t *testing.T) {
+	integer := spec.Definitions{"Foo": *spec.Int64Property()}
+	str := spec.Definitions{"Foo": *spec.StringProperty()}
+	withGVK := func(gvk interface{}) spec.Definitions {
+		s := *spec.StringProperty()
+		s.AddExtension(gvkKey, gvk)
+		return spec.Definitions{"Foo": s}
+	}
+
+	t.Run("path conflict", func(t *testing.T) {
+		err := MergeSpecsWithOptions(specWith("/foo", nil), specWith("/foo", nil), MergeOptions{Source: "v1.foo.example.com"})
+		var conflict *PathConflictError
+		if assert.True(t, errors.As(err, &conflict), "got %v", err) {
+			assert.Equal(t, &PathConflictError{Source: "v1.foo.example.com", Path: "/foo"}, conflict)
+			assert.EqualError(t, err, `unable to merge "v1.foo.example.com": duplicated path /foo`)
+		}
+	})
+
+	t.Run("definition conflict", func(t *testing.T) {
+		err := MergeSpecsWithOptions(specWith("/foo", integer), specWith("/bar", str), MergeOptions{Source: "v1.bar.example.com"})
+		var conflict *DefinitionConflictError
+		if assert.True(t, errors.As(err, &conflict), "got %v", err) {
+			assert.Equal(t, &DefinitionConflictError{Source: "v1.bar.example.com", Definition: "Foo"}, conflict)
+			assert.EqualError(t, err, `model name conflict in merging OpenAPI spec "v1.bar.example.com": Foo`)
+		}
+	})
+
+	t.Run("renamed definition conflict", func(t *testing.T) {
+		dest := specWith("/foo", integer)
+		assert.NoError(t, MergeSpecsWithOptions(dest, specWith("/bar", str), MergeOptions{RenameDefinitionConflicts: true}))
+		assert.Contains(t, dest.Definitions, "Foo_v2")
+	})
+
+	t.Run("invalid group-version-kinds", func(t *testing.T) {
+		dest := specWith("/foo", withGVK([]interface{}{}))
+		err := MergeSpecsWithOptions(dest, specWith("/bar", withGVK("apps/v1")), MergeOptions{Source: "v1.bar.example.com"})
+		var invalid *InvalidSourceError
+		if assert.True(t, errors.As(err, &invalid), "got %v", err) {
+			assert.Equal(t, "v1.bar.example.com", invalid.Source)
+			assert.Equal(t, "Foo", invalid.Definition)
+			assert.EqualError(t, err, `invalid OpenAPI spec "v1.bar.example.com": definition Foo: expected slice of GroupVersionKinds, got: apps/v1`)
+		}
+	})
+
+	t.Run("nil source", func(t *testing.T) {
+		err := MergeSpecsWithOptions(specWith("/foo", nil), nil, MergeOptions{})
+		var invalid *InvalidSourceError
+		assert.True(t, errors.As(err, &invalid), "got %v", err)
+		assert.EqualError(t, err, "invalid OpenAPI spec: spec is nil")
+	})
+
+	t.Run("unnamed source", func(t *testing.T) {
+		assert.EqualError(t, MergeSpecs(specWith("/foo", nil), specWith("/foo", nil)), "unable to merge: duplicated path /foo")
+		assert.EqualError(t, MergeSpecsFailOnDefinitionConflict(specWith("/foo", integer), specWith("/bar", str)), "model name conflict in merging OpenAPI spec: Foo")
+	})
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aggregator
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/kube-openapi/pkg/util"
+	"k8s.io/kube-openapi/pkg/util/sets"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+const (
+	parameterPrefix = "#/parameters/"
+	responsePrefix  = "#/responses/"
+)
+
+// ReachableDefinitions returns the names of the definitions of sp reachable
+// from the paths starting with one of fromPaths, or from all paths if
+// fromPaths is nil. References are followed transitively through definitions,
+// through the parameters and responses shared at the top level of sp, and
+// through "$ref" members of the JSON values of vendor extensions.
+//
+// An error is returned if a reachable reference to a definition, parameter or
+// response of sp points to nothing.
+func ReachableDefinitions(sp *spec.Swagger, fromPaths []string) (sets.String, error) {
+	root := *sp
+	if sp.Paths != nil && fromPaths != nil {
+		prefixes := util.NewTrie(fromPaths)
+		root.Paths = &spec.Paths{Paths: map[string]spec.PathItem{}}
+		for path, pathItem := range sp.Paths.Paths {
+			if prefixes.HasPrefix(path) {
+				root.Paths.Paths[path] = pathItem
+			}
+		}
+	}
+
+	reachable := sets.NewString()
+	visited := map[string]bool{}
+	unresolved := sets.NewString()
+	walker := &readonlyReferenceWalker{root: &root}
+	walker.walkRefCallback = func(ref *spec.Ref) {
+		refStr := ref.String()
+		if refStr == "" || visited[refStr] {
+			return
+		}
+		visited[refStr] = true
+		switch {
+		case strings.HasPrefix(refStr, definitionPrefix):
+			name := refStr[len(definitionPrefix):]
+			def, found := root.Definitions[name]
+			if !found {
+				unresolved.Insert(refStr)
+				return
+			}
+			reachable.Insert(name)
+			walker.walkSchema(&def)
+		case strings.HasPrefix(refStr, parameterPrefix):
+			param, found := root.Parameters[refStr[len(parameterPrefix):]]
+			if !found {
+				unresolved.Insert(refStr)
+				return
+			}
+			walker.walkParams([]spec.Parameter{param})
+		case strings.HasPrefix(refStr, responsePrefix):
+			resp, found := root.Responses[refStr[len(responsePrefix):]]
+			if !found {
+				unresolved.Insert(refStr)
+				return
+			}
+			walker.walkResponse(&resp)
+		}
+	}
+	walker.walkExtensionsCallback = func(ext spec.Extensions) {
+		keys := make([]string, 0, len(ext))
+		for k := range ext {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			walkExtensionRefs(ext[k], walker.walkRefCallback)
+		}
+	}
+	walker.Start()
+
+	if unresolved.Len() > 0 {
+		return nil, fmt.Errorf("unresolvable references: %s", strings.Join(unresolved.List(), ", "))
+	}
+	return reachable, nil
+}
+
+// walkExtensionRefs calls walkRef on the "$ref" members of the objects found
+// in the value of a vendor extension. Values that are not plain JSON values,
+// such as a spec.Schema, are looked at through their JSON representation.
+func walkExtensionRefs(v interface{}, walkRef func(ref *spec.Ref)) {
+	switch v := v.(type) {
+	case nil, bool, string, float64, int64, int, json.Number:
+	case map[string]interface{}:
+		if s, ok := v["$ref"].(string); ok {
+			if ref, err := spec.NewRef(s); err == nil {
+				walkRef(&ref)
+			}
+		}
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			walkExtensionRefs(v[k], walkRef)
+		}
+	case []interface{}:
+		for _, item := range v {
+			walkExtensionRefs(item, walkRef)
+		}
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return
+		}
+		var generic interface{}
+		if err := json.Unmarshal(data, &generic); err != nil {
+			return
+		}
+		walkExtensionRefs(generic, walkRef)
+	}
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aggregator
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+const reachableSpec = `{
+  "swagger": "2.0",
+  "paths": {
+    "/apis/a/v1/foos": {
+      "get": {
+        "parameters": [{"$ref": "#/parameters/body"}],
+        "responses": {
+          "200": {"description": "OK", "schema": {"$ref": "#/definitions/Foo"}},
+          "404": {"$ref": "#/responses/notFound"}
+        }
+      }
+    },
+    "/apis/b/v1/bars": {
+      "get": {
+        "x-kubernetes-extra": {"nested": [{"$ref": "#/definitions/Extra"}]},
+        "responses": {
+          "200": {"description": "OK", "schema": {"$ref": "#/definitions/Bar"}}
+        }
+      }
+    }
+  },
+  "parameters": {
+    "body": {"name": "body", "in": "body", "schema": {"$ref": "#/definitions/Body"}}
+  },
+  "responses": {
+    "notFound": {"description": "not found", "schema": {"$ref": "#/definitions/Status"}}
+  },
+  "definitions": {
+    "Foo": {"properties": {"child": {"$ref": "#/definitions/Child"}}},
+    "Child": {"type": "object"},
+    "Bar": {"type": "object", "x-kubernetes-embedded": {"$ref": "#/definitions/Embedded"}},
+    "Embedded": {"type": "object"},
+    "Extra": {"type": "object"},
+    "Body": {"type": "object"},
+    "Status": {"type": "object"},
+    "Unused": {"type": "object"}
+  }
+}`
+
+func TestReachableDefinitions(t *testing.T) {
+	var sp *spec.Swagger
+	require.NoError(t, json.Unmarshal([]byte(reachableSpec), &sp))
+
+	var tests = []struct {
+		name      string
+		fromPaths []string
+		expected  []string
+	}{
+		{name: "all paths", expected: []string{"Bar", "Body", "Child", "Embedded", "Extra", "Foo", "Status"}},
+		{name: "prefix", fromPaths: []string{"/apis/a/"}, expected: []string{"Body", "Child", "Foo", "Status"}},
+		{name: "extensions", fromPaths: []string{"/apis/b/v1/bars"}, expected: []string{"Bar", "Embedded", "Extra"}},
+		{name: "no paths", fromPaths: []string{}, expected: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReachableDefinitions(sp, tt.fromPaths)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, got.List())
+		})
+	}
+}
+
+func TestReachableDefinitionsTypedExtension(t *testing.T) {
+	var sp *spec.Swagger
+	require.NoError(t, json.Unmarshal([]byte(reachableSpec), &sp))
+	bar := sp.Definitions["Bar"]
+	bar.Extensions = spec.Extensions{"x-kubernetes-embedded": spec.MustCreateRef("#/definitions/Embedded")}
+	bar.Extensions["x-kubernetes-schema"] = spec.RefSchema("#/definitions/Unused")
+	sp.Definitions["Bar"] = bar
+
+	got, err := ReachableDefinitions(sp, []string{"/apis/b/"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Bar", "Embedded", "Extra", "Unused"}, got.List())
+}
+
+func TestReachableDefinitionsUnresolved(t *testing.T) {
+	var sp *spec.Swagger
+	require.NoError(t, json.Unmarshal([]byte(reachableSpec), &sp))
+	delete(sp.Definitions, "Extra")
+	delete(sp.Responses, "notFound")
+
+	_, err := ReachableDefinitions(sp, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "#/definitions/Extra, #/responses/notFound")
+
+	_, err = ReachableDefinitions(sp, []string{"/apis/b/"})
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "#/responses/notFound")
+}
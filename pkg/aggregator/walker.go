@@ -31,6 +31,10 @@ type readonlyReferenceWalker struct {
 	// walkRefCallback will be called on each reference. The input will never be nil.
 	walkRefCallback func(ref *spec.Ref)
 
+	// walkExtensionsCallback, if set, will be called on the vendor extensions
+	// of each path item, operation, parameter, response and schema.
+	walkExtensionsCallback func(ext spec.Extensions)
+
 	// The spec to walk through.
 	root *spec.Swagger
 }
@@ -66,6 +70,7 @@ func (s *readonlyReferenceWalker) walkSchema(schema *spec.Schema) {
 		return
 	}
 	s.walkRefCallback(&schema.Ref)
+	s.walkExtensions(schema.Extensions)
 	var v *spec.Schema
 	if len(schema.Definitions)+len(schema.Properties)+len(schema.PatternProperties) > 0 {
 		v = &spec.Schema{}
@@ -108,6 +113,22 @@ func (s *readonlyReferenceWalker) walkSchema(schema *spec.Schema) {
 			s.walkSchema(&schema.Items.Schemas[i])
 		}
 	}
+	for i := range schema.PrefixItems {
+		s.walkSchema(&schema.PrefixItems[i])
+	}
+	if schema.UnevaluatedItems != nil && schema.UnevaluatedItems.Schema != nil {
+		s.walkSchema(schema.UnevaluatedItems.Schema)
+	}
+	if schema.UnevaluatedProperties != nil && schema.UnevaluatedProperties.Schema != nil {
+		s.walkSchema(schema.UnevaluatedProperties.Schema)
+	}
+}
+
+func (s *readonlyReferenceWalker) walkExtensions(ext spec.Extensions) {
+	if len(ext) == 0 || s.walkExtensionsCallback == nil {
+		return
+	}
+	s.walkExtensionsCallback(ext)
 }
 
 func (s *readonlyReferenceWalker) walkParams(params []spec.Parameter) {
@@ -116,6 +137,7 @@ func (s *readonlyReferenceWalker) walkParams(params []spec.Parameter) {
 	}
 	for _, param := range params {
 		s.walkRefCallback(&param.Ref)
+		s.walkExtensions(param.Extensions)
 		s.walkSchema(param.Schema)
 		if param.Items != nil {
 			s.walkRefCallback(&param.Items.Ref)
@@ -128,6 +150,7 @@ func (s *readonlyReferenceWalker) walkResponse(resp *spec.Response) {
 		return
 	}
 	s.walkRefCallback(&resp.Ref)
+	s.walkExtensions(resp.Extensions)
 	s.walkSchema(resp.Schema)
 }
 
@@ -135,6 +158,7 @@ func (s *readonlyReferenceWalker) walkOperation(op *spec.Operation) {
 	if op == nil {
 		return
 	}
+	s.walkExtensions(op.Extensions)
 	s.walkParams(op.Parameters)
 	if op.Responses == nil {
 		return
@@ -150,6 +174,7 @@ func (s *readonlyReferenceWalker) Start() {
 		return
 	}
 	for _, pathItem := range s.root.Paths.Paths {
+		s.walkExtensions(pathItem.Extensions)
 		s.walkParams(pathItem.Parameters)
 		s.walkOperation(pathItem.Delete)
 		s.walkOperation(pathItem.Get)
@@ -154,19 +154,7 @@ func (o *openAPI) buildRequestBody(parameters []common.Parameter, consumes []str
 			if err != nil {
 				return nil, err
 			}
-			r := &spec3.RequestBody{
-				RequestBodyProps: spec3.RequestBodyProps{
-					Content: map[string]*spec3.MediaType{},
-				},
-			}
-			for _, consume := range consumes {
-				r.Content[consume] = &spec3.MediaType{
-					MediaTypeProps: spec3.MediaTypeProps{
-						Schema: schema,
-					},
-				}
-			}
-			return r, nil
+			return spec3.NewRequestBody(schema, false, consumes...), nil
 		}
 	}
 	return nil, nil
@@ -231,7 +219,7 @@ func newOpenAPI(config *common.Config) openAPI {
 	} else {
 		o.definitions = o.config.GetDefinitions(func(name string) spec.Ref {
 			defName, _ := o.config.GetDefinitionName(name)
-			return spec.MustCreateRef("#/components/schemas/" + common.EscapeJsonPointer(defName))
+			return spec.MustCreateRef(spec3.SchemaRef(defName))
 		})
 	}
 
@@ -239,6 +227,12 @@ func newOpenAPI(config *common.Config) openAPI {
 }
 
 func (o *openAPI) buildOpenAPISpec(webServices []common.RouteContainer) error {
+	return o.buildPaths(webServices, o.spec.Paths.Paths)
+}
+
+// buildPaths adds the path items of the routes of webServices to paths, keyed
+// by path.
+func (o *openAPI) buildPaths(webServices []common.RouteContainer, paths map[string]*spec3.Path) error {
 	pathsToIgnore := util.NewTrie(o.config.IgnorePrefixes)
 	for _, w := range webServices {
 		rootPath := w.RootPath()
@@ -251,7 +245,11 @@ func (o *openAPI) buildOpenAPISpec(webServices []common.RouteContainer) error {
 			return err
 		}
 
-		for path, routes := range groupRoutesByPath(w.Routes()) {
+		allRoutes, err := withAllNamespacesVariants(w.Routes())
+		if err != nil {
+			return err
+		}
+		for path, routes := range groupRoutesByPath(allRoutes) {
 			// go-swagger has special variable definition {$NAME:*} that can only be
 			// used at the end of the path and it is not recognized by OpenAPI.
 			if strings.HasSuffix(path, ":*}") {
@@ -266,7 +264,7 @@ func (o *openAPI) buildOpenAPISpec(webServices []common.RouteContainer) error {
 			if err != nil {
 				return err
 			}
-			pathItem, exists := o.spec.Paths.Paths[path]
+			pathItem, exists := paths[path]
 			if exists {
 				return fmt.Errorf("duplicate webservice route has been found for path: %v", path)
 			}
@@ -276,7 +274,7 @@ func (o *openAPI) buildOpenAPISpec(webServices []common.RouteContainer) error {
 			}
 
 			// add web services's parameters as well as any parameters appears in all ops, as common parameters
-			pathItem.Parameters = append(pathItem.Parameters, commonParams...)
+			pathItem.Parameters = append(pathItem.Parameters, withoutNamespaceParameters(routes, commonParams)...)
 			for _, p := range inPathCommonParamsMap {
 				pathItem.Parameters = append(pathItem.Parameters, p)
 			}
@@ -304,7 +302,7 @@ func (o *openAPI) buildOpenAPISpec(webServices []common.RouteContainer) error {
 				}
 
 			}
-			o.spec.Paths.Paths[path] = pathItem
+			paths[path] = pathItem
 		}
 	}
 	return nil
@@ -327,6 +325,24 @@ func BuildOpenAPISpecFromRoutes(webServices []common.RouteContainer, config *com
 	return a.spec, nil
 }
 
+// BuildOpenAPISpecWithWebhooks builds OpenAPI v3 spec like BuildOpenAPISpecFromRoutes, and adds the routes of webhooks
+// as the webhooks of the spec, keyed by path. These are the requests that the API sends, e.g. to admission or
+// conversion webhooks, rather than receives. Webhooks are only defined by OpenAPI 3.1: the spec must be marshaled
+// with spec3.Version31 to be valid.
+func BuildOpenAPISpecWithWebhooks(webServices, webhooks []common.RouteContainer, config *common.Config) (*spec3.OpenAPI, error) {
+	a := newOpenAPI(config)
+	if err := a.buildOpenAPISpec(webServices); err != nil {
+		return nil, err
+	}
+	if len(webhooks) > 0 {
+		a.spec.Webhooks = map[string]*spec3.Path{}
+		if err := a.buildPaths(webhooks, a.spec.Webhooks); err != nil {
+			return nil, err
+		}
+	}
+	return a.spec, nil
+}
+
 // BuildOpenAPIDefinitionsForResource builds a partial OpenAPI spec given a sample object and common.Config to customize it.
 // BuildOpenAPIDefinitionsForResources returns the OpenAPI spec which includes the definitions for the
 // passed type names.
@@ -465,7 +481,7 @@ func (o *openAPI) buildDefinitionForType(name string) (string, error) {
 		return "", err
 	}
 	defName, _ := o.config.GetDefinitionName(name)
-	return "#/components/schemas/" + common.EscapeJsonPointer(defName), nil
+	return spec3.SchemaRef(defName), nil
 }
 
 func (o *openAPI) toSchema(name string) (_ *spec.Schema, err error) {
@@ -27,6 +27,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	openapi "k8s.io/kube-openapi/pkg/common"
+	"k8s.io/kube-openapi/pkg/common/restfuladapter"
 	"k8s.io/kube-openapi/pkg/spec3"
 	"k8s.io/kube-openapi/pkg/validation/spec"
 )
@@ -464,3 +465,34 @@ func TestBuildOpenAPISpec(t *testing.T) {
 	}
 	assert.Equal(string(expected_json), string(actual_json))
 }
+
+func TestBuildOpenAPISpecWithWebhooks(t *testing.T) {
+	config, container, assert := setUp(t, true)
+	ws := new(restful.WebService)
+	ws.Path("/hooks")
+	ws.Route(getTestRoute(ws, "post", "hook"))
+
+	swagger, err := BuildOpenAPISpecWithWebhooks(restfuladapter.AdaptWebServices(container.RegisteredWebServices()), restfuladapter.AdaptWebServices([]*restful.WebService{ws}), config)
+	if !assert.NoError(err) {
+		return
+	}
+	assert.Len(swagger.Paths.Paths, 2)
+	assert.NotContains(swagger.Paths.Paths, "/hooks/test/{path}")
+	if assert.Contains(swagger.Webhooks, "/hooks/test/{path}") {
+		hook := swagger.Webhooks["/hooks/test/{path}"]
+		if assert.NotNil(hook.Post) {
+			assert.Equal("posthookTestInput", hook.Post.OperationId)
+			assert.Equal(getTestRequestBody(), hook.Post.RequestBody)
+		}
+		assert.Nil(hook.Get)
+	}
+
+	data, err := swagger.MarshalVersion(spec3.Version30)
+	if assert.NoError(err) {
+		assert.NotContains(string(data), `"webhooks"`)
+	}
+	data, err = swagger.MarshalVersion(spec3.Version31)
+	if assert.NoError(err) {
+		assert.Contains(string(data), `"webhooks":{"/hooks/test/{path}":`)
+	}
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package builder3
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/kube-openapi/pkg/common"
+	"k8s.io/kube-openapi/pkg/spec3"
+)
+
+// AllNamespacesMetadataKey is a route metadata key. When its value is true on a
+// route whose path contains a "/namespaces/{namespace}" segment, the builder
+// also publishes the route under the path with that segment removed, e.g.
+// "/apis/apps/v1/deployments" for "/apis/apps/v1/namespaces/{namespace}/deployments".
+//
+// The derived operation shares request and response schemas with the declared
+// one, has no namespace parameter, and its operation name follows the
+// Kubernetes convention: "Namespaced" is dropped and "ForAllNamespaces" is
+// appended, e.g. listAppsV1NamespacedDeployment becomes
+// listAppsV1DeploymentForAllNamespaces.
+//
+// Building the spec fails if the web service already declares a route under
+// the derived path.
+//
+// Derived routes are not go-restful routes, so they cannot be combined with
+// the deprecated Config.GetOperationIDAndTags callback.
+const AllNamespacesMetadataKey = "k8s.io/all-namespaces-variant"
+
+const (
+	namespaceParameterName = "namespace"
+	namespacePathSegment   = "/namespaces/{" + namespaceParameterName + "}"
+)
+
+// allNamespacesRoute is the cluster-wide variant of a namespaced route.
+type allNamespacesRoute struct {
+	common.Route
+}
+
+var _ common.Route = allNamespacesRoute{}
+
+func (r allNamespacesRoute) Path() string {
+	return strings.Replace(r.Route.Path(), namespacePathSegment, "", 1)
+}
+
+func (r allNamespacesRoute) OperationName() string {
+	name := r.Route.OperationName()
+	if name == "" {
+		return ""
+	}
+	return strings.Replace(name, "Namespaced", "", 1) + "ForAllNamespaces"
+}
+
+func (r allNamespacesRoute) Parameters() []common.Parameter {
+	var params []common.Parameter
+	for _, p := range r.Route.Parameters() {
+		if isNamespaceParameter(p) {
+			continue
+		}
+		params = append(params, p)
+	}
+	return params
+}
+
+func isNamespaceParameter(p common.Parameter) bool {
+	return p.Kind() == common.PathParameterKind && p.Name() == namespaceParameterName
+}
+
+func wantsAllNamespacesVariant(r common.Route) bool {
+	v, ok := r.Metadata()[AllNamespacesMetadataKey].(bool)
+	return ok && v && strings.Contains(r.Path(), namespacePathSegment)
+}
+
+// withAllNamespacesVariants returns routes followed by the all-namespaces
+// variants of the routes asking for one. It fails if the path of a variant
+// is already declared by one of routes, instead of letting the variant
+// overwrite it.
+func withAllNamespacesVariants(routes []common.Route) ([]common.Route, error) {
+	declared := make(map[string]common.Route, len(routes))
+	for _, r := range routes {
+		declared[r.Path()] = r
+	}
+	ret := append([]common.Route(nil), routes...)
+	for _, r := range routes {
+		if !wantsAllNamespacesVariant(r) {
+			continue
+		}
+		variant := allNamespacesRoute{Route: r}
+		if d, found := declared[variant.Path()]; found {
+			return nil, fmt.Errorf("all-namespaces variant of %s %s collides with declared route %s %s", r.Method(), r.Path(), d.Method(), d.Path())
+		}
+		ret = append(ret, variant)
+	}
+	return ret, nil
+}
+
+// withoutNamespaceParameters drops the namespace path parameter from the
+// parameters shared by a web service when building an all-namespaces path.
+func withoutNamespaceParameters(routes []common.Route, params []*spec3.Parameter) []*spec3.Parameter {
+	for _, r := range routes {
+		if _, ok := r.(allNamespacesRoute); !ok {
+			return params
+		}
+	}
+	var ret []*spec3.Parameter
+	for _, p := range params {
+		if p.In == "path" && p.Name == namespaceParameterName {
+			continue
+		}
+		ret = append(ret, p)
+	}
+	return ret
+}
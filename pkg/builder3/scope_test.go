@@ -0,0 +1,88 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package builder3
+
+import (
+	"testing"
+
+	"github.com/emicklei/go-restful/v3"
+)
+
+func TestAllNamespacesVariant(t *testing.T) {
+	config, _, assert := setUp(t, false)
+	ws := new(restful.WebService)
+	ws.Path("/apis/test/v1")
+	ws.Param(ws.PathParameter("namespace", "object name and auth scope").DataType("string"))
+	ws.Route(ws.GET("/namespaces/{namespace}/inputs").
+		Operation("listTestV1NamespacedInput").
+		Produces(restful.MIME_JSON).
+		Param(ws.PathParameter("namespace", "object name and auth scope").DataType("string")).
+		Param(ws.QueryParameter("pretty", "If 'true', then the output is pretty printed.")).
+		Metadata(AllNamespacesMetadataKey, true).
+		Returns(200, "OK", TestOutput{}).
+		To(noOp))
+	ws.Route(ws.GET("/namespaces/{namespace}/inputs/{name}").
+		Operation("readTestV1NamespacedInput").
+		Produces(restful.MIME_JSON).
+		Param(ws.PathParameter("namespace", "object name and auth scope").DataType("string")).
+		Param(ws.PathParameter("name", "name of the input").DataType("string")).
+		Returns(200, "OK", TestOutput{}).
+		To(noOp))
+
+	sp, err := BuildOpenAPISpec([]*restful.WebService{ws}, config)
+	if !assert.NoError(err) {
+		return
+	}
+	assert.Len(sp.Paths.Paths, 3)
+
+	namespaced := sp.Paths.Paths["/apis/test/v1/namespaces/{namespace}/inputs"]
+	if assert.NotNil(namespaced) {
+		assert.Equal("listTestV1NamespacedInput", namespaced.Get.OperationId)
+	}
+
+	cluster := sp.Paths.Paths["/apis/test/v1/inputs"]
+	if !assert.NotNil(cluster) {
+		return
+	}
+	assert.Equal("listTestV1InputForAllNamespaces", cluster.Get.OperationId)
+	for _, p := range append(cluster.Parameters, cluster.Get.Parameters...) {
+		assert.NotEqual("namespace", p.Name)
+	}
+	assert.Equal(namespaced.Get.Responses.StatusCodeResponses[200].Content["application/json"].Schema.Ref.String(),
+		cluster.Get.Responses.StatusCodeResponses[200].Content["application/json"].Schema.Ref.String())
+}
+
+func TestAllNamespacesVariantCollision(t *testing.T) {
+	config, _, assert := setUp(t, false)
+	ws := new(restful.WebService)
+	ws.Path("/apis/test/v1")
+	ws.Route(ws.GET("/namespaces/{namespace}/inputs").
+		Operation("listTestV1NamespacedInput").
+		Produces(restful.MIME_JSON).
+		Param(ws.PathParameter("namespace", "object name and auth scope").DataType("string")).
+		Metadata(AllNamespacesMetadataKey, true).
+		Returns(200, "OK", TestOutput{}).
+		To(noOp))
+	ws.Route(ws.POST("/inputs").
+		Operation("createTestV1Input").
+		Produces(restful.MIME_JSON).
+		Returns(200, "OK", TestOutput{}).
+		To(noOp))
+
+	_, err := BuildOpenAPISpec([]*restful.WebService{ws}, config)
+	assert.EqualError(err, "all-namespaces variant of GET /apis/test/v1/namespaces/{namespace}/inputs collides with declared route POST /apis/test/v1/inputs")
+}
@@ -0,0 +1,331 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package openapi exposes OpenAPI schemas as CEL types, so that CEL
+// expressions can be type-checked against published schemas directly.
+package openapi
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+
+	"k8s.io/kube-openapi/pkg/spec3"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+// TypeProvider is a types.Provider that declares every object schema of
+// a set of named OpenAPI schemas as a CEL object type. Lookups for names it
+// does not know about are delegated to a base provider.
+//
+// Named schemas with properties become object types of the same name.
+// Object schemas declared inline in a property are named after the
+// enclosing type and the property, joined by a dot, e.g. "Pod.metadata".
+// Schemas that only declare additionalProperties become maps from string,
+// arrays become lists, and schemas without a usable type (including
+// x-kubernetes-int-or-string and x-kubernetes-preserve-unknown-fields)
+// become dyn.
+//
+// Properties whose names are not CEL identifiers are declared as fields
+// with escaped names, like the Kubernetes API server does for CEL
+// validation rules, see Escape. Properties that cannot be escaped are not
+// declared.
+type TypeProvider struct {
+	base    types.Provider
+	schemas map[string]*spec.Schema
+
+	// objects holds the field declarations of each declared object type,
+	// keyed by escaped field name.
+	objects map[string]map[string]*types.FieldType
+	// resolving tracks the named schemas whose type is being computed, so
+	// that a non-object schema referencing itself resolves to dyn.
+	resolving map[string]bool
+}
+
+var _ types.Provider = &TypeProvider{}
+
+// NewTypeProvider returns a TypeProvider declaring the given schemas,
+// keyed by name. References of the form "#/definitions/<name>" and
+// "#/components/schemas/<name>" are resolved against the same map.
+// If base is nil, a default CEL type registry is used.
+func NewTypeProvider(schemas map[string]*spec.Schema, base types.Provider) (*TypeProvider, error) {
+	if base == nil {
+		registry, err := types.NewRegistry()
+		if err != nil {
+			return nil, err
+		}
+		base = registry
+	}
+	p := &TypeProvider{
+		base:      base,
+		schemas:   schemas,
+		objects:   map[string]map[string]*types.FieldType{},
+		resolving: map[string]bool{},
+	}
+	for name, s := range schemas {
+		if s == nil || schemaType(s) != "object" || len(s.Properties) == 0 {
+			continue
+		}
+		p.declareObject(name, s)
+	}
+	return p, nil
+}
+
+// NewTypeProviderForSwagger returns a TypeProvider declaring the
+// definitions of an OpenAPI v2 document.
+func NewTypeProviderForSwagger(swagger *spec.Swagger, base types.Provider) (*TypeProvider, error) {
+	schemas := map[string]*spec.Schema{}
+	if swagger != nil {
+		for name := range swagger.Definitions {
+			s := swagger.Definitions[name]
+			schemas[name] = &s
+		}
+	}
+	return NewTypeProvider(schemas, base)
+}
+
+// NewTypeProviderForOpenAPI returns a TypeProvider declaring the component
+// schemas of an OpenAPI v3 document.
+func NewTypeProviderForOpenAPI(doc *spec3.OpenAPI, base types.Provider) (*TypeProvider, error) {
+	var schemas map[string]*spec.Schema
+	if doc != nil && doc.Components != nil {
+		schemas = doc.Components.Schemas
+	}
+	return NewTypeProvider(schemas, base)
+}
+
+// EnumValue implements types.Provider.
+func (p *TypeProvider) EnumValue(enumName string) ref.Val {
+	return p.base.EnumValue(enumName)
+}
+
+// FindIdent implements types.Provider.
+func (p *TypeProvider) FindIdent(identName string) (ref.Val, bool) {
+	return p.base.FindIdent(identName)
+}
+
+// FindStructType implements types.Provider. It returns the type of the
+// declared object type with the given name, if any.
+func (p *TypeProvider) FindStructType(structType string) (*types.Type, bool) {
+	if _, ok := p.objects[structType]; ok {
+		return types.NewTypeTypeWithParam(types.NewObjectType(structType)), true
+	}
+	return p.base.FindStructType(structType)
+}
+
+// FindStructFieldType implements types.Provider. It returns the type of a
+// field of a declared object type. fieldName is the escaped property name.
+func (p *TypeProvider) FindStructFieldType(structType, fieldName string) (*types.FieldType, bool) {
+	if fields, ok := p.objects[structType]; ok {
+		f, ok := fields[fieldName]
+		return f, ok
+	}
+	return p.base.FindStructFieldType(structType, fieldName)
+}
+
+// NewValue implements types.Provider. Declared object types are only
+// meant for type-checking and cannot be constructed.
+func (p *TypeProvider) NewValue(typeName string, fields map[string]ref.Val) ref.Val {
+	if _, ok := p.objects[typeName]; ok {
+		return types.NewErr("cannot construct object of type '%s'", typeName)
+	}
+	return p.base.NewValue(typeName, fields)
+}
+
+// declareObject declares name as an object type with the properties of s.
+// The type is registered before its fields are computed, so recursive
+// references resolve to it.
+func (p *TypeProvider) declareObject(name string, s *spec.Schema) {
+	if _, ok := p.objects[name]; ok {
+		return
+	}
+	fields := map[string]*types.FieldType{}
+	p.objects[name] = fields
+	for prop := range s.Properties {
+		field, ok := Escape(prop)
+		if !ok {
+			continue
+		}
+		propSchema := s.Properties[prop]
+		fields[field] = &types.FieldType{
+			Type:    p.typeOf(name+"."+prop, &propSchema),
+			IsSet:   isFieldSet(prop),
+			GetFrom: getField(prop),
+		}
+	}
+}
+
+// typeOf returns the CEL type of s. name is used to declare s if it is an
+// inline object schema.
+func (p *TypeProvider) typeOf(name string, s *spec.Schema) *types.Type {
+	if r := s.Ref.String(); r != "" {
+		return p.typeOfRef(r)
+	}
+	if len(s.AllOf) == 1 && len(s.Type) == 0 && len(s.Properties) == 0 {
+		return p.typeOf(name, &s.AllOf[0])
+	}
+	switch schemaType(s) {
+	case "object":
+		if len(s.Properties) > 0 {
+			p.declareObject(name, s)
+			return types.NewObjectType(name)
+		}
+		if s.AdditionalProperties != nil && s.AdditionalProperties.Schema != nil {
+			return types.NewMapType(types.StringType, p.typeOf(name+".@elem", s.AdditionalProperties.Schema))
+		}
+		return types.NewMapType(types.StringType, types.DynType)
+	case "array":
+		if s.Items != nil && s.Items.Schema != nil {
+			return types.NewListType(p.typeOf(name+".@idx", s.Items.Schema))
+		}
+		return types.NewListType(types.DynType)
+	case "string":
+		switch s.Format {
+		case "byte":
+			return types.BytesType
+		case "date-time":
+			return types.TimestampType
+		case "duration":
+			return types.DurationType
+		}
+		return types.StringType
+	case "integer":
+		return types.IntType
+	case "number":
+		return types.DoubleType
+	case "boolean":
+		return types.BoolType
+	}
+	return types.DynType
+}
+
+// typeOfRef returns the CEL type of the named schema a reference points
+// to, or dyn if the reference cannot be resolved.
+func (p *TypeProvider) typeOfRef(ref string) *types.Type {
+	name := refName(ref)
+	if _, ok := p.objects[name]; ok {
+		return types.NewObjectType(name)
+	}
+	s, ok := p.schemas[name]
+	if !ok || s == nil || p.resolving[name] {
+		return types.DynType
+	}
+	p.resolving[name] = true
+	defer delete(p.resolving, name)
+	return p.typeOf(name, s)
+}
+
+// refName returns the schema name a local reference points to.
+func refName(ref string) string {
+	for _, prefix := range []string{"#/definitions/", "#/components/schemas/"} {
+		if strings.HasPrefix(ref, prefix) {
+			ref = strings.TrimPrefix(ref, prefix)
+			break
+		}
+	}
+	return strings.NewReplacer("~1", "/", "~0", "~").Replace(ref)
+}
+
+// schemaType returns the single type values of s have, or "" if they
+// cannot be given one.
+func schemaType(s *spec.Schema) string {
+	for _, ext := range []string{"x-kubernetes-int-or-string", "x-kubernetes-preserve-unknown-fields"} {
+		if v, ok := s.Extensions.GetBool(ext); ok && v {
+			return ""
+		}
+	}
+	switch {
+	case len(s.Type) == 1:
+		return s.Type[0]
+	case len(s.Type) == 0 && (len(s.Properties) > 0 || s.AdditionalProperties != nil && s.AdditionalProperties.Schema != nil):
+		return "object"
+	}
+	return ""
+}
+
+func isFieldSet(field string) ref.FieldTester {
+	return func(target interface{}) bool {
+		m, ok := target.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		_, ok = m[field]
+		return ok
+	}
+}
+
+func getField(field string) ref.FieldGetter {
+	return func(target interface{}) (interface{}, error) {
+		m, ok := target.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expected map[string]interface{}, got %T", target)
+		}
+		v, ok := m[field]
+		if !ok {
+			return nil, fmt.Errorf("no such field '%s'", field)
+		}
+		return v, nil
+	}
+}
+
+// celReservedSymbols are the CEL keywords and reserved words, which cannot
+// be used as field names as is.
+var celReservedSymbols = map[string]bool{
+	"true": true, "false": true, "null": true, "in": true,
+	"as": true, "break": true, "const": true, "continue": true, "else": true,
+	"for": true, "function": true, "if": true, "import": true, "let": true,
+	"loop": true, "package": true, "namespace": true, "return": true,
+	"var": true, "void": true, "while": true,
+}
+
+var escapeMatcher = regexp.MustCompile(`(__|[-./]|[^a-zA-Z0-9_])`)
+
+// Escape returns the CEL field name of a property, with the escaping the
+// Kubernetes API server uses for CEL validation rules: reserved words such
+// as "namespace" become "__namespace__", and "__", ".", "-" and "/" become
+// "__underscores__", "__dot__", "__dash__" and "__slash__". It returns false
+// if the name cannot be escaped, i.e. it is empty, starts with a digit or
+// contains other characters than letters, digits and the ones above.
+func Escape(name string) (string, bool) {
+	if len(name) == 0 || ('0' <= name[0] && name[0] <= '9') {
+		return "", false
+	}
+	if celReservedSymbols[name] {
+		return "__" + name + "__", true
+	}
+	ok := true
+	escaped := escapeMatcher.ReplaceAllStringFunc(name, func(s string) string {
+		switch s {
+		case "__":
+			return "__underscores__"
+		case ".":
+			return "__dot__"
+		case "-":
+			return "__dash__"
+		case "/":
+			return "__slash__"
+		}
+		ok = false
+		return ""
+	})
+	if !ok {
+		return "", false
+	}
+	return escaped, true
+}
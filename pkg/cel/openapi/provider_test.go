@@ -0,0 +1,218 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package openapi
+
+import (
+	"testing"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/kube-openapi/pkg/spec3"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+func testSchemas() map[string]*spec.Schema {
+	return map[string]*spec.Schema{
+		"io.k8s.Widget": {
+			SchemaProps: spec.SchemaProps{
+				Type: []string{"object"},
+				Properties: map[string]spec.Schema{
+					"name":     *spec.StringProperty(),
+					"replicas": *spec.Int64Property(),
+					"ratio":    *spec.Float64Property(),
+					"enabled":  *spec.BoolProperty(),
+					"data":     {SchemaProps: spec.SchemaProps{Type: []string{"string"}, Format: "byte"}},
+					"created":  *spec.DateTimeProperty(),
+					"tags":     *spec.ArrayProperty(spec.StringProperty()),
+					"labels":   *spec.MapProperty(spec.StringProperty()),
+					"port": {
+						SchemaProps:      spec.SchemaProps{},
+						VendorExtensible: spec.VendorExtensible{Extensions: spec.Extensions{"x-kubernetes-int-or-string": true}},
+					},
+					"spec": {
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"object"},
+							Properties: map[string]spec.Schema{
+								"size": *spec.Int64Property(),
+							},
+						},
+					},
+					"parts":             *spec.ArrayProperty(spec.RefSchema("#/definitions/io.k8s.Part")),
+					"owner":             {SchemaProps: spec.SchemaProps{AllOf: []spec.Schema{*spec.RefSchema("#/components/schemas/io.k8s.Part")}}},
+					"alias":             *spec.RefSchema("#/definitions/io.k8s.Alias"),
+					"foo-bar":           *spec.StringProperty(),
+					"namespace":         *spec.StringProperty(),
+					"a.b/c__d":          *spec.StringProperty(),
+					"1st":               *spec.StringProperty(),
+					"not an identifier": *spec.StringProperty(),
+				},
+			},
+		},
+		"io.k8s.Part": {
+			SchemaProps: spec.SchemaProps{
+				Type: []string{"object"},
+				Properties: map[string]spec.Schema{
+					"id":     *spec.StringProperty(),
+					"parent": *spec.RefSchema("#/definitions/io.k8s.Part"),
+				},
+			},
+		},
+		"io.k8s.Alias": spec.StringProperty(),
+		"io.k8s.Loop":  spec.RefSchema("#/definitions/io.k8s.Loop"),
+	}
+}
+
+func TestTypeProvider(t *testing.T) {
+	p, err := NewTypeProvider(testSchemas(), nil)
+	require.NoError(t, err)
+
+	env, err := cel.NewEnv(
+		cel.CustomTypeProvider(p),
+		cel.Variable("object", cel.ObjectType("io.k8s.Widget")),
+	)
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		expr string
+		want *cel.Type
+	}{
+		{"object.name", cel.StringType},
+		{"object.replicas", cel.IntType},
+		{"object.ratio", cel.DoubleType},
+		{"object.enabled", cel.BoolType},
+		{"object.data", cel.BytesType},
+		{"object.created", cel.TimestampType},
+		{"object.tags", cel.ListType(cel.StringType)},
+		{"object.labels", cel.MapType(cel.StringType, cel.StringType)},
+		{"object.port", cel.DynType},
+		{"object.spec", cel.ObjectType("io.k8s.Widget.spec")},
+		{"object.spec.size", cel.IntType},
+		{"object.parts[0].parent.parent.id", cel.StringType},
+		{"object.owner", cel.ObjectType("io.k8s.Part")},
+		{"object.alias", cel.StringType},
+		{"object.tags.exists(t, t == object.name) && object.replicas > 1", cel.BoolType},
+		{"object.foo__dash__bar", cel.StringType},
+		{"object.__namespace__", cel.StringType},
+		{"object.a__dot__b__slash__c__underscores__d", cel.StringType},
+		{"has(object.__namespace__) && object.foo__dash__bar == object.__namespace__", cel.BoolType},
+	} {
+		t.Run(tc.expr, func(t *testing.T) {
+			ast, iss := env.Compile(tc.expr)
+			require.NoError(t, iss.Err())
+			assert.Equal(t, tc.want, ast.OutputType())
+		})
+	}
+
+	for _, expr := range []string{
+		"object.missing",
+		"object.replicas == 'one'",
+		"object.spec.name",
+		"object.parts[0].id + 1",
+		"object.namespace",
+		"object.foo-bar == ''",
+	} {
+		t.Run(expr, func(t *testing.T) {
+			_, iss := env.Compile(expr)
+			assert.Error(t, iss.Err())
+		})
+	}
+}
+
+func TestTypeProviderLookups(t *testing.T) {
+	p, err := NewTypeProvider(testSchemas(), nil)
+	require.NoError(t, err)
+
+	_, ok := p.FindStructType("io.k8s.Part")
+	assert.True(t, ok)
+	_, ok = p.FindStructType("io.k8s.Alias")
+	assert.False(t, ok)
+	_, ok = p.FindStructType("google.protobuf.Duration")
+	assert.True(t, ok, "lookups of unknown types should fall through to the base provider")
+
+	f, ok := p.FindStructFieldType("io.k8s.Part", "id")
+	require.True(t, ok)
+	assert.True(t, f.IsSet(map[string]interface{}{"id": "a"}))
+	assert.False(t, f.IsSet(map[string]interface{}{}))
+	v, err := f.GetFrom(map[string]interface{}{"id": "a"})
+	require.NoError(t, err)
+	assert.Equal(t, "a", v)
+
+	assert.True(t, types.IsError(p.NewValue("io.k8s.Part", nil)))
+
+	f, ok = p.FindStructFieldType("io.k8s.Widget", "foo__dash__bar")
+	require.True(t, ok)
+	v, err = f.GetFrom(map[string]interface{}{"foo-bar": "b"})
+	require.NoError(t, err)
+	assert.Equal(t, "b", v, "escaped fields should be read from the unescaped property")
+	_, ok = p.FindStructFieldType("io.k8s.Widget", "foo-bar")
+	assert.False(t, ok)
+	_, ok = p.FindStructFieldType("io.k8s.Widget", "namespace")
+	assert.False(t, ok)
+	for _, name := range []string{"1st", "not an identifier"} {
+		_, ok = p.FindStructFieldType("io.k8s.Widget", name)
+		assert.False(t, ok, "properties that cannot be escaped should not be declared")
+	}
+}
+
+func TestEscape(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		escaped string
+		ok      bool
+	}{
+		{"foo", "foo", true},
+		{"foo-bar", "foo__dash__bar", true},
+		{"namespace", "__namespace__", true},
+		{"in", "__in__", true},
+		{"x.y/z", "x__dot__y__slash__z", true},
+		{"a__b", "a__underscores__b", true},
+		{"_foo", "_foo", true},
+		{"", "", false},
+		{"1st", "", false},
+		{"a b", "", false},
+		{"a:b", "", false},
+	} {
+		escaped, ok := Escape(tc.name)
+		assert.Equal(t, tc.ok, ok, tc.name)
+		assert.Equal(t, tc.escaped, escaped, tc.name)
+	}
+}
+
+func TestTypeProviderForDocuments(t *testing.T) {
+	swagger := &spec.Swagger{SwaggerProps: spec.SwaggerProps{Definitions: spec.Definitions{}}}
+	for name, s := range testSchemas() {
+		swagger.Definitions[name] = *s
+	}
+	p, err := NewTypeProviderForSwagger(swagger, nil)
+	require.NoError(t, err)
+	_, ok := p.FindStructFieldType("io.k8s.Widget", "parts")
+	assert.True(t, ok)
+
+	doc := &spec3.OpenAPI{Components: &spec3.Components{Schemas: testSchemas()}}
+	p, err = NewTypeProviderForOpenAPI(doc, nil)
+	require.NoError(t, err)
+	_, ok = p.FindStructFieldType("io.k8s.Widget.spec", "size")
+	assert.True(t, ok)
+
+	p, err = NewTypeProviderForOpenAPI(&spec3.OpenAPI{}, nil)
+	require.NoError(t, err)
+	_, ok = p.FindStructType("io.k8s.Widget")
+	assert.False(t, ok)
+}
@@ -0,0 +1,247 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package conformance checks the OpenAPI documents published by a live
+// server, for use in end-to-end conformance suites.
+package conformance
+
+import (
+	"context"
+	"crypto/sha512"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	openapi_v2 "github.com/google/gnostic/openapiv2"
+	openapi_v3 "github.com/google/gnostic/openapiv3"
+
+	"k8s.io/kube-openapi/pkg/handler3"
+	"k8s.io/kube-openapi/pkg/spec3"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+const (
+	mimeJSON    = "application/json"
+	mimeProtoV2 = "application/com.github.proto-openapi.spec.v2@v1.0+protobuf"
+	mimeProtoV3 = "application/com.github.proto-openapi.spec.v3@v1.0+protobuf"
+)
+
+// Problem is a conformance violation found in a published document.
+type Problem struct {
+	// URL is the URL of the document, relative to the server.
+	URL     string
+	Message string
+}
+
+func (p Problem) Error() string {
+	return p.URL + ": " + p.Message
+}
+
+// Checker checks the OpenAPI v2 and v3 documents published by a server:
+//
+//   - every document is fetched, in JSON and protobuf, and both forms hold
+//     the same document,
+//   - documents conform to the OpenAPI meta-schema of their version, as
+//     checked by the gnostic parsers, and v3 documents pass spec3.Validate,
+//   - ETags and the hashes listed by the v3 discovery document, in its body
+//     and handler3.HashesHeader, match the content of the documents.
+type Checker struct {
+	// Client fetches the documents. http.DefaultClient is used if nil.
+	Client *http.Client
+	// Server is the base URL of the server, e.g. "https://127.0.0.1:6443".
+	Server string
+	// V2Path and V3Path are the paths serving the documents, by default
+	// /openapi/v2 and /openapi/v3. V2Path is skipped if set to "-".
+	V2Path string
+	V3Path string
+}
+
+// Check checks the documents published by the server and returns the
+// problems found, sorted by URL.
+func (c *Checker) Check(ctx context.Context) []Problem {
+	r := &run{Checker: c, ctx: ctx}
+	if c.V2Path != "-" {
+		r.checkV2(defaultString(c.V2Path, "/openapi/v2"))
+	}
+	r.checkV3(defaultString(c.V3Path, "/openapi/v3"))
+	sort.SliceStable(r.problems, func(i, j int) bool { return r.problems[i].URL < r.problems[j].URL })
+	return r.problems
+}
+
+type run struct {
+	*Checker
+	ctx      context.Context
+	problems []Problem
+}
+
+func (r *run) reportf(u, format string, args ...interface{}) {
+	r.problems = append(r.problems, Problem{URL: u, Message: fmt.Sprintf(format, args...)})
+}
+
+func (r *run) checkV2(path string) {
+	data, header, ok := r.fetch(path, mimeJSON)
+	if !ok {
+		return
+	}
+	r.checkETag(path, data, header.Get("Etag"))
+	if _, err := openapi_v2.ParseDocument(data); err != nil {
+		r.reportf(path, "does not conform to the OpenAPI v2 schema: %v", err)
+	}
+	if err := json.Unmarshal(data, &spec.Swagger{}); err != nil {
+		r.reportf(path, "cannot be decoded: %v", err)
+	}
+	r.checkProto(path, data, mimeProtoV2, func(b []byte) (proto.Message, error) {
+		return openapi_v2.ParseDocument(b)
+	}, &openapi_v2.Document{})
+}
+
+func (r *run) checkV3(path string) {
+	data, header, ok := r.fetch(path, mimeJSON)
+	if !ok {
+		return
+	}
+	discovery := &handler3.OpenAPIV3Discovery{}
+	if err := json.Unmarshal(data, discovery); err != nil {
+		r.reportf(path, "invalid discovery document: %v", err)
+		return
+	}
+
+	gvs := make([]string, 0, len(discovery.Paths))
+	for gv := range discovery.Paths {
+		gvs = append(gvs, gv)
+	}
+	sort.Strings(gvs)
+	hashes := make([]string, 0, len(gvs))
+	for _, gv := range gvs {
+		u := discovery.Paths[gv].ServerRelativeURL
+		parsed, err := url.Parse(u)
+		if err != nil {
+			r.reportf(path, "invalid URL %q for %s: %v", u, gv, err)
+			continue
+		}
+		hashes = append(hashes, gv+"="+parsed.Query().Get("hash"))
+		if want := strings.TrimSuffix(path, "/") + "/" + gv; parsed.Path != want {
+			r.reportf(path, "URL %q of %s does not point to %s", u, gv, want)
+		}
+		doc, docHeader, ok := r.fetch(u, mimeJSON)
+		if !ok {
+			continue
+		}
+		if hash := parsed.Query().Get("hash"); hash != computeETag(doc) {
+			r.reportf(u, "hash %q in discovery does not match the content", hash)
+		}
+		r.checkETag(u, doc, docHeader.Get("Etag"))
+		if _, err := openapi_v3.ParseDocument(doc); err != nil {
+			r.reportf(u, "does not conform to the OpenAPI v3 schema: %v", err)
+		}
+		o := &spec3.OpenAPI{}
+		if err := json.Unmarshal(doc, o); err != nil {
+			r.reportf(u, "cannot be decoded: %v", err)
+		} else if err := spec3.Validate(o); err != nil {
+			r.reportf(u, "invalid: %v", err)
+		}
+		r.checkProto(u, doc, mimeProtoV3, func(b []byte) (proto.Message, error) {
+			return openapi_v3.ParseDocument(b)
+		}, &openapi_v3.Document{})
+	}
+	if v, ok := header[http.CanonicalHeaderKey(handler3.HashesHeader)]; ok && strings.Join(v, ",") != strings.Join(hashes, ",") {
+		r.reportf(path, "%s header %q does not match the discovered hashes", handler3.HashesHeader, strings.Join(v, ","))
+	}
+}
+
+// checkETag checks that the ETag served with data is its hash, if set.
+func (r *run) checkETag(u string, data []byte, etag string) {
+	if etag == "" {
+		return
+	}
+	if unquoted, err := strconv.Unquote(etag); err == nil {
+		etag = unquoted
+	}
+	if etag != computeETag(data) {
+		r.reportf(u, "ETag %q does not match the content", etag)
+	}
+}
+
+// checkProto checks that the protobuf form of the document served at u
+// holds the same document as its JSON form.
+func (r *run) checkProto(u string, jsonData []byte, mime string, parse func([]byte) (proto.Message, error), pb proto.Message) {
+	want, err := parse(jsonData)
+	if err != nil {
+		// already reported
+		return
+	}
+	data, _, ok := r.fetch(u, mime)
+	if !ok {
+		return
+	}
+	if err := proto.Unmarshal(data, pb); err != nil {
+		r.reportf(u, "invalid protobuf document: %v", err)
+		return
+	}
+	if !proto.Equal(want, pb) {
+		r.reportf(u, "protobuf and JSON documents differ")
+	}
+}
+
+// fetch gets u with the given Accept header, and returns the body and
+// headers of the response.
+func (r *run) fetch(u, accept string) ([]byte, http.Header, bool) {
+	req, err := http.NewRequestWithContext(r.ctx, http.MethodGet, strings.TrimSuffix(r.Server, "/")+u, nil)
+	if err != nil {
+		r.reportf(u, "%v", err)
+		return nil, nil, false
+	}
+	req.Header.Set("Accept", accept)
+	client := r.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		r.reportf(u, "%v", err)
+		return nil, nil, false
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		r.reportf(u, "reading %s response: %v", accept, err)
+		return nil, nil, false
+	}
+	if resp.StatusCode != http.StatusOK {
+		r.reportf(u, "%s response status %d", accept, resp.StatusCode)
+		return nil, nil, false
+	}
+	return data, resp.Header, true
+}
+
+// computeETag computes the ETag of data the way the handlers of this
+// repository do.
+func computeETag(data []byte) string {
+	return fmt.Sprintf("%X", sha512.Sum512(data))
+}
+
+func defaultString(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
@@ -0,0 +1,171 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conformance
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"k8s.io/kube-openapi/pkg/handler"
+	"k8s.io/kube-openapi/pkg/handler3"
+	"k8s.io/kube-openapi/pkg/spec3"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+type prefixMux struct {
+	*http.ServeMux
+}
+
+func (m prefixMux) HandlePrefix(path string, h http.Handler) {
+	m.Handle(path, h)
+}
+
+func newTestServer(t *testing.T, wrap func(http.Handler) http.Handler) *httptest.Server {
+	mux := prefixMux{http.NewServeMux()}
+
+	v2, err := handler.NewOpenAPIService(&spec.Swagger{SwaggerProps: spec.SwaggerProps{
+		Swagger: "2.0",
+		Info:    &spec.Info{InfoProps: spec.InfoProps{Title: "test", Version: "v1"}},
+		Paths:   &spec.Paths{Paths: map[string]spec.PathItem{}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := v2.RegisterOpenAPIVersionedService("/openapi/v2", mux); err != nil {
+		t.Fatal(err)
+	}
+
+	v3, err := handler3.NewOpenAPIService(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, gv := range []string{"apis/apps/v1", "api/v1"} {
+		v3.UpdateGroupVersion(gv, &spec3.OpenAPI{
+			Version: "3.0.0",
+			Info:    &spec.Info{InfoProps: spec.InfoProps{Title: gv, Version: "v1"}},
+			Paths:   &spec3.Paths{Paths: map[string]*spec3.Path{}},
+		})
+	}
+	if err := v3.RegisterOpenAPIV3VersionedService("/openapi/v3", mux); err != nil {
+		t.Fatal(err)
+	}
+
+	var h http.Handler = mux
+	if wrap != nil {
+		h = wrap(h)
+	}
+	server := httptest.NewServer(h)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// bodyRewriter replaces the body of the responses served at path.
+func bodyRewriter(path, accept string, body string) func(http.Handler) http.Handler {
+	return responseRewriter(path, accept, func(http.Header, []byte) []byte { return []byte(body) })
+}
+
+// responseRewriter lets rewrite modify the responses served at path.
+func responseRewriter(path, accept string, rewrite func(http.Header, []byte) []byte) func(http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != path || r.Header.Get("Accept") != accept {
+				h.ServeHTTP(w, r)
+				return
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, r)
+			for k, v := range rec.Header() {
+				w.Header()[k] = v
+			}
+			body := rewrite(w.Header(), rec.Body.Bytes())
+			w.Header().Del("Content-Length")
+			w.WriteHeader(rec.Code)
+			w.Write(body)
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	var tests = []struct {
+		name     string
+		wrap     func(http.Handler) http.Handler
+		expected []string
+	}{
+		{name: "conformant"},
+		{
+			name: "missing v2",
+			wrap: func(h http.Handler) http.Handler {
+				return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					if r.URL.Path == "/openapi/v2" {
+						http.NotFound(w, r)
+						return
+					}
+					h.ServeHTTP(w, r)
+				})
+			},
+			expected: []string{"/openapi/v2: application/json response status 404"},
+		},
+		{
+			name: "tampered v3 document",
+			wrap: bodyRewriter("/openapi/v3/apis/apps/v1", mimeJSON, `{"openapi": "3.0.0", "info": {"title": "x", "version": "v1"}, "paths": {}}`),
+			expected: []string{
+				"does not match the content",
+				"does not match the content",
+				"protobuf and JSON documents differ",
+			},
+		},
+		{
+			name: "invalid v2 document",
+			wrap: bodyRewriter("/openapi/v2", mimeJSON, `{"swagger": "2.0", "paths": {}}`),
+			expected: []string{
+				"ETag",
+				"does not conform to the OpenAPI v2 schema",
+			},
+		},
+		{
+			name: "stale hashes header",
+			wrap: responseRewriter("/openapi/v3", mimeJSON, func(header http.Header, body []byte) []byte {
+				header.Set(handler3.HashesHeader, "api/v1=0,"+strings.SplitN(header.Get(handler3.HashesHeader), ",", 2)[1])
+				return body
+			}),
+			expected: []string{`/openapi/v3: X-OpenAPI-V3-Hashes header "api/v1=0,apis/apps/v1=`},
+		},
+		{
+			name:     "invalid discovery",
+			wrap:     bodyRewriter("/openapi/v3", mimeJSON, `[]`),
+			expected: []string{"/openapi/v3: invalid discovery document"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newTestServer(t, tt.wrap)
+			c := &Checker{Client: server.Client(), Server: server.URL}
+			problems := c.Check(context.Background())
+			if len(problems) != len(tt.expected) {
+				t.Fatalf("expected %d problems, got %v", len(tt.expected), problems)
+			}
+			for i, p := range problems {
+				if !strings.Contains(p.Error(), tt.expected[i]) {
+					t.Errorf("expected problem %d to contain %q, got %q", i, tt.expected[i], p.Error())
+				}
+			}
+		})
+	}
+}
@@ -64,8 +64,16 @@ func Packages(context *generator.Context, arguments *args.GeneratorArgs) generat
 `)...)
 
 	reportPath := "-"
+	maxErrors := 1
+	emitHashes := false
+	emitV3 := false
+	closedStructs := false
 	if customArgs, ok := arguments.CustomArgs.(*generatorargs.CustomArgs); ok {
 		reportPath = customArgs.ReportFilename
+		maxErrors = customArgs.MaxErrors
+		emitHashes = customArgs.EmitDefinitionHashes
+		emitV3 = customArgs.EmitV3Definitions
+		closedStructs = customArgs.ClosedStructs
 	}
 	context.FileTypes[apiViolationFileType] = apiViolationFile{
 		unmangledPath: reportPath,
@@ -81,6 +89,10 @@ func Packages(context *generator.Context, arguments *args.GeneratorArgs) generat
 					newOpenAPIGen(
 						arguments.OutputFileBaseName,
 						arguments.OutputPackagePath,
+						maxErrors,
+						emitHashes,
+						emitV3,
+						closedStructs,
 					),
 					newAPIViolationGen(),
 				}
@@ -0,0 +1,238 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generators
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+
+	"k8s.io/gengo/types"
+	openapi "k8s.io/kube-openapi/pkg/common"
+	"sigs.k8s.io/yaml"
+)
+
+// tagExampleFile references a JSON or YAML file, relative to the directory of
+// the package, holding an example of the type, e.g.
+// +exampleFile=testdata/deployment.yaml
+const tagExampleFile = "exampleFile"
+
+// exampleFromComments reads the example referenced by the exampleFile tag of
+// the comments, relative to dir. It returns nil if there is no such tag.
+func exampleFromComments(comments []string, dir string) (interface{}, error) {
+	file, err := getSingleTagsValue(comments, tagExampleFile)
+	if file == "" {
+		return nil, err
+	}
+	if !filepath.IsAbs(file) {
+		if dir == "" {
+			return nil, fmt.Errorf("cannot resolve example file %q: unknown package directory", file)
+		}
+		file = filepath.Join(dir, file)
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read example: %v", err)
+	}
+	var example interface{}
+	if err := yaml.Unmarshal(data, &example); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal example %s: %v", file, err)
+	}
+	return example, nil
+}
+
+// generateExample emits the example referenced by the exampleFile tag of t,
+// after checking that it conforms to the schema generated for t.
+func (g openAPITypeWriter) generateExample(t *types.Type) error {
+	var dir string
+	if pkg := g.context.Universe.Package(t.Name.Package); pkg != nil {
+		dir = pkg.SourcePath
+	}
+	example, err := exampleFromComments(t.CommentLines, dir)
+	if err != nil || example == nil {
+		return err
+	}
+	if err := validateExample(t, example, ""); err != nil {
+		return fmt.Errorf("invalid example: %v", err)
+	}
+	g.Do("SwaggerSchemaProps: spec.SwaggerSchemaProps{\n", nil)
+	g.Do("Example: $.$,\n", fmt.Sprintf("%#v", example))
+	g.Do("},\n", nil)
+	return nil
+}
+
+// validateExample checks that the value v, decoded from JSON, conforms to the
+// schema generated for t: that it has the expected JSON types, all required
+// fields and no unknown field. Types whose schema is not generated from their
+// Go definition accept any value.
+func validateExample(t *types.Type, v interface{}, path string) error {
+	t = resolveAliasAndPtrType(t)
+	if v == nil {
+		return nil
+	}
+	if typeString, _ := openapi.OpenAPITypeFormat(t.String()); typeString != "" {
+		return validateExampleType(typeString, v, path)
+	}
+	switch t.Kind {
+	case types.Struct:
+		if hasOpenAPIDefinitionMethod(t) || hasOpenAPIDefinitionMethods(t) || hasOpenAPIV3DefinitionMethod(t) || getExternalRef(t) != "" {
+			return nil
+		}
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return exampleTypeError(path, "object", v)
+		}
+		members := map[string]*types.Member{}
+		required := exampleMembers(t, members, nil)
+		for _, name := range required {
+			if _, ok := obj[name]; !ok {
+				return fmt.Errorf("%s: required field is missing", examplePath(path, name))
+			}
+		}
+		keys := make([]string, 0, len(obj))
+		for k := range obj {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			m, ok := members[k]
+			if !ok {
+				return fmt.Errorf("%s: unknown field", examplePath(path, k))
+			}
+			if jsonTags := getJsonTags(m); len(jsonTags) > 1 && jsonTags[1] == "string" {
+				if err := validateExampleType("string", obj[k], examplePath(path, k)); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := validateExample(m.Type, obj[k], examplePath(path, k)); err != nil {
+				return err
+			}
+		}
+	case types.Map:
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return exampleTypeError(path, "object", v)
+		}
+		for k, elem := range obj {
+			if err := validateExample(t.Elem, elem, fmt.Sprintf("%s[%q]", path, k)); err != nil {
+				return err
+			}
+		}
+	case types.Slice, types.Array:
+		items, ok := v.([]interface{})
+		if !ok {
+			return exampleTypeError(path, "array", v)
+		}
+		for i, item := range items {
+			if err := validateExample(t.Elem, item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// exampleMembers adds the members of t, keyed by JSON name, to members, in the
+// same way as generateMembers, and returns required with the names of the
+// required ones appended.
+func exampleMembers(t *types.Type, members map[string]*types.Member, required []string) []string {
+	return exampleMembersOf(t, members, required, map[*types.Type]bool{})
+}
+
+// exampleMembersOf is exampleMembers, skipping the structs already seen since
+// structs may inline each other.
+func exampleMembersOf(t *types.Type, members map[string]*types.Member, required []string, seen map[*types.Type]bool) []string {
+	for t.Kind == types.Pointer {
+		t = t.Elem
+	}
+	if seen[t] {
+		return required
+	}
+	seen[t] = true
+	for i := range t.Members {
+		m := &t.Members[i]
+		if hasOpenAPITagValue(m.CommentLines, tagValueFalse) {
+			continue
+		}
+		if shouldInlineMembers(m) {
+			required = exampleMembersOf(m.Type, members, required, seen)
+			continue
+		}
+		name := getReferableName(m)
+		if name == "" {
+			continue
+		}
+		if !hasOptionalTag(m) {
+			required = append(required, name)
+		}
+		members[name] = m
+	}
+	return required
+}
+
+func validateExampleType(typeString string, v interface{}, path string) error {
+	var ok bool
+	switch typeString {
+	case "string":
+		_, ok = v.(string)
+	case "boolean":
+		_, ok = v.(bool)
+	case "number":
+		_, ok = v.(float64)
+	case "integer":
+		var f float64
+		f, ok = v.(float64)
+		ok = ok && f == math.Trunc(f)
+	default:
+		ok = true
+	}
+	if !ok {
+		return exampleTypeError(path, typeString, v)
+	}
+	return nil
+}
+
+func exampleTypeError(path, expected string, v interface{}) error {
+	got := reflect.TypeOf(v).String()
+	switch v.(type) {
+	case string:
+		got = "string"
+	case bool:
+		got = "boolean"
+	case float64:
+		got = "number"
+	case map[string]interface{}:
+		got = "object"
+	case []interface{}:
+		got = "array"
+	}
+	if path == "" {
+		path = "<root>"
+	}
+	return fmt.Errorf("%s: expected %s, got %s", path, expected, got)
+}
+
+func examplePath(path, field string) string {
+	if path == "" {
+		return field
+	}
+	return path + "." + field
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generators
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/gengo/generator"
+	"k8s.io/gengo/namer"
+	"k8s.io/gengo/types"
+)
+
+const exampleTestSource = `package foo
+
+// Blah has an example.
+// +k8s:openapi-gen=true
+// +exampleFile=testdata/blah.yaml
+type Blah struct {
+	Name string ` + "`json:\"name\"`" + `
+	// +optional
+	Replicas *int32 ` + "`json:\"replicas,omitempty\"`" + `
+	Labels map[string]string ` + "`json:\"labels,omitempty\"`" + `
+	Items []Item ` + "`json:\"items,omitempty\"`" + `
+	Inner ` + "`json:\",inline\"`" + `
+}
+
+type Item struct {
+	Port int ` + "`json:\"port\"`" + `
+}
+
+type Inner struct {
+	Enabled bool ` + "`json:\"enabled,omitempty\"`" + `
+}
+`
+
+func generateWithExample(t *testing.T, example string) (string, error) {
+	dir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "testdata"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "testdata", "blah.yaml"), []byte(example), 0644))
+
+	builder, _, _ := construct(t, map[string]string{"base/foo/foo.go": exampleTestSource}, namer.NewRawNamer("o", nil))
+	c, err := generator.NewContext(builder, namer.NameSystems{
+		"raw": namer.NewRawNamer("", nil),
+		"private": &namer.NameStrategy{
+			Join: func(pre string, in []string, post string) string {
+				return strings.Join(in, "_")
+			},
+		},
+	}, "raw")
+	require.NoError(t, err)
+	c.Universe.Package("base/foo").SourcePath = dir
+
+	buf := &bytes.Buffer{}
+	sw := generator.NewSnippetWriter(buf, c, "$", "$")
+	err = newOpenAPITypeWriter(sw, c).generate(c.Universe.Type(types.Name{Package: "base/foo", Name: "Blah"}))
+	return buf.String(), err
+}
+
+func TestExampleFile(t *testing.T) {
+	out, err := generateWithExample(t, `
+name: foo
+replicas: 3
+labels:
+  app: foo
+items:
+- port: 80
+enabled: true
+`)
+	require.NoError(t, err)
+	assert.Contains(t, out, "SwaggerSchemaProps: spec.SwaggerSchemaProps{\n"+
+		`Example: map[string]interface {}{"enabled":true, "items":[]interface {}{map[string]interface {}{"port":80}}, "labels":map[string]interface {}{"app":"foo"}, "name":"foo", "replicas":3},`+"\n"+
+		"},\n")
+
+	for _, tc := range []struct {
+		example string
+		err     string
+	}{
+		{example: `{"replicas": 3}`, err: "name: required field is missing"},
+		{example: `{"name": "foo", "size": 3}`, err: "size: unknown field"},
+		{example: `{"name": 1}`, err: "name: expected string, got number"},
+		{example: `{"name": "foo", "replicas": 1.5}`, err: "replicas: expected integer, got number"},
+		{example: `{"name": "foo", "items": [{"port": "80"}]}`, err: "items[0].port: expected integer, got string"},
+		{example: `{"name": "foo", "labels": {"app": true}}`, err: `labels["app"]: expected string, got boolean`},
+		{example: `[]`, err: "<root>: expected object, got array"},
+	} {
+		_, err := generateWithExample(t, tc.example)
+		if assert.Error(t, err, tc.example) {
+			assert.Equal(t, "invalid example: "+tc.err, err.Error(), tc.example)
+		}
+	}
+}
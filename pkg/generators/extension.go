@@ -19,6 +19,7 @@ package generators
 import (
 	"fmt"
 	"sort"
+	"strconv"
 	"strings"
 
 	"k8s.io/gengo/examples/set-gen/sets"
@@ -27,6 +28,14 @@ import (
 
 const extensionPrefix = "x-kubernetes-"
 
+const (
+	// deprecatedPrefix starts the paragraph of a comment marking a Go type
+	// or field as deprecated.
+	deprecatedPrefix = "Deprecated:"
+	// deprecationMessageExtension holds the text of the deprecation paragraph.
+	deprecationMessageExtension = "x-kubernetes-deprecation-message"
+)
+
 // extensionAttributes encapsulates common traits for particular extensions.
 type extensionAttributes struct {
 	xName         string
@@ -185,9 +194,53 @@ func parseExtensions(comments []string) ([]extension, []error) {
 		}
 		extensions = append(extensions, e)
 	}
+	// Finally, generate the deprecation message from a "Deprecated:" paragraph.
+	if message, deprecated := parseDeprecation(comments); deprecated && message != "" {
+		quoted := strconv.Quote(message)
+		extensions = append(extensions, extension{
+			idlTag: deprecatedPrefix,
+			xName:  deprecationMessageExtension,
+			values: []string{quoted[1 : len(quoted)-1]},
+		})
+	}
 	return extensions, errors
 }
 
+// parseDeprecation looks for a paragraph starting with "Deprecated:", the Go
+// convention to mark an identifier as deprecated, and returns its text
+// without the prefix, joined on a single line.
+func parseDeprecation(comments []string) (string, bool) {
+	var message []string
+	deprecated := false
+	paragraphStart := true
+	for _, line := range comments {
+		// Ignore all lines after ---, as for descriptions
+		if line == "---" {
+			break
+		}
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "":
+			if deprecated {
+				return strings.Join(message, " "), true
+			}
+			paragraphStart = true
+			continue
+		case deprecated:
+			if !strings.HasPrefix(line, "+") {
+				message = append(message, line)
+			}
+		case paragraphStart && strings.HasPrefix(line, deprecatedPrefix):
+			deprecated = true
+			if rest := strings.TrimSpace(strings.TrimPrefix(line, deprecatedPrefix)); rest != "" {
+				message = append(message, rest)
+			}
+		}
+		paragraphStart = false
+	}
+	return strings.Join(message, " "), deprecated
+}
+
 func validateMemberExtensions(extensions []extension, m *types.Member) []error {
 	errors := []error{}
 	for _, e := range extensions {
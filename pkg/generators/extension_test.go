@@ -510,3 +510,38 @@ func TestValidateMemberExtensions(t *testing.T) {
 	}
 
 }
+
+func TestParseDeprecation(t *testing.T) {
+	var tests = []struct {
+		comments   []string
+		deprecated bool
+		message    string
+	}{
+		{comments: []string{"Foo is a test."}},
+		{comments: []string{"Foo is a test.", "", "Deprecated: use Bar instead."}, deprecated: true, message: "use Bar instead."},
+		{
+			comments:   []string{"Foo is a test.", "", "Deprecated: use Bar", "instead.", "", "More text.", "+optional"},
+			deprecated: true,
+			message:    "use Bar instead.",
+		},
+		{comments: []string{"Deprecated:", "use \"Bar\"."}, deprecated: true, message: `use "Bar".`},
+		{comments: []string{"Deprecated:"}, deprecated: true},
+		// not at the start of a paragraph
+		{comments: []string{"Foo is a test.", "Deprecated: not really."}},
+		{comments: []string{"Foo is a test.", "---", "Deprecated: hidden."}},
+	}
+	for i, test := range tests {
+		message, deprecated := parseDeprecation(test.comments)
+		if deprecated != test.deprecated || message != test.message {
+			t.Errorf("[%d] expected (%q, %v), got (%q, %v)", i, test.message, test.deprecated, message, deprecated)
+		}
+	}
+
+	extensions, _ := parseExtensions([]string{"Deprecated: use \"Bar\".", "+listType=atomic"})
+	if len(extensions) != 2 {
+		t.Fatalf("expected 2 extensions, got %v", extensions)
+	}
+	if e := extensions[1]; e.xName != "x-kubernetes-deprecation-message" || !reflect.DeepEqual(e.values, []string{`use \"Bar\".`}) {
+		t.Errorf("unexpected deprecation extension %v", e)
+	}
+}
@@ -18,6 +18,8 @@ package generators
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -43,6 +45,15 @@ const tagDefault = "default"
 const (
 	tagValueTrue  = "true"
 	tagValueFalse = "false"
+	// tagValueRef marks a type whose schema is defined elsewhere, e.g.
+	// +k8s:openapi-gen=x-kubernetes-ref:k8s.io/api/core/v1.PodSpec
+	tagValueRef = "x-kubernetes-ref"
+	// tagValueClosed marks a struct whose schema rejects properties other
+	// than its fields, through additionalProperties: false.
+	tagValueClosed = "closed"
+	// tagValueOpen keeps the schema of a struct open when all structs are
+	// closed by the generator.
+	tagValueOpen = "open"
 )
 
 // Used for temporary validation of patch struct tags.
@@ -77,6 +88,17 @@ func hasOpenAPITagValue(comments []string, value string) bool {
 	return false
 }
 
+// getExternalRef returns the name of the definition a type refers to through
+// the x-kubernetes-ref tag value, or "" if the type carries no such tag.
+func getExternalRef(t *types.Type) string {
+	for _, val := range getOpenAPITagValue(t.CommentLines) {
+		if strings.HasPrefix(val, tagValueRef+":") {
+			return strings.TrimSpace(strings.TrimPrefix(val, tagValueRef+":"))
+		}
+	}
+	return ""
+}
+
 // hasOptionalTag returns true if the member has +optional in its comments or
 // omitempty in its json tags.
 func hasOptionalTag(m *types.Member) bool {
@@ -113,20 +135,48 @@ type openAPIGen struct {
 	// TargetPackage is the package that will get GetOpenAPIDefinitions function returns all open API definitions.
 	targetPackage string
 	imports       namer.ImportTracker
+	// maxErrors is the number of type errors after which generation stops.
+	// Zero means all errors are collected.
+	maxErrors int
+	errs      errorList
+	positions *declPositions
+	// hashes holds the hash of the generated definition of each type, keyed
+	// by type name. It is nil unless definition hashes are emitted.
+	hashes map[string]string
+	// emitV3 adds a GetOpenAPIV3Definitions function returning the native
+	// OpenAPI v3 definitions of the types.
+	emitV3 bool
+	// closedStructs closes the schemas of all structs not tagged as open.
+	closedStructs bool
+	// recursiveChains holds the recursive chains of types found while
+	// generating, see recursionTracker.
+	recursiveChains map[string]bool
 }
 
-func newOpenAPIGen(sanitizedName string, targetPackage string) generator.Generator {
-	return &openAPIGen{
+func newOpenAPIGen(sanitizedName string, targetPackage string, maxErrors int, emitHashes, emitV3, closedStructs bool) generator.Generator {
+	g := &openAPIGen{
 		DefaultGen: generator.DefaultGen{
 			OptionalName: sanitizedName,
 		},
-		imports:       generator.NewImportTracker(),
-		targetPackage: targetPackage,
+		imports:         generator.NewImportTracker(),
+		targetPackage:   targetPackage,
+		maxErrors:       maxErrors,
+		emitV3:          emitV3,
+		closedStructs:   closedStructs,
+		recursiveChains: map[string]bool{},
 	}
+	if emitHashes {
+		g.hashes = map[string]string{}
+	}
+	return g
 }
 
 const nameTmpl = "schema_$.type|private$"
 
+// nameV3Tmpl names the functions returning the v3 definition of types whose
+// v2 and v3 definitions differ.
+const nameV3Tmpl = "schema_v3_$.type|private$"
+
 func (g *openAPIGen) Namers(c *generator.Context) namer.NameSystems {
 	// Have the raw namer for this file track what it imports.
 	return namer.NameSystems{
@@ -168,6 +218,7 @@ func argsFromType(t *types.Type) generator.Args {
 }
 
 func (g *openAPIGen) Init(c *generator.Context, w io.Writer) error {
+	g.positions = newDeclPositions(c.Universe)
 	sw := generator.NewSnippetWriter(w, c, "$", "$")
 	sw.Do("func GetOpenAPIDefinitions(ref $.ReferenceCallback|raw$) map[string]$.OpenAPIDefinition|raw$ {\n", argsFromType(nil))
 	sw.Do("return map[string]$.OpenAPIDefinition|raw${\n", argsFromType(nil))
@@ -182,16 +233,87 @@ func (g *openAPIGen) Init(c *generator.Context, w io.Writer) error {
 	sw.Do("}\n", nil)
 	sw.Do("}\n\n", nil)
 
+	if g.emitV3 {
+		sw.Do("// GetOpenAPIV3Definitions returns the OpenAPI v3 definitions of the types, keyed\n", nil)
+		sw.Do("// like the result of GetOpenAPIDefinitions. Unlike those, the definitions keep\n", nil)
+		sw.Do("// v3-only constructs such as oneOf and nullable in place, and carry no embedded\n", nil)
+		sw.Do("// v2 schema.\n", nil)
+		sw.Do("func GetOpenAPIV3Definitions(ref $.ReferenceCallback|raw$) map[string]$.OpenAPIDefinition|raw$ {\n", argsFromType(nil))
+		sw.Do("return map[string]$.OpenAPIDefinition|raw${\n", argsFromType(nil))
+		for _, t := range c.Order {
+			if err := newOpenAPITypeWriter(sw, c).generateV3Call(t); err != nil {
+				return err
+			}
+		}
+		sw.Do("}\n", nil)
+		sw.Do("}\n\n", nil)
+	}
+
 	return sw.Error()
 }
 
 func (g *openAPIGen) GenerateType(c *generator.Context, t *types.Type, w io.Writer) error {
 	klog.V(5).Infof("generating for type %v", t)
-	sw := generator.NewSnippetWriter(w, c, "$", "$")
-	err := newOpenAPITypeWriter(sw, c).generate(t)
+	buf := &bytes.Buffer{}
+	sw := generator.NewSnippetWriter(buf, c, "$", "$")
+	tw := newOpenAPITypeWriter(sw, c)
+	tw.positions = g.positions
+	tw.closedStructs = g.closedStructs
+	err := tw.generate(t)
+	if err == nil && g.emitV3 {
+		err = tw.generateV3(t)
+	}
+	for _, chain := range tw.recursion.chains {
+		g.recursiveChains[chain] = true
+	}
 	if err != nil {
+		g.errs = append(g.errs, g.positions.typeError(t, err))
+		if g.maxErrors > 0 && len(g.errs) >= g.maxErrors {
+			return g.errs
+		}
+		return nil
+	}
+	if err := sw.Error(); err != nil {
 		return err
 	}
+	if g.hashes != nil && buf.Len() > 0 {
+		sum := sha256.Sum256(buf.Bytes())
+		g.hashes[t.Name.String()] = hex.EncodeToString(sum[:])
+	}
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+func (g *openAPIGen) Finalize(c *generator.Context, w io.Writer) error {
+	chains := make([]string, 0, len(g.recursiveChains))
+	for chain := range g.recursiveChains {
+		chains = append(chains, chain)
+	}
+	sort.Strings(chains)
+	for _, chain := range chains {
+		klog.Warningf("recursive types, referenced where they recur: %s", chain)
+	}
+	if len(g.errs) > 0 {
+		return g.errs
+	}
+	if g.hashes == nil {
+		return nil
+	}
+	// Types whose definition is returned by their own methods have no
+	// generated definition to hash, and are left out.
+	sw := generator.NewSnippetWriter(w, c, "$", "$")
+	sw.Do("// GetOpenAPIDefinitionHashes returns a hash of the generated OpenAPI definition\n", nil)
+	sw.Do("// of each type, keyed like the result of GetOpenAPIDefinitions. A hash changes\n", nil)
+	sw.Do("// whenever the definition generated for the type changes.\n", nil)
+	sw.Do("func GetOpenAPIDefinitionHashes() map[string]string {\n", nil)
+	sw.Do("return map[string]string{\n", nil)
+	for _, t := range c.Order {
+		if hash, ok := g.hashes[t.Name.String()]; ok {
+			sw.Do("\"$.name$\": \"$.hash$\",\n", generator.Args{"name": t.Name.String(), "hash": hash})
+		}
+	}
+	sw.Do("}\n", nil)
+	sw.Do("}\n\n", nil)
 	return sw.Error()
 }
 
@@ -227,6 +349,12 @@ type openAPITypeWriter struct {
 	refTypes               map[string]*types.Type
 	enumContext            *enumContext
 	GetDefinitionInterface *types.Type
+	positions              *declPositions
+	// closedStructs closes the schemas of all structs not tagged as open.
+	closedStructs bool
+	// recursion follows the types written in place, shared by the copies of
+	// the writer.
+	recursion *recursionTracker
 }
 
 func newOpenAPITypeWriter(sw *generator.SnippetWriter, c *generator.Context) openAPITypeWriter {
@@ -235,6 +363,7 @@ func newOpenAPITypeWriter(sw *generator.SnippetWriter, c *generator.Context) ope
 		context:       c,
 		refTypes:      map[string]*types.Type{},
 		enumContext:   newEnumContext(c),
+		recursion:     &recursionTracker{},
 	}
 }
 
@@ -299,6 +428,11 @@ func (g openAPITypeWriter) generateMembers(t *types.Type, required []string) ([]
 	for t.Kind == types.Pointer { // fast-forward to effective type containing members
 		t = t.Elem
 	}
+	if !g.recursion.enter(t) {
+		// t inlines itself, its members are already written
+		return required, nil
+	}
+	defer g.recursion.leave()
 	for _, m := range t.Members {
 		if hasOpenAPITagValue(m.CommentLines, tagValueFalse) {
 			continue
@@ -319,16 +453,25 @@ func (g openAPITypeWriter) generateMembers(t *types.Type, required []string) ([]
 		}
 		if err = g.generateProperty(&m, t); err != nil {
 			klog.Errorf("Error when generating: %v, %v\n", name, m)
-			return required, err
+			return required, g.positions.memberError(t, &m, err)
 		}
 	}
 	return required, nil
 }
 
 func (g openAPITypeWriter) generateCall(t *types.Type) error {
+	if isRecursiveContainer(t) {
+		g.Do("\"$.$\": ", t.Name)
+		g.Do(nameTmpl+"(ref),\n", argsFromType(t))
+		return g.Error()
+	}
 	// Only generate for struct type and ignore the rest
 	switch t.Kind {
 	case types.Struct:
+		if getExternalRef(t) != "" {
+			// referenced under the external name, nothing to register
+			return nil
+		}
 		args := argsFromType(t)
 		g.Do("\"$.$\": ", t.Name)
 
@@ -352,7 +495,80 @@ func (g openAPITypeWriter) generateCall(t *types.Type) error {
 	return g.Error()
 }
 
+// generateV3Call writes the entry of t in GetOpenAPIV3Definitions. Types
+// defining their own v3 definition, or v3 oneOf types, get it without the
+// v2 schema embedded; the others share their definition with v2.
+func (g openAPITypeWriter) generateV3Call(t *types.Type) error {
+	if isRecursiveContainer(t) {
+		// shares its definition with v2
+		return g.generateCall(t)
+	}
+	// Only generate for struct type and ignore the rest
+	switch t.Kind {
+	case types.Struct:
+		if getExternalRef(t) != "" {
+			// referenced under the external name, nothing to register
+			return nil
+		}
+		args := argsFromType(t)
+		g.Do("\"$.$\": ", t.Name)
+
+		hasV2Definition := hasOpenAPIDefinitionMethod(t)
+		hasV2DefinitionTypeAndFormat := hasOpenAPIDefinitionMethods(t)
+		hasV3OneOfTypes := hasOpenAPIV3OneOfMethod(t)
+		hasV3Definition := hasOpenAPIV3DefinitionMethod(t)
+
+		switch {
+		case hasV3Definition:
+			g.Do("$.type|raw${}.OpenAPIV3Definition(),\n", args)
+		case hasV2Definition:
+			g.Do("$.type|raw${}.OpenAPIDefinition(),\n", args)
+		case hasV2DefinitionTypeAndFormat && hasV3OneOfTypes:
+			g.Do(nameV3Tmpl+"(ref),\n", args)
+		default:
+			g.Do(nameTmpl+"(ref),\n", args)
+		}
+	}
+	return g.Error()
+}
+
+// generateV3 writes the function returning the v3 definition of t, for the
+// types whose v3 definition is generated and differs from the v2 one.
+func (g openAPITypeWriter) generateV3(t *types.Type) error {
+	if t.Kind != types.Struct || getExternalRef(t) != "" {
+		return nil
+	}
+	if hasOpenAPIDefinitionMethod(t) || hasOpenAPIV3DefinitionMethod(t) ||
+		!hasOpenAPIDefinitionMethods(t) || !hasOpenAPIV3OneOfMethod(t) {
+		return nil
+	}
+	args := argsFromType(t)
+	g.Do("func "+nameV3Tmpl+"(ref $.ReferenceCallback|raw$) $.OpenAPIDefinition|raw$ {\n", args)
+	g.Do("return ", nil)
+	g.generateV3OneOfDefinition(t)
+	g.Do("\n}\n\n", nil)
+	return g.Error()
+}
+
+// generateV3OneOfDefinition writes the v3 definition of a type with
+// OpenAPIV3OneOfTypes, OpenAPISchemaType and OpenAPISchemaFormat methods.
+func (g openAPITypeWriter) generateV3OneOfDefinition(t *types.Type) {
+	args := argsFromType(t)
+	g.Do("$.OpenAPIDefinition|raw${\n"+
+		"Schema: spec.Schema{\n"+
+		"SchemaProps: spec.SchemaProps{\n", args)
+	g.generateDescription(t.CommentLines)
+	g.Do("OneOf:common.GenerateOpenAPIV3OneOfSchema($.type|raw${}.OpenAPIV3OneOfTypes()),\n"+
+		"Format:$.type|raw${}.OpenAPISchemaFormat(),\n"+
+		"},\n"+
+		"},\n"+
+		"}", args)
+}
+
 func (g openAPITypeWriter) generate(t *types.Type) error {
+	if isRecursiveContainer(t) {
+		return g.generateRecursiveContainer(t)
+	}
 	// Only generate for struct type and ignore the rest
 	switch t.Kind {
 	case types.Struct:
@@ -365,6 +581,10 @@ func (g openAPITypeWriter) generate(t *types.Type) error {
 			// already invoked directly
 			return nil
 		}
+		if getExternalRef(t) != "" {
+			// the schema is defined elsewhere
+			return nil
+		}
 
 		args := argsFromType(t)
 		g.Do("func "+nameTmpl+"(ref $.ReferenceCallback|raw$) $.OpenAPIDefinition|raw$ {\n", args)
@@ -382,15 +602,9 @@ func (g openAPITypeWriter) generate(t *types.Type) error {
 			return nil
 		case hasV2DefinitionTypeAndFormat && hasV3OneOfTypes:
 			// generate v3 def.
-			g.Do("return common.EmbedOpenAPIDefinitionIntoV2Extension($.OpenAPIDefinition|raw${\n"+
-				"Schema: spec.Schema{\n"+
-				"SchemaProps: spec.SchemaProps{\n", args)
-			g.generateDescription(t.CommentLines)
-			g.Do("OneOf:common.GenerateOpenAPIV3OneOfSchema($.type|raw${}.OpenAPIV3OneOfTypes()),\n"+
-				"Format:$.type|raw${}.OpenAPISchemaFormat(),\n"+
-				"},\n"+
-				"},\n"+
-				"},", args)
+			g.Do("return common.EmbedOpenAPIDefinitionIntoV2Extension(", nil)
+			g.generateV3OneOfDefinition(t)
+			g.Do(",", nil)
 			// generate v2 def.
 			g.Do("$.OpenAPIDefinition|raw${\n"+
 				"Schema: spec.Schema{\n"+
@@ -419,6 +633,7 @@ func (g openAPITypeWriter) generate(t *types.Type) error {
 		}
 		g.Do("return $.OpenAPIDefinition|raw${\nSchema: spec.Schema{\nSchemaProps: spec.SchemaProps{\n", args)
 		g.generateDescription(t.CommentLines)
+		g.generateDeprecated(t.CommentLines)
 		g.Do("Type: []string{\"object\"},\n", nil)
 
 		// write members into a temporary buffer, in order to postpone writing out the Properties field. We only do
@@ -439,36 +654,102 @@ func (g openAPITypeWriter) generate(t *types.Type) error {
 		if len(required) > 0 {
 			g.Do("Required: []string{\"$.$\"},\n", strings.Join(required, "\",\""))
 		}
+		closed, err := g.isClosed(t)
+		if err != nil {
+			return err
+		}
+		if closed {
+			g.Do("AdditionalProperties: &spec.SchemaOrBool{Allows: false},\n", nil)
+		}
 		g.Do("},\n", nil)
+		if err := g.generateExample(t); err != nil {
+			return err
+		}
 		if err := g.generateStructExtensions(t); err != nil {
 			return err
 		}
 		g.Do("},\n", nil)
+		g.generateDependencies()
+		g.Do("}\n}\n\n", nil)
+	}
+	return nil
+}
 
-		// Map order is undefined, sort them or we may get a different file generated each time.
-		keys := []string{}
-		for k := range g.refTypes {
-			keys = append(keys, k)
-		}
-		sort.Strings(keys)
-		deps := []string{}
-		for _, k := range keys {
-			v := g.refTypes[k]
-			if t, _ := openapi.OpenAPITypeFormat(v.String()); t != "" {
-				// This is a known type, we do not need a reference to it
-				// Will eliminate special case of time.Time
-				continue
-			}
-			deps = append(deps, k)
+// generateDependencies writes the Dependencies of a definition, the types it
+// references.
+func (g openAPITypeWriter) generateDependencies() {
+	// Map order is undefined, sort them or we may get a different file generated each time.
+	keys := []string{}
+	for k := range g.refTypes {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	deps := []string{}
+	for _, k := range keys {
+		v := g.refTypes[k]
+		if t, _ := openapi.OpenAPITypeFormat(v.String()); t != "" {
+			// This is a known type, we do not need a reference to it
+			// Will eliminate special case of time.Time
+			continue
 		}
-		if len(deps) > 0 {
-			g.Do("Dependencies: []string{\n", args)
-			for _, k := range deps {
-				g.Do("\"$.$\",", k)
-			}
-			g.Do("},\n", nil)
+		deps = append(deps, k)
+	}
+	if len(deps) > 0 {
+		g.Do("Dependencies: []string{\n", nil)
+		for _, k := range deps {
+			g.Do("\"$.$\",", k)
+		}
+		g.Do("},\n", nil)
+	}
+}
+
+// isClosed returns true if the schema of struct t must reject properties
+// other than its fields. Structs are closed by the closed tag value, or by
+// the generator unless they have the open tag value. A struct inlining
+// something else than structs, e.g. a map, cannot be closed.
+func (g openAPITypeWriter) isClosed(t *types.Type) (bool, error) {
+	closed := hasOpenAPITagValue(t.CommentLines, tagValueClosed)
+	open := hasOpenAPITagValue(t.CommentLines, tagValueOpen)
+	if closed && open {
+		return false, fmt.Errorf("type %v cannot be both %s and %s", t, tagValueClosed, tagValueOpen)
+	}
+	if !closed && (open || !g.closedStructs) {
+		return false, nil
+	}
+	if m := inlinedNonStruct(t); m != nil {
+		if closed {
+			return false, fmt.Errorf("type %v cannot be %s, it inlines member %s of kind %s", t, tagValueClosed, m.Name, resolveAliasAndPtrType(m.Type).Kind)
+		}
+		return false, nil
+	}
+	return true, nil
+}
+
+// inlinedNonStruct returns the first member inlined in t, directly or through
+// inlined structs, that is not a struct, or nil if there is none.
+func inlinedNonStruct(t *types.Type) *types.Member {
+	return inlinedNonStructOf(t, map[*types.Type]bool{})
+}
+
+// inlinedNonStructOf is inlinedNonStruct, skipping the structs already seen
+// since structs may inline each other.
+func inlinedNonStructOf(t *types.Type, seen map[*types.Type]bool) *types.Member {
+	t = resolveAliasAndPtrType(t)
+	if seen[t] {
+		return nil
+	}
+	seen[t] = true
+	for i := range t.Members {
+		m := &t.Members[i]
+		if hasOpenAPITagValue(m.CommentLines, tagValueFalse) || !shouldInlineMembers(m) {
+			continue
+		}
+		if resolveAliasAndPtrType(m.Type).Kind != types.Struct {
+			return m
+		}
+		if inlined := inlinedNonStructOf(m.Type, seen); inlined != nil {
+			return inlined
 		}
-		g.Do("}\n}\n\n", nil)
 	}
 	return nil
 }
@@ -478,13 +759,13 @@ func (g openAPITypeWriter) generateStructExtensions(t *types.Type) error {
 	// Initially, we will only log struct extension errors.
 	if len(errors) > 0 {
 		for _, e := range errors {
-			klog.Errorf("[%s]: %s\n", t.String(), e)
+			klog.Errorf("%v\n", g.positions.typeError(t, e))
 		}
 	}
 	unions, errors := parseUnions(t)
 	if len(errors) > 0 {
 		for _, e := range errors {
-			klog.Errorf("[%s]: %s\n", t.String(), e)
+			klog.Errorf("%v\n", g.positions.typeError(t, e))
 		}
 	}
 
@@ -499,9 +780,8 @@ func (g openAPITypeWriter) generateMemberExtensions(m *types.Member, parent *typ
 	errors := append(parseErrors, validationErrors...)
 	// Initially, we will only log member extension errors.
 	if len(errors) > 0 {
-		errorPrefix := fmt.Sprintf("[%s] %s:", parent.String(), m.String())
 		for _, e := range errors {
-			klog.V(2).Infof("%s %s\n", errorPrefix, e)
+			klog.V(2).Infof("%v\n", g.positions.memberError(parent, m, e))
 		}
 	}
 	g.emitExtensions(extensions, nil)
@@ -651,6 +931,14 @@ func (g openAPITypeWriter) generateDescription(CommentLines []string) {
 	}
 }
 
+// generateDeprecated marks the schema as deprecated if the comments hold a
+// "Deprecated:" paragraph. The message itself is emitted as an extension.
+func (g openAPITypeWriter) generateDeprecated(CommentLines []string) {
+	if _, deprecated := parseDeprecation(CommentLines); deprecated {
+		g.Do("Deprecated: true,\n", nil)
+	}
+}
+
 func (g openAPITypeWriter) generateProperty(m *types.Member, parent *types.Type) error {
 	name := getReferableName(m)
 	if name == "" {
@@ -669,6 +957,7 @@ func (g openAPITypeWriter) generateProperty(m *types.Member, parent *types.Type)
 		extraComments = enumType.DescriptionLines()
 	}
 	g.generateDescription(append(m.CommentLines, extraComments...))
+	g.generateDeprecated(m.CommentLines)
 	jsonTags := getJsonTags(m)
 	if len(jsonTags) > 1 && jsonTags[1] == "string" {
 		g.generateSimpleProperty("string", "")
@@ -695,11 +984,11 @@ func (g openAPITypeWriter) generateProperty(m *types.Member, parent *types.Type)
 	case types.Builtin:
 		return fmt.Errorf("please add type %v to getOpenAPITypeFormat function", t)
 	case types.Map:
-		if err := g.generateMapProperty(t); err != nil {
+		if err := g.generateMapProperty(m.Type); err != nil {
 			return fmt.Errorf("failed to generate map property in %v: %v: %v", parent, m.Name, err)
 		}
 	case types.Slice, types.Array:
-		if err := g.generateSliceProperty(t); err != nil {
+		if err := g.generateSliceProperty(m.Type); err != nil {
 			return fmt.Errorf("failed to generate slice property in %v: %v: %v", parent, m.Name, err)
 		}
 	case types.Struct, types.Interface:
@@ -717,8 +1006,12 @@ func (g openAPITypeWriter) generateSimpleProperty(typeString, format string) {
 }
 
 func (g openAPITypeWriter) generateReferenceProperty(t *types.Type) {
-	g.refTypes[t.Name.String()] = t
-	g.Do("Ref: ref(\"$.$\"),\n", t.Name.String())
+	name := t.Name.String()
+	if ref := getExternalRef(t); ref != "" {
+		name = ref
+	}
+	g.refTypes[name] = t
+	g.Do("Ref: ref(\"$.$\"),\n", name)
 }
 
 func resolveAliasAndEmbeddedType(t *types.Type) *types.Type {
@@ -751,7 +1044,17 @@ func resolveAliasAndPtrType(t *types.Type) *types.Type {
 	return t
 }
 
+// generateMapProperty writes the schema of map type t, which may be a pointer
+// or a named type.
 func (g openAPITypeWriter) generateMapProperty(t *types.Type) error {
+	if named := namedContainer(t); named != nil {
+		if !g.recursion.enter(named) {
+			g.generateReferenceProperty(named)
+			return nil
+		}
+		defer g.recursion.leave()
+	}
+	t = resolveAliasAndPtrType(t)
 	keyType := resolveAliasAndPtrType(t.Key)
 	elemType := resolveAliasAndPtrType(t.Elem)
 
@@ -777,11 +1080,11 @@ func (g openAPITypeWriter) generateMapProperty(t *types.Type) error {
 	case types.Struct:
 		g.generateReferenceProperty(elemType)
 	case types.Slice, types.Array:
-		if err := g.generateSliceProperty(elemType); err != nil {
+		if err := g.generateSliceProperty(t.Elem); err != nil {
 			return err
 		}
 	case types.Map:
-		if err := g.generateMapProperty(elemType); err != nil {
+		if err := g.generateMapProperty(t.Elem); err != nil {
 			return err
 		}
 	default:
@@ -791,7 +1094,17 @@ func (g openAPITypeWriter) generateMapProperty(t *types.Type) error {
 	return nil
 }
 
+// generateSliceProperty writes the schema of slice or array type t, which may
+// be a pointer or a named type.
 func (g openAPITypeWriter) generateSliceProperty(t *types.Type) error {
+	if named := namedContainer(t); named != nil {
+		if !g.recursion.enter(named) {
+			g.generateReferenceProperty(named)
+			return nil
+		}
+		defer g.recursion.leave()
+	}
+	t = resolveAliasAndPtrType(t)
 	elemType := resolveAliasAndPtrType(t.Elem)
 	g.Do("Type: []string{\"array\"},\n", nil)
 	g.Do("Items: &spec.SchemaOrArray{\nSchema: &spec.Schema{\nSchemaProps: spec.SchemaProps{\n", nil)
@@ -810,11 +1123,11 @@ func (g openAPITypeWriter) generateSliceProperty(t *types.Type) error {
 	case types.Struct:
 		g.generateReferenceProperty(elemType)
 	case types.Slice, types.Array:
-		if err := g.generateSliceProperty(elemType); err != nil {
+		if err := g.generateSliceProperty(t.Elem); err != nil {
 			return err
 		}
 	case types.Map:
-		if err := g.generateMapProperty(elemType); err != nil {
+		if err := g.generateMapProperty(t.Elem); err != nil {
 			return err
 		}
 	default:
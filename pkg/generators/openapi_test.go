@@ -24,6 +24,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"k8s.io/gengo/generator"
 	"k8s.io/gengo/namer"
@@ -460,6 +461,75 @@ Dependencies: []string{
 `, funcBuffer.String())
 }
 
+func TestExternalRef(t *testing.T) {
+	callErr, funcErr, assert, callBuffer, funcBuffer := testOpenAPITypeWriter(t, `
+package foo
+
+// Wrapper reuses the schema of a foreign type.
+// +k8s:openapi-gen=x-kubernetes-ref:k8s.io/api/core/v1.PodSpec
+type Wrapper struct {
+  Spec interface{}
+}
+
+// Blah demonstrate a struct with a field referring to an external schema.
+type Blah struct {
+  // A wrapped field
+  Field Wrapper
+}
+	`)
+	if callErr != nil {
+		t.Fatal(callErr)
+	}
+	if funcErr != nil {
+		t.Fatal(funcErr)
+	}
+	assert.Equal(`"base/foo.Blah": schema_base_foo_Blah(ref),
+`, callBuffer.String())
+	assert.Equal(`func schema_base_foo_Blah(ref common.ReferenceCallback) common.OpenAPIDefinition {
+return common.OpenAPIDefinition{
+Schema: spec.Schema{
+SchemaProps: spec.SchemaProps{
+Description: "Blah demonstrate a struct with a field referring to an external schema.",
+Type: []string{"object"},
+Properties: map[string]spec.Schema{
+"Field": {
+SchemaProps: spec.SchemaProps{
+Description: "A wrapped field",
+Default: map[string]interface {}{},
+Ref: ref("k8s.io/api/core/v1.PodSpec"),
+},
+},
+},
+Required: []string{"Field"},
+},
+},
+Dependencies: []string{
+"k8s.io/api/core/v1.PodSpec",},
+}
+}
+
+`, funcBuffer.String())
+}
+
+func TestExternalRefSkipsDefinition(t *testing.T) {
+	callErr, funcErr, assert, callBuffer, funcBuffer := testOpenAPITypeWriter(t, `
+package foo
+
+// +k8s:openapi-gen=x-kubernetes-ref:k8s.io/api/core/v1.PodSpec
+type Blah struct {
+  String string
+}
+	`)
+	if callErr != nil {
+		t.Fatal(callErr)
+	}
+	if funcErr != nil {
+		t.Fatal(funcErr)
+	}
+	assert.Equal(``, callBuffer.String())
+	assert.Equal(``, funcBuffer.String())
+}
+
 func TestNestedStructPointer(t *testing.T) {
 	callErr, funcErr, assert, callBuffer, funcBuffer := testOpenAPITypeWriter(t, `
 package foo
@@ -1692,3 +1762,460 @@ Extensions: spec.Extensions{
 
 `, funcBuffer.String())
 }
+
+func TestDeprecated(t *testing.T) {
+	callErr, funcErr, assert, _, funcBuffer := testOpenAPITypeWriter(t, `
+package foo
+
+// Blah is a test.
+//
+// Deprecated: use Bar instead.
+// +k8s:openapi-gen=true
+type Blah struct {
+	// Value is the value.
+	//
+	// Deprecated: use "NewValue",
+	// removed in v2.
+	Value string
+	NewValue string
+}`)
+	if callErr != nil {
+		t.Fatal(callErr)
+	}
+	if funcErr != nil {
+		t.Fatal(funcErr)
+	}
+	assert.Equal(`func schema_base_foo_Blah(ref common.ReferenceCallback) common.OpenAPIDefinition {
+return common.OpenAPIDefinition{
+Schema: spec.Schema{
+SchemaProps: spec.SchemaProps{
+Description: "Blah is a test.\n\nDeprecated: use Bar instead.",
+Deprecated: true,
+Type: []string{"object"},
+Properties: map[string]spec.Schema{
+"Value": {
+VendorExtensible: spec.VendorExtensible{
+Extensions: spec.Extensions{
+"x-kubernetes-deprecation-message": "use \"NewValue\", removed in v2.",
+},
+},
+SchemaProps: spec.SchemaProps{
+Description: "Value is the value.\n\nDeprecated: use \"NewValue\", removed in v2.",
+Deprecated: true,
+Default: "",
+Type: []string{"string"},
+Format: "",
+},
+},
+"NewValue": {
+SchemaProps: spec.SchemaProps{
+Default: "",
+Type: []string{"string"},
+Format: "",
+},
+},
+},
+Required: []string{"Value","NewValue"},
+},
+VendorExtensible: spec.VendorExtensible{
+Extensions: spec.Extensions{
+"x-kubernetes-deprecation-message": "use Bar instead.",
+},
+},
+},
+}
+}
+
+`, funcBuffer.String())
+}
+
+func TestDefinitionHashes(t *testing.T) {
+	generate := func(emitHashes bool) string {
+		c, universe := constructWithSource(t)
+		g := newOpenAPIGen("openapi_generated", "base/output", 1, emitHashes, false, false)
+		w := &bytes.Buffer{}
+		require.NoError(t, g.Init(c, w))
+		require.NoError(t, g.GenerateType(c, universe.Type(types.Name{Package: "base/foo", Name: "Good"}), w))
+		require.NoError(t, g.Finalize(c, w))
+		return w.String()
+	}
+
+	assert.NotContains(t, generate(false), "GetOpenAPIDefinitionHashes")
+
+	out := generate(true)
+	assert.Contains(t, out, "func GetOpenAPIDefinitionHashes() map[string]string {\n")
+	assert.Regexp(t, `"base/foo\.Good": "[0-9a-f]{64}",`, out)
+	assert.NotRegexp(t, `"base/foo\.BadKey": "`, out, "types that were not generated have no hash")
+	assert.Equal(t, out, generate(true), "hashes should be stable")
+}
+
+func testOpenAPIV3TypeWriter(t *testing.T, code string) (*bytes.Buffer, *bytes.Buffer) {
+	builder, universe, _ := construct(t, map[string]string{"base/foo/bar.go": code}, namer.NewRawNamer("o", nil))
+	context, err := generator.NewContext(builder, namer.NameSystems{
+		"raw": namer.NewRawNamer("", nil),
+		"private": &namer.NameStrategy{
+			Join: func(pre string, in []string, post string) string {
+				return strings.Join(in, "_")
+			},
+			PrependPackageNames: 4,
+		},
+	}, "raw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	blahT := universe.Type(types.Name{Package: "base/foo", Name: "Blah"})
+
+	callBuffer := &bytes.Buffer{}
+	if err := newOpenAPITypeWriter(generator.NewSnippetWriter(callBuffer, context, "$", "$"), context).generateV3Call(blahT); err != nil {
+		t.Fatal(err)
+	}
+	funcBuffer := &bytes.Buffer{}
+	if err := newOpenAPITypeWriter(generator.NewSnippetWriter(funcBuffer, context, "$", "$"), context).generateV3(blahT); err != nil {
+		t.Fatal(err)
+	}
+	return callBuffer, funcBuffer
+}
+
+func TestV3Definitions(t *testing.T) {
+	var tests = []struct {
+		name         string
+		code         string
+		expectedCall string
+		expectedFunc string
+	}{
+		{
+			name: "generated",
+			code: `
+package foo
+
+type Blah struct {
+	String string ` + "`json:\"String\"`" + `
+}
+`,
+			expectedCall: `"base/foo.Blah": schema_base_foo_Blah(ref),
+`,
+		},
+		{
+			name: "v3 definition",
+			code: `
+package foo
+
+import openapi "k8s.io/kube-openapi/pkg/common"
+
+type Blah struct {
+}
+
+func (_ Blah) OpenAPIV3Definition() openapi.OpenAPIDefinition {
+	return openapi.OpenAPIDefinition{}
+}
+
+func (_ Blah) OpenAPIDefinition() openapi.OpenAPIDefinition {
+	return openapi.OpenAPIDefinition{}
+}
+`,
+			expectedCall: `"base/foo.Blah": foo.Blah{}.OpenAPIV3Definition(),
+`,
+		},
+		{
+			name: "v2 definition",
+			code: `
+package foo
+
+import openapi "k8s.io/kube-openapi/pkg/common"
+
+type Blah struct {
+}
+
+func (_ Blah) OpenAPIDefinition() openapi.OpenAPIDefinition {
+	return openapi.OpenAPIDefinition{}
+}
+`,
+			expectedCall: `"base/foo.Blah": foo.Blah{}.OpenAPIDefinition(),
+`,
+		},
+		{
+			name: "v3 one of types",
+			code: `
+package foo
+
+// Blah is a custom type
+type Blah struct {
+}
+
+func (_ Blah) OpenAPISchemaType() []string { return []string{"string"} }
+func (_ Blah) OpenAPISchemaFormat() string { return "date-time" }
+func (_ Blah) OpenAPIV3OneOfTypes() []string { return []string{"string", "number"} }
+`,
+			expectedCall: `"base/foo.Blah": schema_v3_base_foo_Blah(ref),
+`,
+			expectedFunc: `func schema_v3_base_foo_Blah(ref common.ReferenceCallback) common.OpenAPIDefinition {
+return common.OpenAPIDefinition{
+Schema: spec.Schema{
+SchemaProps: spec.SchemaProps{
+Description: "Blah is a custom type",
+OneOf:common.GenerateOpenAPIV3OneOfSchema(foo.Blah{}.OpenAPIV3OneOfTypes()),
+Format:foo.Blah{}.OpenAPISchemaFormat(),
+},
+},
+}
+}
+
+`,
+		},
+		{
+			name: "v2 type and format",
+			code: `
+package foo
+
+type Blah struct {
+}
+
+func (_ Blah) OpenAPISchemaType() []string { return []string{"string"} }
+func (_ Blah) OpenAPISchemaFormat() string { return "date-time" }
+`,
+			expectedCall: `"base/foo.Blah": schema_base_foo_Blah(ref),
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			callBuffer, funcBuffer := testOpenAPIV3TypeWriter(t, tt.code)
+			assert.Equal(t, tt.expectedCall, callBuffer.String())
+			assert.Equal(t, tt.expectedFunc, funcBuffer.String())
+		})
+	}
+}
+
+func TestEmitV3Definitions(t *testing.T) {
+	generate := func(emitV3 bool) string {
+		c, universe := constructWithSource(t)
+		g := newOpenAPIGen("openapi_generated", "base/output", 1, false, emitV3, false)
+		w := &bytes.Buffer{}
+		require.NoError(t, g.Init(c, w))
+		require.NoError(t, g.GenerateType(c, universe.Type(types.Name{Package: "base/foo", Name: "Good"}), w))
+		require.NoError(t, g.Finalize(c, w))
+		return w.String()
+	}
+
+	assert.NotContains(t, generate(false), "GetOpenAPIV3Definitions")
+
+	out := generate(true)
+	assert.Contains(t, out, "func GetOpenAPIV3Definitions(ref common.ReferenceCallback) map[string]common.OpenAPIDefinition {\n")
+	assert.Contains(t, out, `"base/foo.Good": schema_Good(ref),`)
+}
+
+func TestClosedStructs(t *testing.T) {
+	source := `
+package foo
+
+// +k8s:openapi-gen=true
+type Inner struct {
+	Value string
+}
+
+// +k8s:openapi-gen=true
+type Closed struct {
+	Inner ` + "`json:\",inline\"`" + `
+}
+
+// +k8s:openapi-gen=true
+// +k8s:openapi-gen=open
+type Open struct {
+	Value string
+}
+
+// +k8s:openapi-gen=true
+type WithMap struct {
+	Extra map[string]string ` + "`json:\",inline\"`" + `
+}
+
+// +k8s:openapi-gen=true
+// +k8s:openapi-gen=closed
+type ClosedWithMap struct {
+	Extra map[string]string ` + "`json:\",inline\"`" + `
+}
+
+// +k8s:openapi-gen=closed
+// +k8s:openapi-gen=open
+type Both struct {
+	Value string
+}
+`
+	tests := []struct {
+		name          string
+		typeName      string
+		tag           string
+		closedStructs bool
+		closed        bool
+		err           string
+	}{
+		{name: "default", typeName: "Closed"},
+		{name: "tagged", typeName: "Closed", tag: "closed", closed: true},
+		{name: "all structs", typeName: "Closed", closedStructs: true, closed: true},
+		{name: "open", typeName: "Open", closedStructs: true},
+		{name: "inlined map", typeName: "WithMap", closedStructs: true},
+		{name: "tagged inlined map", typeName: "ClosedWithMap", err: "cannot be closed, it inlines member Extra of kind Map"},
+		{name: "both tags", typeName: "Both", err: "cannot be both closed and open"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code := source
+			if tt.tag != "" {
+				code = strings.Replace(code, "\ntype "+tt.typeName+" ", "\n// +k8s:openapi-gen="+tt.tag+"\ntype "+tt.typeName+" ", 1)
+			}
+			rawNamer := namer.NewRawNamer("o", nil)
+			namers := namer.NameSystems{
+				"raw": namer.NewRawNamer("", nil),
+				"private": &namer.NameStrategy{
+					Join: func(pre string, in []string, post string) string {
+						return strings.Join(in, "_")
+					},
+					PrependPackageNames: 4,
+				},
+			}
+			builder, universe, _ := construct(t, map[string]string{"base/foo/bar.go": code}, rawNamer)
+			context, err := generator.NewContext(builder, namers, "raw")
+			if err != nil {
+				t.Fatal(err)
+			}
+			buffer := &bytes.Buffer{}
+			tw := newOpenAPITypeWriter(generator.NewSnippetWriter(buffer, context, "$", "$"), context)
+			tw.closedStructs = tt.closedStructs
+			err = tw.generate(universe.Type(types.Name{Package: "base/foo", Name: tt.typeName}))
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("expected error containing %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.Contains(buffer.String(), "AdditionalProperties: &spec.SchemaOrBool{Allows: false},\n},\n"); got != tt.closed {
+				t.Errorf("expected closed %v, got:\n%s", tt.closed, buffer.String())
+			}
+		})
+	}
+}
+
+func TestRecursiveTypes(t *testing.T) {
+	source := `
+// +k8s:openapi-gen=true
+package foo
+
+// Tree is a map of trees.
+type Tree map[string]Tree
+
+type List []*List
+
+type Matrix [][]Matrix
+
+type Node struct {
+	Children Tree
+	Lists    List
+	Matrix   Matrix
+	Next     *Node
+	*Base    ` + "`json:\",inline\"`" + `
+}
+
+type Base struct {
+	Name  string
+	*Node ` + "`json:\",inline\"`" + `
+}
+`
+	builder, universe, _ := construct(t, map[string]string{"base/foo/bar.go": source}, namer.NewRawNamer("o", nil))
+	context, err := generator.NewContext(builder, namer.NameSystems{
+		"raw": namer.NewRawNamer("", nil),
+		"private": &namer.NameStrategy{
+			Join: func(pre string, in []string, post string) string {
+				return strings.Join(in, "_")
+			},
+			PrependPackageNames: 4,
+		},
+	}, "raw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	generate := func(name string) (string, string, []string) {
+		typ := universe.Type(types.Name{Package: "base/foo", Name: name})
+		callBuffer := &bytes.Buffer{}
+		require.NoError(t, newOpenAPITypeWriter(generator.NewSnippetWriter(callBuffer, context, "$", "$"), context).generateCall(typ))
+		funcBuffer := &bytes.Buffer{}
+		tw := newOpenAPITypeWriter(generator.NewSnippetWriter(funcBuffer, context, "$", "$"), context)
+		require.NoError(t, tw.generate(typ))
+		return callBuffer.String(), funcBuffer.String(), tw.recursion.chains
+	}
+
+	call, funcs, chains := generate("Tree")
+	assert.Equal(t, "\"base/foo.Tree\": schema_base_foo_Tree(ref),\n", call)
+	assert.Equal(t, `func schema_base_foo_Tree(ref common.ReferenceCallback) common.OpenAPIDefinition {
+return common.OpenAPIDefinition{
+Schema: spec.Schema{
+SchemaProps: spec.SchemaProps{
+Description: "Tree is a map of trees.",
+Type: []string{"object"},
+AdditionalProperties: &spec.SchemaOrBool{
+Allows: true,
+Schema: &spec.Schema{
+SchemaProps: spec.SchemaProps{
+Ref: ref("base/foo.Tree"),
+},
+},
+},
+},
+},
+Dependencies: []string{
+"base/foo.Tree",},
+}
+}
+
+`, funcs)
+	assert.Equal(t, []string{"base/foo.Tree -> base/foo.Tree"}, chains)
+
+	_, funcs, chains = generate("Matrix")
+	assert.Contains(t, funcs, `Type: []string{"array"},
+Items: &spec.SchemaOrArray{
+Schema: &spec.Schema{
+SchemaProps: spec.SchemaProps{
+Type: []string{"array"},
+Items: &spec.SchemaOrArray{
+Schema: &spec.Schema{
+SchemaProps: spec.SchemaProps{
+Ref: ref("base/foo.Matrix"),
+`)
+	assert.Equal(t, []string{"base/foo.Matrix -> base/foo.Matrix"}, chains)
+
+	call, funcs, chains = generate("Node")
+	assert.Equal(t, "\"base/foo.Node\": schema_base_foo_Node(ref),\n", call)
+	assert.Equal(t, 1, strings.Count(funcs, `"Name": {`), "inlined members should be written once")
+	assert.Contains(t, funcs, `"Children": {
+SchemaProps: spec.SchemaProps{
+Type: []string{"object"},
+AdditionalProperties: &spec.SchemaOrBool{
+Allows: true,
+Schema: &spec.Schema{
+SchemaProps: spec.SchemaProps{
+Ref: ref("base/foo.Tree"),
+`)
+	assert.Contains(t, funcs, `"Lists": {
+SchemaProps: spec.SchemaProps{
+Type: []string{"array"},
+Items: &spec.SchemaOrArray{
+Schema: &spec.Schema{
+SchemaProps: spec.SchemaProps{
+Ref: ref("base/foo.List"),
+`)
+	assert.Contains(t, funcs, `Dependencies: []string{
+"base/foo.List","base/foo.Matrix","base/foo.Node","base/foo.Tree",},`)
+	assert.Equal(t, []string{
+		"base/foo.Tree -> base/foo.Tree",
+		"base/foo.List -> base/foo.List",
+		"base/foo.Matrix -> base/foo.Matrix",
+		"base/foo.Node -> base/foo.Base -> base/foo.Node",
+	}, chains)
+
+	call, funcs, chains = generate("Base")
+	assert.Equal(t, 1, strings.Count(funcs, `"Name": {`), "inlined members should be written once")
+	assert.Contains(t, chains, "base/foo.Base -> base/foo.Node -> base/foo.Base")
+	assert.NotEmpty(t, call)
+}
@@ -0,0 +1,180 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generators
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"strings"
+
+	"k8s.io/gengo/types"
+	"k8s.io/klog/v2"
+)
+
+// declPositions records where types and their members are declared.
+// gengo does not keep source positions on its types, so the source of a
+// package is parsed again the first time a position in it is looked up.
+type declPositions struct {
+	universe types.Universe
+	fset     *token.FileSet
+	// packages maps a package path to the positions of its declarations,
+	// keyed by "Type" for types and "Type.Member" for struct members.
+	packages map[string]map[string]token.Position
+}
+
+func newDeclPositions(universe types.Universe) *declPositions {
+	return &declPositions{
+		universe: universe,
+		fset:     token.NewFileSet(),
+		packages: map[string]map[string]token.Position{},
+	}
+}
+
+// typePosition returns the position of the declaration of t, or an invalid
+// position if it is unknown.
+func (d *declPositions) typePosition(t *types.Type) token.Position {
+	return d.load(t.Name.Package)[t.Name.Name]
+}
+
+// memberPosition returns the position of the declaration of member m of
+// struct t, or an invalid position if it is unknown.
+func (d *declPositions) memberPosition(t *types.Type, m *types.Member) token.Position {
+	return d.load(t.Name.Package)[t.Name.Name+"."+m.Name]
+}
+
+func (d *declPositions) load(pkgPath string) map[string]token.Position {
+	if positions, ok := d.packages[pkgPath]; ok {
+		return positions
+	}
+	positions := map[string]token.Position{}
+	d.packages[pkgPath] = positions
+
+	pkg, ok := d.universe[pkgPath]
+	if !ok || pkg.SourcePath == "" {
+		return positions
+	}
+	notTest := func(fi os.FileInfo) bool { return !strings.HasSuffix(fi.Name(), "_test.go") }
+	parsed, err := parser.ParseDir(d.fset, pkg.SourcePath, notTest, 0)
+	if err != nil {
+		klog.V(2).Infof("cannot parse %s for source positions: %v", pkg.SourcePath, err)
+		return positions
+	}
+	for _, p := range parsed {
+		for _, f := range p.Files {
+			for _, decl := range f.Decls {
+				gen, ok := decl.(*ast.GenDecl)
+				if !ok || gen.Tok != token.TYPE {
+					continue
+				}
+				for _, spec := range gen.Specs {
+					d.addTypeSpec(positions, spec.(*ast.TypeSpec))
+				}
+			}
+		}
+	}
+	return positions
+}
+
+func (d *declPositions) addTypeSpec(positions map[string]token.Position, spec *ast.TypeSpec) {
+	name := spec.Name.Name
+	positions[name] = d.fset.Position(spec.Name.Pos())
+	st, ok := spec.Type.(*ast.StructType)
+	if !ok {
+		return
+	}
+	for _, field := range st.Fields.List {
+		if len(field.Names) == 0 {
+			if embedded := embeddedName(field.Type); embedded != "" {
+				positions[name+"."+embedded] = d.fset.Position(field.Pos())
+			}
+			continue
+		}
+		for _, n := range field.Names {
+			positions[name+"."+n.Name] = d.fset.Position(n.Pos())
+		}
+	}
+}
+
+// embeddedName returns the member name of an embedded field of type expr.
+func embeddedName(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return e.Name
+	case *ast.SelectorExpr:
+		return e.Sel.Name
+	case *ast.StarExpr:
+		return embeddedName(e.X)
+	}
+	return ""
+}
+
+// declError is an error about a type or one of its members, located at the
+// declaration it is about.
+type declError struct {
+	Pos  token.Position
+	Decl string
+	Err  error
+}
+
+func (e *declError) Error() string {
+	if e.Pos.IsValid() {
+		return fmt.Sprintf("%v: %s: %v", e.Pos, e.Decl, e.Err)
+	}
+	return fmt.Sprintf("%s: %v", e.Decl, e.Err)
+}
+
+func (e *declError) Unwrap() error {
+	return e.Err
+}
+
+// typeError locates err at the declaration of t, unless it is already
+// located at a more precise declaration. Without positions, err is
+// returned as is.
+func (d *declPositions) typeError(t *types.Type, err error) error {
+	var located *declError
+	if d == nil || errors.As(err, &located) {
+		return err
+	}
+	return &declError{Pos: d.typePosition(t), Decl: t.Name.String(), Err: err}
+}
+
+// memberError locates err at the declaration of member m of struct t.
+func (d *declPositions) memberError(t *types.Type, m *types.Member, err error) error {
+	var located *declError
+	if d == nil || errors.As(err, &located) {
+		return err
+	}
+	return &declError{Pos: d.memberPosition(t, m), Decl: t.Name.String() + "." + m.Name, Err: err}
+}
+
+// errorList is the result of a generation run that found several errors.
+type errorList []error
+
+func (l errorList) Error() string {
+	if len(l) == 1 {
+		return l[0].Error()
+	}
+	msgs := make([]string, 0, len(l))
+	for _, err := range l {
+		msgs = append(msgs, err.Error())
+	}
+	return fmt.Sprintf("%d errors:\n%s", len(l), strings.Join(msgs, "\n"))
+}
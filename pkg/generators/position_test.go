@@ -0,0 +1,126 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generators
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/gengo/generator"
+	"k8s.io/gengo/namer"
+	"k8s.io/gengo/types"
+)
+
+const positionTestSource = `package foo
+
+// Good is fine.
+type Good struct {
+	Name string
+}
+
+// BadKey has a map with non-string keys.
+type BadKey struct {
+	Good
+	Counts map[int]string
+}
+
+// BadElem has a map with unsupported elements.
+type BadElem struct {
+	Fn map[string]func()
+}
+`
+
+func constructWithSource(t *testing.T) (*generator.Context, types.Universe) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "foo.go"), []byte(positionTestSource), 0644))
+
+	builder, _, _ := construct(t, map[string]string{"base/foo/foo.go": positionTestSource}, namer.NewRawNamer("o", nil))
+	c, err := generator.NewContext(builder, namer.NameSystems{
+		"raw": namer.NewRawNamer("", nil),
+		"private": &namer.NameStrategy{
+			Join: func(pre string, in []string, post string) string {
+				return strings.Join(in, "_")
+			},
+		},
+	}, "raw")
+	require.NoError(t, err)
+	c.Universe.Package("base/foo").SourcePath = dir
+	return c, c.Universe
+}
+
+func TestDeclPositions(t *testing.T) {
+	_, universe := constructWithSource(t)
+	positions := newDeclPositions(universe)
+
+	badKey := universe.Type(types.Name{Package: "base/foo", Name: "BadKey"})
+	pos := positions.typePosition(badKey)
+	assert.Equal(t, "foo.go", filepath.Base(pos.Filename))
+	assert.Equal(t, 9, pos.Line)
+
+	pos = positions.memberPosition(badKey, &badKey.Members[0])
+	assert.Equal(t, 10, pos.Line, "embedded member")
+	pos = positions.memberPosition(badKey, &badKey.Members[1])
+	assert.Equal(t, 11, pos.Line)
+
+	err := positions.memberError(badKey, &badKey.Members[1], errors.New("boom"))
+	assert.Regexp(t, `foo\.go:11:2: base/foo\.BadKey\.Counts: boom$`, err.Error())
+	assert.Equal(t, err, positions.typeError(badKey, err), "already located errors are kept")
+
+	unknown := &types.Type{Name: types.Name{Package: "base/other", Name: "X"}}
+	assert.Equal(t, "base/other.X: boom", positions.typeError(unknown, errors.New("boom")).Error())
+}
+
+func TestGenerateTypeMaxErrors(t *testing.T) {
+	for _, tc := range []struct {
+		maxErrors int
+		want      int
+	}{
+		{maxErrors: 1, want: 1},
+		{maxErrors: 0, want: 2},
+		{maxErrors: 5, want: 2},
+	} {
+		c, universe := constructWithSource(t)
+		g := newOpenAPIGen("openapi_generated", "base/output", tc.maxErrors, false, false, false)
+		w := &bytes.Buffer{}
+		require.NoError(t, g.Init(c, w))
+
+		var err error
+		for _, name := range []string{"Good", "BadKey", "BadElem"} {
+			if err = g.GenerateType(c, universe.Type(types.Name{Package: "base/foo", Name: name}), w); err != nil {
+				break
+			}
+		}
+		if err == nil {
+			err = g.Finalize(c, w)
+		}
+		var errs errorList
+		require.True(t, errors.As(err, &errs), "maxErrors=%d: %v", tc.maxErrors, err)
+		assert.Len(t, errs, tc.want, "maxErrors=%d", tc.maxErrors)
+		assert.Contains(t, errs[0].Error(), "foo.go:11:2: base/foo.BadKey.Counts: ")
+		if len(errs) > 1 {
+			assert.Contains(t, err.Error(), "2 errors:")
+			assert.Contains(t, errs[1].Error(), "foo.go:16:2: base/foo.BadElem.Fn: ")
+		}
+	}
+}
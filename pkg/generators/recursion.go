@@ -0,0 +1,129 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generators
+
+import (
+	"strings"
+
+	"k8s.io/gengo/types"
+)
+
+// recursionTracker follows the types written in place while generating a
+// definition: inlined structs, slices, arrays and maps. Structs used as
+// members are referenced rather than written in place, but a named slice or
+// map may contain itself, and a struct may inline a struct inlining it. Such
+// types recur while they are being written, and must not be written again.
+type recursionTracker struct {
+	stack []*types.Type
+	// chains holds the recursive chains found, from the recurring type to
+	// itself, e.g. "foo.Tree -> foo.Tree".
+	chains []string
+}
+
+// enter returns true and pushes t if t can be written in place. If t is
+// already being written, its recursive chain is recorded and false is
+// returned: t must be referenced instead, or skipped for an inlined struct
+// whose members are already written.
+func (r *recursionTracker) enter(t *types.Type) bool {
+	for i, s := range r.stack {
+		if s != t {
+			continue
+		}
+		chain := make([]string, 0, len(r.stack)-i+1)
+		for _, c := range r.stack[i:] {
+			chain = append(chain, c.Name.String())
+		}
+		r.chains = append(r.chains, strings.Join(append(chain, t.Name.String()), " -> "))
+		return false
+	}
+	r.stack = append(r.stack, t)
+	return true
+}
+
+// leave pops the type pushed by the last successful enter.
+func (r *recursionTracker) leave() {
+	r.stack = r.stack[:len(r.stack)-1]
+}
+
+// namedContainer returns the named type of t, a slice, array or map type
+// that may be a pointer, or nil if it is not named. gengo represents named
+// slice, array and map types as aliases.
+func namedContainer(t *types.Type) *types.Type {
+	for t.Kind == types.Pointer {
+		t = t.Elem
+	}
+	if t.Kind != types.Alias {
+		return nil
+	}
+	return t
+}
+
+// isRecursiveContainer returns true if t is a named slice, array or map type
+// whose elements contain t, through other slices, arrays, maps, pointers and
+// named types. These types get a definition, so that they can be referenced
+// where they recur.
+func isRecursiveContainer(t *types.Type) bool {
+	if t.Kind != types.Alias {
+		return false
+	}
+	switch resolveAliasAndPtrType(t).Kind {
+	case types.Slice, types.Array, types.Map:
+	default:
+		return false
+	}
+	seen := map[*types.Type]bool{}
+	elem := resolveAliasAndPtrType(t).Elem
+	for !seen[elem] {
+		seen[elem] = true
+		switch elem.Kind {
+		case types.Pointer:
+			elem = elem.Elem
+		case types.Alias:
+			if elem == t {
+				return true
+			}
+			elem = elem.Underlying
+		case types.Slice, types.Array, types.Map:
+			elem = elem.Elem
+		default:
+			return false
+		}
+	}
+	return false
+}
+
+// generateRecursiveContainer writes the definition of a recursive container
+// type, see isRecursiveContainer.
+func (g openAPITypeWriter) generateRecursiveContainer(t *types.Type) error {
+	args := argsFromType(t)
+	g.Do("func "+nameTmpl+"(ref $.ReferenceCallback|raw$) $.OpenAPIDefinition|raw$ {\n", args)
+	g.Do("return $.OpenAPIDefinition|raw${\nSchema: spec.Schema{\nSchemaProps: spec.SchemaProps{\n", args)
+	g.generateDescription(t.CommentLines)
+	var err error
+	if resolveAliasAndPtrType(t).Kind == types.Map {
+		err = g.generateMapProperty(t)
+	} else {
+		err = g.generateSliceProperty(t)
+	}
+	if err != nil {
+		return err
+	}
+	g.Do("},\n},\n", nil)
+	g.generateDependencies()
+	g.Do("}\n}\n\n", nil)
+	return g.Error()
+}
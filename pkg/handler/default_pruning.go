@@ -204,5 +204,32 @@ func PruneDefaultsSchema(schema *spec.Schema) *spec.Schema {
 		}
 	}
 
+	prefixItemsCloned := false
+	for i := range schema.PrefixItems {
+		if s := PruneDefaultsSchema(&schema.PrefixItems[i]); s != &schema.PrefixItems[i] {
+			if !prefixItemsCloned {
+				prefixItemsCloned = true
+				clone()
+				schema.PrefixItems = make([]spec.Schema, len(orig.PrefixItems))
+				copy(schema.PrefixItems, orig.PrefixItems)
+			}
+			schema.PrefixItems[i] = *s
+		}
+	}
+
+	if schema.UnevaluatedItems != nil && schema.UnevaluatedItems.Schema != nil {
+		if s := PruneDefaultsSchema(schema.UnevaluatedItems.Schema); s != schema.UnevaluatedItems.Schema {
+			clone()
+			schema.UnevaluatedItems = &spec.SchemaOrBool{Schema: s, Allows: schema.UnevaluatedItems.Allows}
+		}
+	}
+
+	if schema.UnevaluatedProperties != nil && schema.UnevaluatedProperties.Schema != nil {
+		if s := PruneDefaultsSchema(schema.UnevaluatedProperties.Schema); s != schema.UnevaluatedProperties.Schema {
+			clone()
+			schema.UnevaluatedProperties = &spec.SchemaOrBool{Schema: s, Allows: schema.UnevaluatedProperties.Allows}
+		}
+	}
+
 	return schema
 }
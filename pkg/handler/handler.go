@@ -18,12 +18,13 @@ package handler
 
 import (
 	"bytes"
+	"context"
 	"crypto/sha512"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"strconv"
-	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/NYTimes/gziphandler"
@@ -36,6 +37,7 @@ import (
 	"k8s.io/kube-openapi/pkg/common"
 	"k8s.io/kube-openapi/pkg/common/restfuladapter"
 	"k8s.io/kube-openapi/pkg/internal/handler"
+	"k8s.io/kube-openapi/pkg/schemamutation"
 	"k8s.io/kube-openapi/pkg/validation/spec"
 )
 
@@ -47,16 +49,13 @@ func computeETag(data []byte) string {
 }
 
 // OpenAPIService is the service responsible for serving OpenAPI spec. It has
-// the ability to safely change the spec while serving it.
+// the ability to safely change the spec while serving it. Every request is
+// served from a single version of the spec, even if it is updated meanwhile.
 type OpenAPIService struct {
-	// rwMutex protects All members of this service.
-	rwMutex sync.RWMutex
-
-	lastModified time.Time
-
-	jsonCache  handler.HandlerCache
-	protoCache handler.HandlerCache
-	etagCache  handler.HandlerCache
+	specs     handler.SnapshotHolder
+	lifecycle handler.Lifecycle
+	// digestHeaders is set to 1 to send digest headers with the spec.
+	digestHeaders int32
 }
 
 // NewOpenAPIService builds an OpenAPIService starting with the given spec.
@@ -68,59 +67,109 @@ func NewOpenAPIService(spec *spec.Swagger) (*OpenAPIService, error) {
 	return o, nil
 }
 
-func (o *OpenAPIService) getSwaggerBytes() ([]byte, string, time.Time, error) {
-	o.rwMutex.RLock()
-	defer o.rwMutex.RUnlock()
-	specBytes, err := o.jsonCache.Get()
+// snapshotGetter returns one serialization of a spec snapshot, with its
+// ETag and modification time.
+type snapshotGetter func(s *handler.Snapshot) ([]byte, string, time.Time, error)
+
+func getSwaggerBytes(s *handler.Snapshot) ([]byte, string, time.Time, error) {
+	specBytes, err := s.JSON.Get()
 	if err != nil {
 		return nil, "", time.Time{}, err
 	}
-	etagBytes, err := o.etagCache.Get()
+	etagBytes, err := s.ETag.Get()
 	if err != nil {
 		return nil, "", time.Time{}, err
 	}
-	return specBytes, string(etagBytes), o.lastModified, nil
+	return specBytes, string(etagBytes), s.LastModified, nil
+}
+
+// digestGetter returns the SHA-256 sum of one serialization of a spec
+// snapshot.
+type digestGetter func(s *handler.Snapshot) ([]byte, error)
+
+func getSwaggerDigest(s *handler.Snapshot) ([]byte, error) {
+	return s.JSONDigest.Get()
+}
+
+func getSwaggerPbDigest(s *handler.Snapshot) ([]byte, error) {
+	return s.ProtoDigest.Get()
 }
 
-func (o *OpenAPIService) getSwaggerPbBytes() ([]byte, string, time.Time, error) {
-	o.rwMutex.RLock()
-	defer o.rwMutex.RUnlock()
-	specPb, err := o.protoCache.Get()
+func getSwaggerPbBytes(s *handler.Snapshot) ([]byte, string, time.Time, error) {
+	specPb, err := s.Proto.Get()
 	if err != nil {
 		return nil, "", time.Time{}, err
 	}
-	etagBytes, err := o.etagCache.Get()
+	etagBytes, err := s.ETag.Get()
 	if err != nil {
 		return nil, "", time.Time{}, err
 	}
-	return specPb, string(etagBytes), o.lastModified, nil
+	return specPb, string(etagBytes), s.LastModified, nil
 }
 
+// UpdateSpec replaces the served spec. Requests already in flight finish
+// serving the previous spec.
 func (o *OpenAPIService) UpdateSpec(openapiSpec *spec.Swagger) (err error) {
-	o.rwMutex.Lock()
-	defer o.rwMutex.Unlock()
-	o.jsonCache = o.jsonCache.New(func() ([]byte, error) {
-		return json.Marshal(openapiSpec)
-	})
-	o.protoCache = o.protoCache.New(func() ([]byte, error) {
-		json, err := o.jsonCache.Get()
-		if err != nil {
-			return nil, err
-		}
-		return ToProtoBinary(json)
-	})
-	o.etagCache = o.etagCache.New(func() ([]byte, error) {
-		json, err := o.jsonCache.Get()
-		if err != nil {
-			return nil, err
-		}
-		return []byte(computeETag(json)), nil
-	})
-	o.lastModified = time.Now()
-
+	o.specs.Update(newSpecSnapshot(openapiSpec))
 	return nil
 }
 
+// SwapSpec replaces the served spec like UpdateSpec, but acts as a fence:
+// it only returns once every request served from a previous spec has
+// finished, so no response computed from it is written afterwards. If ctx
+// is done first, ctx.Err() is returned; the new spec is served regardless.
+func (o *OpenAPIService) SwapSpec(ctx context.Context, openapiSpec *spec.Swagger) error {
+	return o.specs.Swap(ctx, newSpecSnapshot(openapiSpec))
+}
+
+func newSpecSnapshot(openapiSpec *spec.Swagger) func(*handler.Snapshot) *handler.Snapshot {
+	return func(prev *handler.Snapshot) *handler.Snapshot {
+		return handler.NewSnapshot(prev, func() ([]byte, error) {
+			return json.Marshal(schemamutation.StripV3OnlyFields(openapiSpec))
+		}, ToProtoBinary, computeETag)
+	}
+}
+
+// SetDigestHeaders makes the service send the Repr-Digest (RFC 9530) and
+// Digest (RFC 3230) headers with the spec, so that clients can detect a
+// corrupted transfer. Digests are computed once per spec and serialization.
+// Compressed responses, which are checksummed by gzip already, carry no
+// digest.
+func (o *OpenAPIService) SetDigestHeaders(enabled bool) {
+	var v int32
+	if enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&o.digestHeaders, v)
+}
+
+// setDigestHeaders sets the digest headers of a response served from s, if
+// enabled.
+func (o *OpenAPIService) setDigestHeaders(w http.ResponseWriter, s *handler.Snapshot, getDigest digestGetter) {
+	if atomic.LoadInt32(&o.digestHeaders) == 0 {
+		return
+	}
+	if _, compressed := w.(*gziphandler.GzipResponseWriter); compressed {
+		return
+	}
+	if sum, err := getDigest(s); err == nil {
+		handler.SetDigestHeaders(w.Header(), sum)
+	}
+}
+
+// Start makes the service serve requests again after Shutdown. A new
+// service serves requests without calling Start.
+func (o *OpenAPIService) Start() {
+	o.lifecycle.Start()
+}
+
+// Shutdown makes the service answer new requests with 503 Service
+// Unavailable, and waits for the requests in flight to finish or for ctx
+// to be done.
+func (o *OpenAPIService) Shutdown(ctx context.Context) error {
+	return o.lifecycle.Shutdown(ctx)
+}
+
 func ToProtoBinary(json []byte) ([]byte, error) {
 	document, err := openapi_v2.ParseDocument(json)
 	if err != nil {
@@ -145,14 +194,27 @@ func (o *OpenAPIService) RegisterOpenAPIVersionedService(servePath string, handl
 	accepted := []struct {
 		Type           string
 		SubType        string
-		GetDataAndETag func() ([]byte, string, time.Time, error)
+		GetDataAndETag snapshotGetter
+		GetDigest      digestGetter
 	}{
-		{"application", "json", o.getSwaggerBytes},
-		{"application", "com.github.proto-openapi.spec.v2@v1.0+protobuf", o.getSwaggerPbBytes},
+		{"application", "json", getSwaggerBytes, getSwaggerDigest},
+		{"application", "com.github.proto-openapi.spec.v2@v1.0+protobuf", getSwaggerPbBytes, getSwaggerPbDigest},
 	}
 
 	handler.Handle(servePath, gziphandler.GzipHandler(http.HandlerFunc(
 		func(w http.ResponseWriter, r *http.Request) {
+			if !o.lifecycle.Enter() {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			defer o.lifecycle.Exit()
+			snapshot, release := o.specs.Acquire()
+			defer release()
+			if snapshot == nil {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+
 			decipherableFormats := r.Header.Get("Accept")
 			if decipherableFormats == "" {
 				decipherableFormats = "*/*"
@@ -169,7 +231,7 @@ func (o *OpenAPIService) RegisterOpenAPIVersionedService(servePath string, handl
 					}
 
 					// serve the first matching media type in the sorted clause list
-					data, etag, lastModified, err := accepts.GetDataAndETag()
+					data, etag, lastModified, err := accepts.GetDataAndETag(snapshot)
 					if err != nil {
 						klog.Errorf("Error in OpenAPI handler: %s", err)
 						// only return a 503 if we have no older cache data to serve
@@ -180,6 +242,9 @@ func (o *OpenAPIService) RegisterOpenAPIVersionedService(servePath string, handl
 					}
 					// ETag must be enclosed in double quotes: https://developer.mozilla.org/en-US/docs/Web/HTTP/Headers/ETag
 					w.Header().Set("Etag", strconv.Quote(etag))
+					if err == nil {
+						o.setDigestHeaders(w, snapshot, accepts.GetDigest)
+					}
 					// ServeContent will take care of caching using eTag.
 					http.ServeContent(w, r, servePath, lastModified, bytes.NewReader(data))
 					return
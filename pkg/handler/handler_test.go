@@ -1,6 +1,9 @@
 package handler
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
 	json "encoding/json"
 	"io"
 	"net/http"
@@ -106,3 +109,173 @@ func TestToProtoBinary(t *testing.T) {
 	}
 	// TODO: add some kind of roundtrip test here
 }
+
+func TestV3OnlyFieldsNotServed(t *testing.T) {
+	// fields that only OpenAPI v3 defines, which openapi-gen writes into the
+	// definitions shared by v2 and v3
+	properties := map[string]spec.Schema{
+		"deprecated": {SchemaProps: spec.SchemaProps{Type: []string{"string"}, Deprecated: true}},
+		"writeOnly":  *spec.StringProperty().AsWriteOnly(),
+		"prefixItems": {SchemaProps: spec.SchemaProps{
+			Type:        []string{"array"},
+			PrefixItems: []spec.Schema{*spec.StringProperty()},
+		}},
+		"unevaluatedItems": {SchemaProps: spec.SchemaProps{
+			Type:             []string{"array"},
+			UnevaluatedItems: &spec.SchemaOrBool{Allows: false},
+		}},
+		"unevaluatedProperties": {SchemaProps: spec.SchemaProps{
+			Type:                  []string{"object"},
+			UnevaluatedProperties: &spec.SchemaOrBool{Allows: false},
+		}},
+	}
+	served := &spec.Swagger{SwaggerProps: spec.SwaggerProps{
+		Swagger: "2.0",
+		Info:    &spec.Info{InfoProps: spec.InfoProps{Title: "test", Version: "v1"}},
+		Definitions: spec.Definitions{"T": spec.Schema{SchemaProps: spec.SchemaProps{
+			Type:       []string{"object"},
+			Properties: properties,
+		}}},
+	}}
+	before, err := json.Marshal(served)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	o, err := NewOpenAPIService(served)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mux := http.NewServeMux()
+	if err := o.RegisterOpenAPIVersionedService("/openapi/v2", mux); err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/openapi/v2", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected the JSON spec to be served, got %d: %s", w.Code, w.Body.String())
+	}
+	got := &spec.Swagger{}
+	if err := json.Unmarshal(w.Body.Bytes(), got); err != nil {
+		t.Fatal(err)
+	}
+	for name, schema := range got.Definitions["T"].Properties {
+		if schema.Deprecated || schema.WriteOnly || schema.PrefixItems != nil || schema.UnevaluatedItems != nil || schema.UnevaluatedProperties != nil {
+			t.Errorf("expected v3-only fields to be stripped from %s, got %#v", name, schema)
+		}
+	}
+	if len(got.Definitions["T"].Properties) != len(properties) {
+		t.Errorf("expected %d properties, got %v", len(properties), got.Definitions["T"].Properties)
+	}
+
+	req := httptest.NewRequest("GET", "/openapi/v2", nil)
+	req.Header.Set("Accept", "application/com.github.proto-openapi.spec.v2@v1.0+protobuf")
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("expected the protobuf spec to be served, got %d: %s", w.Code, w.Body.String())
+	}
+
+	if after, err := json.Marshal(served); err != nil || string(after) != string(before) {
+		t.Errorf("expected the served spec not to be modified, got %s, %v", after, err)
+	}
+}
+
+func TestSwapSpecAndShutdown(t *testing.T) {
+	mux := http.NewServeMux()
+	o, err := NewOpenAPIService(&spec.Swagger{SwaggerProps: spec.SwaggerProps{Swagger: "2.0"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := o.RegisterOpenAPIVersionedService("/openapi/v2", mux); err != nil {
+		t.Fatal(err)
+	}
+	get := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/openapi/v2", nil)
+		req.Header.Set("Accept", "application/json")
+		mux.ServeHTTP(w, req)
+		return w
+	}
+
+	swapped := &spec.Swagger{SwaggerProps: spec.SwaggerProps{Swagger: "2.0", Info: &spec.Info{InfoProps: spec.InfoProps{Title: "swapped"}}}}
+	if err := o.SwapSpec(context.Background(), swapped); err != nil {
+		t.Fatal(err)
+	}
+	expected, err := json.Marshal(swapped)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w := get(); w.Code != http.StatusOK || !reflect.DeepEqual(w.Body.Bytes(), expected) {
+		t.Errorf("expected swapped spec, got %d: %s", w.Code, w.Body.String())
+	}
+
+	if err := o.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if w := get(); w.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected %d after Shutdown, got %d", http.StatusServiceUnavailable, w.Code)
+	}
+	o.Start()
+	if w := get(); w.Code != http.StatusOK {
+		t.Errorf("expected %d after Start, got %d", http.StatusOK, w.Code)
+	}
+}
+
+func TestDigestHeaders(t *testing.T) {
+	var s spec.Swagger
+	if err := s.UnmarshalJSON(returnedSwagger); err != nil {
+		t.Fatal(err)
+	}
+	mux := http.NewServeMux()
+	o, err := NewOpenAPIService(&s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := o.RegisterOpenAPIVersionedService("/openapi/v2", mux); err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	get := func(accept, acceptEncoding string) (*http.Response, []byte) {
+		req, err := http.NewRequest("GET", server.URL+"/openapi/v2", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Accept", accept)
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+		resp, err := server.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp, body
+	}
+
+	if resp, _ := get("application/json", "identity"); resp.Header.Get("Repr-Digest") != "" {
+		t.Errorf("expected no digest unless enabled, got %q", resp.Header.Get("Repr-Digest"))
+	}
+
+	o.SetDigestHeaders(true)
+	for _, accept := range []string{"application/json", "application/com.github.proto-openapi.spec.v2@v1.0+protobuf"} {
+		resp, body := get(accept, "identity")
+		sum := sha256.Sum256(body)
+		encoded := base64.StdEncoding.EncodeToString(sum[:])
+		if got, want := resp.Header.Get("Repr-Digest"), "sha-256=:"+encoded+":"; got != want {
+			t.Errorf("Accept: %v: expected Repr-Digest %q, got %q", accept, want, got)
+		}
+		if got, want := resp.Header.Get("Digest"), "SHA-256="+encoded; got != want {
+			t.Errorf("Accept: %v: expected Digest %q, got %q", accept, want, got)
+		}
+	}
+
+	if resp, _ := get("application/json", "gzip"); resp.Header.Get("Repr-Digest") != "" || resp.Header.Get("Digest") != "" {
+		t.Errorf("expected no digest on compressed responses, got %v", resp.Header)
+	}
+}
@@ -0,0 +1,257 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler3
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"k8s.io/kube-openapi/pkg/spec3"
+)
+
+// ClientStorage stores the documents fetched by a Client, with their ETags.
+// Implementations must be safe for concurrent use.
+type ClientStorage interface {
+	// Get returns the document of a group-version, and false if there is none.
+	Get(groupVersion string) (data []byte, etag string, ok bool)
+	// Put stores the document of a group-version.
+	Put(groupVersion string, data []byte, etag string) error
+	// Delete removes the document of a group-version.
+	Delete(groupVersion string) error
+	// List returns the group-versions with a document.
+	List() ([]string, error)
+}
+
+// NewMemoryStorage returns a ClientStorage keeping the documents in memory.
+func NewMemoryStorage() ClientStorage {
+	return &memoryStorage{docs: map[string]storedDocument{}}
+}
+
+type storedDocument struct {
+	data []byte
+	etag string
+}
+
+type memoryStorage struct {
+	mu   sync.RWMutex
+	docs map[string]storedDocument
+}
+
+func (s *memoryStorage) Get(groupVersion string) ([]byte, string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	d, ok := s.docs[groupVersion]
+	return d.data, d.etag, ok
+}
+
+func (s *memoryStorage) Put(groupVersion string, data []byte, etag string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.docs[groupVersion] = storedDocument{data: data, etag: etag}
+	return nil
+}
+
+func (s *memoryStorage) Delete(groupVersion string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.docs, groupVersion)
+	return nil
+}
+
+func (s *memoryStorage) List() ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	gvs := make([]string, 0, len(s.docs))
+	for gv := range s.docs {
+		gvs = append(gvs, gv)
+	}
+	sort.Strings(gvs)
+	return gvs, nil
+}
+
+// Client fetches the OpenAPI v3 documents served by an OpenAPIService, e.g.
+// the ones of the servers behind an aggregator, and keeps them in a storage.
+// A document is only fetched again once its hash in the discovery document
+// changes, and then with its ETag so that an unchanged document is not sent
+// again.
+type Client struct {
+	// Client performs the requests. http.DefaultClient is used if nil.
+	Client *http.Client
+	// Server is the base URL of the server, e.g. "https://10.0.0.1:443".
+	Server string
+	// Path is the path of the discovery document, by default /openapi/v3.
+	Path string
+	// Storage keeps the fetched documents. It must be set before the first
+	// call to Update.
+	Storage ClientStorage
+}
+
+// NewClient returns a client of the server at the given base URL, keeping
+// the fetched documents in memory.
+func NewClient(server string) *Client {
+	return &Client{Server: server, Storage: NewMemoryStorage()}
+}
+
+// Update fetches the discovery document of the server, then the documents
+// of the group-versions that are new or whose hash changed. Documents of
+// the group-versions no longer served are deleted from the storage.
+//
+// It returns the sorted group-versions whose document was added, changed or
+// deleted. If some documents cannot be fetched, the previous ones are kept
+// and an error is returned along with the other changes.
+func (c *Client) Update(ctx context.Context) ([]string, error) {
+	if c.Storage == nil {
+		return nil, fmt.Errorf("no storage for the fetched documents")
+	}
+	discoveryPath := c.Path
+	if discoveryPath == "" {
+		discoveryPath = "/openapi/v3"
+	}
+	data, _, _, err := c.fetch(ctx, discoveryPath, "")
+	if err != nil {
+		return nil, err
+	}
+	discovery := &OpenAPIV3Discovery{}
+	if err := json.Unmarshal(data, discovery); err != nil {
+		return nil, fmt.Errorf("invalid discovery document: %v", err)
+	}
+
+	var changed, errs []string
+	stored, err := c.Storage.List()
+	if err != nil {
+		return nil, err
+	}
+	for _, gv := range stored {
+		if _, ok := discovery.Paths[gv]; ok {
+			continue
+		}
+		if err := c.Storage.Delete(gv); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", gv, err))
+			continue
+		}
+		changed = append(changed, gv)
+	}
+
+	for gv, path := range discovery.Paths {
+		updated, err := c.updateGroupVersion(ctx, gv, path.ServerRelativeURL)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", gv, err))
+			continue
+		}
+		if updated {
+			changed = append(changed, gv)
+		}
+	}
+	sort.Strings(changed)
+	if len(errs) > 0 {
+		sort.Strings(errs)
+		return changed, fmt.Errorf("failed to update documents: %s", strings.Join(errs, "; "))
+	}
+	return changed, nil
+}
+
+// updateGroupVersion fetches the document of gv served at u unless the
+// stored one has the hash of u. It returns true if the document changed.
+func (c *Client) updateGroupVersion(ctx context.Context, gv, u string) (bool, error) {
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return false, fmt.Errorf("invalid URL %q: %v", u, err)
+	}
+	hash := parsed.Query().Get("hash")
+	_, etag, ok := c.Storage.Get(gv)
+	if ok && hash != "" && etag == hash {
+		return false, nil
+	}
+	if !ok {
+		etag = ""
+	}
+
+	data, newETag, notModified, err := c.fetch(ctx, u, etag)
+	if err != nil || notModified {
+		return false, err
+	}
+	if newETag == "" {
+		newETag = hash
+	}
+	if err := c.Storage.Put(gv, data, newETag); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// GroupVersion returns the stored document of a group-version, and false if
+// there is none. Every call parses a new copy of the document.
+func (c *Client) GroupVersion(groupVersion string) (*spec3.OpenAPI, bool, error) {
+	if c.Storage == nil {
+		return nil, false, nil
+	}
+	data, _, ok := c.Storage.Get(groupVersion)
+	if !ok {
+		return nil, false, nil
+	}
+	o := &spec3.OpenAPI{}
+	if err := json.Unmarshal(data, o); err != nil {
+		return nil, true, fmt.Errorf("invalid document for %s: %v", groupVersion, err)
+	}
+	return o, true, nil
+}
+
+// fetch gets the JSON document at u, relative to the server. If etag is not
+// empty, the request is conditional and notModified is true if the server
+// answered it with 304 Not Modified.
+func (c *Client) fetch(ctx context.Context, u, etag string) (data []byte, newETag string, notModified bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(c.Server, "/")+u, nil)
+	if err != nil {
+		return nil, "", false, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if etag != "" {
+		req.Header.Set("If-None-Match", strconv.Quote(etag))
+	}
+	client := c.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, "", false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotModified && etag != "" {
+		return nil, etag, true, nil
+	}
+	data, err = io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", false, fmt.Errorf("reading %s: %v", u, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", false, fmt.Errorf("fetching %s: status %d", u, resp.StatusCode)
+	}
+	newETag = resp.Header.Get("Etag")
+	if unquoted, err := strconv.Unquote(newETag); err == nil {
+		newETag = unquoted
+	}
+	return data, newETag, false, nil
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler3
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+
+	"k8s.io/kube-openapi/pkg/spec3"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+func clientTestSpec(title string) *spec3.OpenAPI {
+	return &spec3.OpenAPI{
+		Version: "3.0.0",
+		Info:    &spec.Info{InfoProps: spec.InfoProps{Title: title, Version: "v1"}},
+	}
+}
+
+func TestClient(t *testing.T) {
+	o, err := NewOpenAPIService(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fetches int32
+	mux := http.NewServeMux()
+	mux.Handle("/openapi/v3", http.HandlerFunc(o.HandleDiscovery))
+	mux.Handle("/openapi/v3/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&fetches, 1)
+		o.HandleGroupVersion(w, r)
+	}))
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	o.UpdateGroupVersion("apis/apps/v1", clientTestSpec("apps"))
+	o.UpdateGroupVersion("apis/batch/v1", clientTestSpec("batch"))
+
+	c := NewClient(server.URL)
+	c.Client = server.Client()
+	update := func(expected []string, expectedFetches int32) {
+		t.Helper()
+		atomic.StoreInt32(&fetches, 0)
+		changed, err := c.Update(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(changed) != 0 || len(expected) != 0 {
+			if !reflect.DeepEqual(expected, changed) {
+				t.Errorf("expected changes %v, got %v", expected, changed)
+			}
+		}
+		if got := atomic.LoadInt32(&fetches); got != expectedFetches {
+			t.Errorf("expected %d documents fetched, got %d", expectedFetches, got)
+		}
+	}
+
+	update([]string{"apis/apps/v1", "apis/batch/v1"}, 2)
+	doc, ok, err := c.GroupVersion("apis/apps/v1")
+	if err != nil || !ok {
+		t.Fatalf("expected apis/apps/v1, got %v, %v", ok, err)
+	}
+	if doc.Info.Title != "apps" {
+		t.Errorf("expected apps document, got %q", doc.Info.Title)
+	}
+
+	// nothing changed, nothing is fetched
+	update(nil, 0)
+
+	o.UpdateGroupVersion("apis/apps/v1", clientTestSpec("apps v2"))
+	update([]string{"apis/apps/v1"}, 1)
+	if doc, _, _ := c.GroupVersion("apis/apps/v1"); doc.Info.Title != "apps v2" {
+		t.Errorf("expected updated apps document, got %q", doc.Info.Title)
+	}
+
+	o.DeleteGroupVersion("apis/batch/v1")
+	update([]string{"apis/batch/v1"}, 0)
+	if _, ok, err := c.GroupVersion("apis/batch/v1"); ok || err != nil {
+		t.Errorf("expected apis/batch/v1 to be deleted, got %v, %v", ok, err)
+	}
+
+	// a document stored with a stale ETag is fetched again
+	data, etag, _ := c.Storage.Get("apis/apps/v1")
+	c.Storage.Put("apis/apps/v1", data, etag+"-stale")
+	atomic.StoreInt32(&fetches, 0)
+	changed, err := c.Update(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(changed) != 1 {
+		t.Errorf("expected the stale document to be fetched again, got changes %v", changed)
+	}
+	if _, got, _ := c.Storage.Get("apis/apps/v1"); got != etag {
+		t.Errorf("expected ETag %q, got %q", etag, got)
+	}
+}
+
+func TestClientNotModified(t *testing.T) {
+	var conditional int32
+	mux := http.NewServeMux()
+	mux.Handle("/openapi/v3", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"paths":{"apis/apps/v1":{"serverRelativeURL":"/openapi/v3/apis/apps/v1?hash=NEW"}}}`))
+	}))
+	mux.Handle("/openapi/v3/apis/apps/v1", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"OLD"` {
+			atomic.AddInt32(&conditional, 1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	c := NewClient(server.URL)
+	c.Client = server.Client()
+	c.Storage.Put("apis/apps/v1", []byte(`{"openapi":"3.0.0"}`), "OLD")
+	changed, err := c.Update(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(changed) != 0 || atomic.LoadInt32(&conditional) != 1 {
+		t.Errorf("expected one conditional request and no changes, got %d and %v", conditional, changed)
+	}
+
+	c.Storage.Delete("apis/apps/v1")
+	if _, err := c.Update(context.Background()); err == nil {
+		t.Errorf("expected an error for the failed fetch")
+	}
+}
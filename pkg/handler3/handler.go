@@ -18,16 +18,18 @@ package handler3
 
 import (
 	"bytes"
-	"crypto/sha512"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
-	"net/url"
-	"path"
 	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/golang/protobuf/proto"
@@ -44,51 +46,76 @@ const (
 	subTypeJSON     = "json"
 )
 
+const (
+	// HashesHeader is set on discovery responses to a comma separated list
+	// of "<group-version>=<hash>" pairs, sorted by group-version. Clients can
+	// compare it against the hashes of their cached documents without
+	// parsing the discovery body or issuing a conditional request per document.
+	// It is omitted if it would exceed maxHashesHeaderSize, in which case
+	// clients fall back to the discovery body.
+	HashesHeader = "X-OpenAPI-V3-Hashes"
+	// KnownHashesHeader may be sent on discovery requests with the hashes the
+	// client already has, as encoded by EncodeKnownHashes. HashesHeader then
+	// only lists the group-versions whose hash differs from the known one,
+	// and the known group-versions that were removed with an empty hash.
+	// Requests with a malformed KnownHashesHeader are rejected with 400 Bad
+	// Request.
+	KnownHashesHeader = "X-OpenAPI-V3-Known-Hashes"
+)
+
+// maxHashesHeaderSize bounds the size of the HashesHeader value, to stay
+// well within the header size limits of common proxies and clients.
+const maxHashesHeaderSize = 8 << 10
+
 // OpenAPIV3Discovery is the format of the Discovery document for OpenAPI V3
 // It maps Discovery paths to their corresponding URLs with a hash parameter included
-type OpenAPIV3Discovery struct {
-	Paths map[string]OpenAPIV3DiscoveryGroupVersion `json:"paths"`
-}
+type OpenAPIV3Discovery = spec3.Discovery
 
 // OpenAPIV3DiscoveryGroupVersion includes information about a group version and URL
 // for accessing the OpenAPI. The URL includes a hash parameter to support client side caching
-type OpenAPIV3DiscoveryGroupVersion struct {
-	// Path is an absolute path of an OpenAPI V3 document in the form of /openapi/v3/apis/apps/v1?hash=014fbff9a07c
-	ServerRelativeURL string `json:"serverRelativeURL"`
-}
+type OpenAPIV3DiscoveryGroupVersion = spec3.DiscoveryGroupVersion
 
 // OpenAPIService is the service responsible for serving OpenAPI spec. It has
-// the ability to safely change the spec while serving it.
+// the ability to safely change the spec while serving it. Every request is
+// served from a single version of a group-version's spec, even if it is
+// updated meanwhile.
 type OpenAPIService struct {
-	// rwMutex protects All members of this service.
+	// rwMutex protects the group-versions of this service.
 	rwMutex      sync.RWMutex
 	lastModified time.Time
 	v3Schema     map[string]*OpenAPIV3Group
+
+	lifecycle handler.Lifecycle
+	// marshalLimiter bounds the serializations of the specs of all groups.
+	marshalLimiter marshalLimiter
+	// digestHeaders is set to 1 to send digest headers with the specs.
+	digestHeaders int32
+	// flattenPathRefs is set to 1 to inline the path items referenced by
+	// other path items when serializing the specs.
+	flattenPathRefs int32
 }
 
 type OpenAPIV3Group struct {
-	rwMutex sync.RWMutex
-
-	lastModified time.Time
-
-	pbCache   handler.HandlerCache
-	jsonCache handler.HandlerCache
-	etagCache handler.HandlerCache
+	specs handler.SnapshotHolder
+	// limiter, if not nil, bounds the serializations of the spec.
+	limiter *marshalLimiter
+	// flattenPathRefs, if not nil, points to the flattenPathRefs setting of
+	// the service.
+	flattenPathRefs *int32
+	// requested is set once a client requested the spec of the group, to
+	// serialize it before the specs only hashed for discovery.
+	requested int32
 }
 
 func computeETag(data []byte) string {
 	if data == nil {
 		return ""
 	}
-	return fmt.Sprintf("%X", sha512.Sum512(data))
+	return spec3.DiscoveryHash(data)
 }
 
 func constructServerRelativeURL(gvString, etag string) string {
-	u := url.URL{Path: path.Join("/openapi/v3", gvString)}
-	query := url.Values{}
-	query.Set("hash", etag)
-	u.RawQuery = query.Encode()
-	return u.String()
+	return spec3.DiscoveryURL(gvString, etag)
 }
 
 // NewOpenAPIService builds an OpenAPIService starting with the given spec.
@@ -98,69 +125,215 @@ func NewOpenAPIService(spec *spec.Swagger) (*OpenAPIService, error) {
 	return o, nil
 }
 
-func (o *OpenAPIService) getGroupBytes() ([]byte, error) {
+func (o *OpenAPIService) getGroupBytes() ([]byte, map[string]string, error) {
 	o.rwMutex.RLock()
 	defer o.rwMutex.RUnlock()
-	keys := make([]string, len(o.v3Schema))
-	i := 0
-	for k := range o.v3Schema {
-		keys[i] = k
-		i++
-	}
-
-	sort.Strings(keys)
-	discovery := &OpenAPIV3Discovery{Paths: make(map[string]OpenAPIV3DiscoveryGroupVersion)}
+	hashes := make(map[string]string, len(o.v3Schema))
 	for gvString, groupVersion := range o.v3Schema {
-		etagBytes, err := groupVersion.etagCache.Get()
+		etagBytes, err := groupVersion.etag()
 		if err != nil {
-			return nil, err
-		}
-		discovery.Paths[gvString] = OpenAPIV3DiscoveryGroupVersion{
-			ServerRelativeURL: constructServerRelativeURL(gvString, string(etagBytes)),
+			return nil, nil, err
 		}
+		hashes[gvString] = string(etagBytes)
+	}
+	j, err := json.Marshal(spec3.NewDiscovery(hashes))
+	if err != nil {
+		return nil, nil, err
+	}
+	return j, hashes, nil
+}
+
+// EncodeKnownHashes encodes a group-version to hash map into the compressed
+// form expected in the KnownHashesHeader request header.
+func EncodeKnownHashes(hashes map[string]string) (string, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := io.WriteString(zw, formatHashes(hashes, "\n")); err != nil {
+		return "", err
+	}
+	if err := zw.Close(); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+func decodeKnownHashes(value string) (map[string]string, error) {
+	compressed, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, err
 	}
-	j, err := json.Marshal(discovery)
+	defer zr.Close()
+	// Bound the decompressed size, the header comes from untrusted clients.
+	data, err := io.ReadAll(io.LimitReader(zr, maxKnownHashesSize+1))
 	if err != nil {
 		return nil, err
 	}
-	return j, nil
+	if len(data) > maxKnownHashesSize {
+		return nil, fmt.Errorf("known hashes exceed %d bytes", maxKnownHashesSize)
+	}
+	hashes := map[string]string{}
+	for _, line := range strings.Split(string(data), "\n") {
+		if line == "" {
+			continue
+		}
+		gv, hash, found := strings.Cut(line, "=")
+		if !found {
+			return nil, fmt.Errorf("invalid known hash entry %q", line)
+		}
+		hashes[gv] = hash
+	}
+	return hashes, nil
+}
+
+const maxKnownHashesSize = 1 << 20
+
+func formatHashes(hashes map[string]string, sep string) string {
+	keys := make([]string, 0, len(hashes))
+	for k := range hashes {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	entries := make([]string, len(keys))
+	for i, k := range keys {
+		entries[i] = k + "=" + hashes[k]
+	}
+	return strings.Join(entries, sep)
 }
 
-func (o *OpenAPIService) getSingleGroupBytes(getType string, group string) ([]byte, string, time.Time, error) {
+// acquireGroup returns the current snapshot of the spec of group, and marks
+// the group as requested. The returned func must be called once the request
+// is done with it.
+func (o *OpenAPIService) acquireGroup(group string) (*handler.Snapshot, func(), error) {
 	o.rwMutex.RLock()
-	defer o.rwMutex.RUnlock()
 	v, ok := o.v3Schema[group]
+	o.rwMutex.RUnlock()
 	if !ok {
-		return nil, "", time.Now(), fmt.Errorf("Cannot find CRD group %s", group)
+		return nil, nil, fmt.Errorf("Cannot find CRD group %s", group)
+	}
+	atomic.StoreInt32(&v.requested, 1)
+	s, release := v.specs.Acquire()
+	if s == nil {
+		release()
+		return nil, nil, fmt.Errorf("Cannot find CRD group %s", group)
 	}
+	return s, release, nil
+}
+
+func getSnapshotBytes(getType string, s *handler.Snapshot) ([]byte, string, time.Time, error) {
 	if getType == subTypeJSON {
-		specBytes, err := v.jsonCache.Get()
+		specBytes, err := s.JSON.Get()
 		if err != nil {
-			return nil, "", v.lastModified, err
+			return nil, "", s.LastModified, err
 		}
-		etagBytes, err := v.etagCache.Get()
-		return specBytes, string(etagBytes), v.lastModified, err
+		etagBytes, err := s.ETag.Get()
+		return specBytes, string(etagBytes), s.LastModified, err
 	} else if getType == subTypeProtobuf {
-		specPb, err := v.pbCache.Get()
+		specPb, err := s.Proto.Get()
 		if err != nil {
-			return nil, "", v.lastModified, err
+			return nil, "", s.LastModified, err
 		}
-		etagBytes, err := v.etagCache.Get()
-		return specPb, string(etagBytes), v.lastModified, err
+		etagBytes, err := s.ETag.Get()
+		return specPb, string(etagBytes), s.LastModified, err
 	}
 	return nil, "", time.Now(), fmt.Errorf("Invalid accept clause %s", getType)
 }
 
+func getSnapshotDigest(getType string, s *handler.Snapshot) ([]byte, error) {
+	if getType == subTypeProtobuf {
+		return s.ProtoDigest.Get()
+	}
+	return s.JSONDigest.Get()
+}
+
+// UpdateGroupVersion replaces the spec served for group. Requests already
+// in flight finish serving the previous spec.
 func (o *OpenAPIService) UpdateGroupVersion(group string, openapi *spec3.OpenAPI) (err error) {
 	o.rwMutex.Lock()
 	defer o.rwMutex.Unlock()
 
 	if _, ok := o.v3Schema[group]; !ok {
-		o.v3Schema[group] = &OpenAPIV3Group{}
+		o.v3Schema[group] = o.newGroup()
 	}
 	return o.v3Schema[group].UpdateSpec(openapi)
 }
 
+// SwapGroupVersion replaces the spec served for group like
+// UpdateGroupVersion, but acts as a fence: it only returns once every
+// request served from a previous spec of group has finished, or with
+// ctx.Err() once ctx is done.
+func (o *OpenAPIService) SwapGroupVersion(ctx context.Context, group string, openapi *spec3.OpenAPI) error {
+	o.rwMutex.Lock()
+	v, ok := o.v3Schema[group]
+	if !ok {
+		// nothing to wait for, the group is new
+		defer o.rwMutex.Unlock()
+		o.v3Schema[group] = o.newGroup()
+		return o.v3Schema[group].UpdateSpec(openapi)
+	}
+	o.rwMutex.Unlock()
+	return v.SwapSpec(ctx, openapi)
+}
+
+func (o *OpenAPIService) newGroup() *OpenAPIV3Group {
+	return &OpenAPIV3Group{limiter: &o.marshalLimiter, flattenPathRefs: &o.flattenPathRefs}
+}
+
+// SetMaxConcurrentMarshals bounds the number of group-version specs being
+// serialized at the same time, so that a burst of spec updates, e.g. after
+// many CRDs changed, does not starve request handling. Specs of
+// group-versions requested by clients are serialized before the ones only
+// hashed for discovery. Zero, the default, means no limit.
+func (o *OpenAPIService) SetMaxConcurrentMarshals(n int) {
+	o.marshalLimiter.setLimit(n)
+}
+
+// MarshalQueueStats returns the current state of the serialization of
+// group-version specs, e.g. to export it as metrics.
+func (o *OpenAPIService) MarshalQueueStats() MarshalQueueStats {
+	return o.marshalLimiter.getStats()
+}
+
+// SetDigestHeaders makes the service send the Repr-Digest (RFC 9530) and
+// Digest (RFC 3230) headers with the group-version specs, so that clients
+// can detect a corrupted transfer. Digests are computed once per spec and
+// serialization.
+func (o *OpenAPIService) SetDigestHeaders(enabled bool) {
+	var v int32
+	if enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&o.digestHeaders, v)
+}
+
+// SetFlattenPathRefs makes the service serve the specs with their path items
+// that reference other path items replaced by the items they reference, see
+// spec3.Paths.Flatten, for clients that do not follow path item references.
+// It applies to specs serialized afterwards.
+func (o *OpenAPIService) SetFlattenPathRefs(enabled bool) {
+	var v int32
+	if enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&o.flattenPathRefs, v)
+}
+
+// Start makes the service serve requests again after Shutdown. A new
+// service serves requests without calling Start.
+func (o *OpenAPIService) Start() {
+	o.lifecycle.Start()
+}
+
+// Shutdown makes the service answer new requests with 503 Service
+// Unavailable, and waits for the requests in flight to finish or for ctx
+// to be done.
+func (o *OpenAPIService) Shutdown(ctx context.Context) error {
+	return o.lifecycle.Shutdown(ctx)
+}
+
 func (o *OpenAPIService) DeleteGroupVersion(group string) {
 	o.rwMutex.Lock()
 	defer o.rwMutex.Unlock()
@@ -176,13 +349,48 @@ func ToV3ProtoBinary(json []byte) ([]byte, error) {
 }
 
 func (o *OpenAPIService) HandleDiscovery(w http.ResponseWriter, r *http.Request) {
-	data, _ := o.getGroupBytes()
-	w.Header().Set("Etag", strconv.Quote(computeETag(data)))
+	if !o.lifecycle.Enter() {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	defer o.lifecycle.Exit()
+
+	data, hashes, _ := o.getGroupBytes()
+	// HashesHeader, and so the ETag, depend on KnownHashesHeader.
+	w.Header().Set("Vary", KnownHashesHeader)
+	etag := computeETag(data)
+	if v := r.Header.Get(KnownHashesHeader); v != "" {
+		known, err := decodeKnownHashes(v)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid %s header: %v", KnownHashesHeader, err), http.StatusBadRequest)
+			return
+		}
+		for gv, hash := range known {
+			current, found := hashes[gv]
+			if !found {
+				// an empty hash marks the group-version as removed
+				hashes[gv] = ""
+			} else if current == hash {
+				delete(hashes, gv)
+			}
+		}
+		etag = computeETag([]byte(string(data) + "\n" + formatHashes(hashes, ",")))
+	}
+	if value := formatHashes(hashes, ","); len(value) <= maxHashesHeaderSize {
+		w.Header().Set(HashesHeader, value)
+	}
+	w.Header().Set("Etag", strconv.Quote(etag))
 	w.Header().Set("Content-Type", "application/json")
 	http.ServeContent(w, r, "/openapi/v3", time.Now(), bytes.NewReader(data))
 }
 
 func (o *OpenAPIService) HandleGroupVersion(w http.ResponseWriter, r *http.Request) {
+	if !o.lifecycle.Enter() {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	defer o.lifecycle.Exit()
+
 	url := strings.SplitAfterN(r.URL.Path, "/", 4)
 	group := url[3]
 
@@ -205,6 +413,12 @@ func (o *OpenAPIService) HandleGroupVersion(w http.ResponseWriter, r *http.Reque
 		{"application", subTypeProtobuf},
 	}
 
+	snapshot, release, err := o.acquireGroup(group)
+	if err != nil {
+		return
+	}
+	defer release()
+
 	for _, clause := range clauses {
 		for _, accepts := range accepted {
 			if clause.Type != accepts.Type && clause.Type != "*" {
@@ -213,12 +427,17 @@ func (o *OpenAPIService) HandleGroupVersion(w http.ResponseWriter, r *http.Reque
 			if clause.SubType != accepts.SubType && clause.SubType != "*" {
 				continue
 			}
-			data, etag, lastModified, err := o.getSingleGroupBytes(accepts.SubType, group)
+			data, etag, lastModified, err := getSnapshotBytes(accepts.SubType, snapshot)
 			if err != nil {
 				return
 			}
 			// ETag must be enclosed in double quotes: https://developer.mozilla.org/en-US/docs/Web/HTTP/Headers/ETag
 			w.Header().Set("Etag", strconv.Quote(etag))
+			if atomic.LoadInt32(&o.digestHeaders) != 0 {
+				if sum, err := getSnapshotDigest(accepts.SubType, snapshot); err == nil {
+					handler.SetDigestHeaders(w.Header(), sum)
+				}
+			}
 
 			if hash := r.URL.Query().Get("hash"); hash != "" {
 				if hash != etag {
@@ -251,29 +470,63 @@ func (o *OpenAPIService) RegisterOpenAPIV3VersionedService(servePath string, han
 	return nil
 }
 
+// UpdateSpec replaces the spec of the group-version. Requests already in
+// flight finish serving the previous spec.
 func (o *OpenAPIV3Group) UpdateSpec(openapi *spec3.OpenAPI) (err error) {
-	o.rwMutex.Lock()
-	defer o.rwMutex.Unlock()
+	o.specs.Update(o.newSpecSnapshot(openapi))
+	return nil
+}
+
+// SwapSpec replaces the spec of the group-version like UpdateSpec, but only
+// returns once every request served from a previous spec has finished, or
+// with ctx.Err() once ctx is done.
+func (o *OpenAPIV3Group) SwapSpec(ctx context.Context, openapi *spec3.OpenAPI) error {
+	return o.specs.Swap(ctx, o.newSpecSnapshot(openapi))
+}
+
+func (o *OpenAPIV3Group) etag() ([]byte, error) {
+	s, release := o.specs.Acquire()
+	defer release()
+	if s == nil {
+		return nil, fmt.Errorf("no spec")
+	}
+	return s.ETag.Get()
+}
 
-	o.jsonCache = o.jsonCache.New(func() ([]byte, error) {
+// marshal serializes openapi, with its path item references flattened if
+// the service is configured to. openapi is not modified.
+func (o *OpenAPIV3Group) marshal(openapi *spec3.OpenAPI) ([]byte, error) {
+	if openapi == nil || openapi.Paths == nil || o.flattenPathRefs == nil || atomic.LoadInt32(o.flattenPathRefs) == 0 {
 		return json.Marshal(openapi)
-	})
-	o.pbCache = o.pbCache.New(func() ([]byte, error) {
-		json, err := o.jsonCache.Get()
-		if err != nil {
-			return nil, err
-		}
-		return ToV3ProtoBinary(json)
-	})
-	// TODO: This forces a json marshal of corresponding group-versions.
-	// We should look to replace this with a faster hashing mechanism.
-	o.etagCache = o.etagCache.New(func() ([]byte, error) {
-		json, err := o.jsonCache.Get()
-		if err != nil {
-			return nil, err
-		}
-		return []byte(computeETag(json)), nil
-	})
-	o.lastModified = time.Now()
-	return nil
+	}
+	flattened := *openapi
+	paths := *openapi.Paths
+	paths.Paths = make(map[string]*spec3.Path, len(openapi.Paths.Paths))
+	for k, v := range openapi.Paths.Paths {
+		paths.Paths[k] = v
+	}
+	if err := paths.Flatten(); err != nil {
+		return nil, err
+	}
+	flattened.Paths = &paths
+	return json.Marshal(&flattened)
+}
+
+func (o *OpenAPIV3Group) newSpecSnapshot(openapi *spec3.OpenAPI) func(*handler.Snapshot) *handler.Snapshot {
+	requested := func() bool {
+		return atomic.LoadInt32(&o.requested) != 0
+	}
+	return func(prev *handler.Snapshot) *handler.Snapshot {
+		// TODO: The ETag forces a json marshal of corresponding group-versions.
+		// We should look to replace this with a faster hashing mechanism.
+		return handler.NewSnapshot(prev, func() ([]byte, error) {
+			return o.limiter.do(requested, func() ([]byte, error) {
+				return o.marshal(openapi)
+			})
+		}, func(json []byte) ([]byte, error) {
+			return o.limiter.do(requested, func() ([]byte, error) {
+				return ToV3ProtoBinary(json)
+			})
+		}, computeETag)
+	}
 }
@@ -18,17 +18,23 @@ package handler3
 
 import (
 	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
 	"strconv"
+	"sync"
 	"testing"
 	"time"
 
 	"encoding/json"
 
 	"k8s.io/kube-openapi/pkg/spec3"
+	"k8s.io/kube-openapi/pkg/validation/spec"
 )
 
 var returnedOpenAPI = []byte(`{
@@ -383,3 +389,263 @@ func TestCacheBusting(t *testing.T) {
 		}
 	}
 }
+
+func TestDiscoveryHashesHeader(t *testing.T) {
+	var s *spec3.OpenAPI
+	if err := json.Unmarshal(returnedOpenAPI, &s); err != nil {
+		t.Fatal(err)
+	}
+	o, err := NewOpenAPIService(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	o.UpdateGroupVersion("apis/apps/v1", s)
+	o.UpdateGroupVersion("api/v1", &spec3.OpenAPI{Version: "3.0.0"})
+	_, hashes, err := o.getGroupBytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	known, err := EncodeKnownHashes(map[string]string{
+		"apis/apps/v1":  hashes["apis/apps/v1"],
+		"api/v1":        "stale",
+		"apis/batch/v1": "removed",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tcs := []struct {
+		name        string
+		knownHashes string
+		code        int
+		expected    string
+	}{
+		{
+			name:     "no known hashes",
+			code:     http.StatusOK,
+			expected: "api/v1=" + hashes["api/v1"] + ",apis/apps/v1=" + hashes["apis/apps/v1"],
+		},
+		{
+			name:        "known hashes",
+			knownHashes: known,
+			code:        http.StatusOK,
+			expected:    "api/v1=" + hashes["api/v1"] + ",apis/batch/v1=",
+		},
+		{
+			name:        "undecodable known hashes",
+			knownHashes: "not-gzip",
+			code:        http.StatusBadRequest,
+		},
+	}
+	etags := map[string]bool{}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/openapi/v3", nil)
+			if tc.knownHashes != "" {
+				req.Header.Set(KnownHashesHeader, tc.knownHashes)
+			}
+			w := httptest.NewRecorder()
+			o.HandleDiscovery(w, req)
+			if w.Code != tc.code {
+				t.Fatalf("expected %d, got %d: %s", tc.code, w.Code, w.Body.String())
+			}
+			if got := w.Header().Get(HashesHeader); got != tc.expected {
+				t.Errorf("expected %s header %q, got %q", HashesHeader, tc.expected, got)
+			}
+			if got := w.Header().Get("Vary"); got != KnownHashesHeader {
+				t.Errorf("expected Vary header %q, got %q", KnownHashesHeader, got)
+			}
+			if tc.code == http.StatusOK {
+				etag := w.Header().Get("Etag")
+				if etags[etag] {
+					t.Errorf("expected responses with different %s headers to have different ETags, got %s twice", HashesHeader, etag)
+				}
+				etags[etag] = true
+			}
+		})
+	}
+}
+
+func TestDiscoveryHashesHeaderSize(t *testing.T) {
+	o, err := NewOpenAPIService(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 1000; i++ {
+		o.UpdateGroupVersion(fmt.Sprintf("apis/group%d.example.com/v1", i), &spec3.OpenAPI{Version: "3.0.0", Info: &spec.Info{InfoProps: spec.InfoProps{Title: fmt.Sprintf("v%d", i)}}})
+	}
+	w := httptest.NewRecorder()
+	o.HandleDiscovery(w, httptest.NewRequest("GET", "/openapi/v3", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d", http.StatusOK, w.Code)
+	}
+	if _, found := w.Header()[HashesHeader]; found {
+		t.Errorf("expected no %s header above %d bytes, got %d bytes", HashesHeader, maxHashesHeaderSize, len(w.Header().Get(HashesHeader)))
+	}
+	var discovery OpenAPIV3Discovery
+	if err := json.Unmarshal(w.Body.Bytes(), &discovery); err != nil {
+		t.Fatal(err)
+	}
+	if len(discovery.Paths) != 1000 {
+		t.Errorf("expected the full listing of 1000 group-versions, got %d", len(discovery.Paths))
+	}
+}
+
+func TestConcurrentUpdatesServeConsistentSnapshots(t *testing.T) {
+	o, err := NewOpenAPIService(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	specFor := func(i int) *spec3.OpenAPI {
+		return &spec3.OpenAPI{Version: "3.0.0", Info: &spec.Info{InfoProps: spec.InfoProps{Title: fmt.Sprintf("v%d", i)}}}
+	}
+	o.UpdateGroupVersion("apis/apps/v1", specFor(0))
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 1; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			if i%2 == 0 {
+				o.SwapGroupVersion(context.Background(), "apis/apps/v1", specFor(i))
+			} else {
+				o.UpdateGroupVersion("apis/apps/v1", specFor(i))
+			}
+		}
+	}()
+
+	for reader := 0; reader < 4; reader++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				req := httptest.NewRequest("GET", "/openapi/v3/apis/apps/v1", nil)
+				req.Header.Set("Accept", "application/json")
+				w := httptest.NewRecorder()
+				o.HandleGroupVersion(w, req)
+				if w.Code != http.StatusOK {
+					t.Errorf("unexpected status %d", w.Code)
+					return
+				}
+				if etag, want := w.Header().Get("Etag"), strconv.Quote(computeETag(w.Body.Bytes())); etag != want {
+					t.Errorf("torn read: Etag %s does not match body %s", etag, w.Body.String())
+					return
+				}
+			}
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(stop)
+	wg.Wait()
+}
+
+func TestShutdown(t *testing.T) {
+	o, err := NewOpenAPIService(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	o.UpdateGroupVersion("apis/apps/v1", &spec3.OpenAPI{Version: "3.0.0"})
+
+	if err := o.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{"/openapi/v3", "/openapi/v3/apis/apps/v1"} {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", path, nil)
+		if path == "/openapi/v3" {
+			o.HandleDiscovery(w, req)
+		} else {
+			o.HandleGroupVersion(w, req)
+		}
+		if w.Code != http.StatusServiceUnavailable {
+			t.Errorf("%s: expected %d after Shutdown, got %d", path, http.StatusServiceUnavailable, w.Code)
+		}
+	}
+
+	o.Start()
+	w := httptest.NewRecorder()
+	o.HandleGroupVersion(w, httptest.NewRequest("GET", "/openapi/v3/apis/apps/v1", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("expected %d after Start, got %d", http.StatusOK, w.Code)
+	}
+}
+
+func TestDigestHeaders(t *testing.T) {
+	o, err := NewOpenAPIService(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var s *spec3.OpenAPI
+	if err := json.Unmarshal(returnedOpenAPI, &s); err != nil {
+		t.Fatal(err)
+	}
+	o.UpdateGroupVersion("apis/apps/v1", s)
+
+	get := func(accept string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/openapi/v3/apis/apps/v1", nil)
+		req.Header.Set("Accept", accept)
+		w := httptest.NewRecorder()
+		o.HandleGroupVersion(w, req)
+		return w
+	}
+
+	if w := get("application/json"); w.Header().Get("Repr-Digest") != "" {
+		t.Errorf("expected no digest unless enabled, got %q", w.Header().Get("Repr-Digest"))
+	}
+
+	o.SetDigestHeaders(true)
+	for _, accept := range []string{"application/json", "application/com.github.proto-openapi.spec.v3@v1.0+protobuf"} {
+		w := get(accept)
+		sum := sha256.Sum256(w.Body.Bytes())
+		encoded := base64.StdEncoding.EncodeToString(sum[:])
+		if got, want := w.Header().Get("Repr-Digest"), "sha-256=:"+encoded+":"; got != want {
+			t.Errorf("Accept: %v: expected Repr-Digest %q, got %q", accept, want, got)
+		}
+		if got, want := w.Header().Get("Digest"), "SHA-256="+encoded; got != want {
+			t.Errorf("Accept: %v: expected Digest %q, got %q", accept, want, got)
+		}
+	}
+}
+
+func TestFlattenPathRefs(t *testing.T) {
+	o, err := NewOpenAPIService(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var s *spec3.OpenAPI
+	if err := json.Unmarshal([]byte(`{
+  "openapi": "3.0.0",
+  "paths": {
+    "/apis/apps/v1/deployments": {"get": {"operationId": "listDeployments"}},
+    "/apis/apps/v1/namespaces/{namespace}/deployments": {"$ref": "#/paths/~1apis~1apps~1v1~1deployments"}
+  }
+}`), &s); err != nil {
+		t.Fatal(err)
+	}
+	o.SetFlattenPathRefs(true)
+	o.UpdateGroupVersion("apis/apps/v1", s)
+
+	w := httptest.NewRecorder()
+	o.HandleGroupVersion(w, httptest.NewRequest("GET", "/openapi/v3/apis/apps/v1", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	var served *spec3.OpenAPI
+	if err := json.Unmarshal(w.Body.Bytes(), &served); err != nil {
+		t.Fatal(err)
+	}
+	item := served.Paths.Paths["/apis/apps/v1/namespaces/{namespace}/deployments"]
+	if item.Ref.String() != "" || item.Get == nil || item.Get.OperationId != "listDeployments" {
+		t.Errorf("expected the path item to be flattened, got %#v", item)
+	}
+	if s.Paths.Paths["/apis/apps/v1/namespaces/{namespace}/deployments"].Ref.String() == "" {
+		t.Errorf("expected the spec not to be modified")
+	}
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler3
+
+import (
+	"sync"
+	"time"
+)
+
+// MarshalQueueStats describes the marshal operations of an OpenAPIService,
+// that is the serialization of group-version specs to JSON and protobuf.
+type MarshalQueueStats struct {
+	// Running is the number of marshal operations in progress.
+	Running int
+	// Queued is the number of marshal operations waiting for a slot.
+	Queued int
+	// Completed is the number of marshal operations done.
+	Completed int64
+	// WaitTime is the total time marshal operations spent queued.
+	WaitTime time.Duration
+}
+
+// marshalLimiter bounds the number of marshal operations running at the
+// same time. Waiting operations for which the priority func returns true
+// are started first, the others in order of arrival. The zero value does
+// not limit anything.
+type marshalLimiter struct {
+	mu      sync.Mutex
+	limit   int
+	waiting []*marshalWaiter
+	stats   MarshalQueueStats
+}
+
+type marshalWaiter struct {
+	priority func() bool
+	ready    chan struct{}
+}
+
+func (l *marshalLimiter) setLimit(limit int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.limit = limit
+	l.dispatchLocked()
+}
+
+// do runs f once a slot is available. A nil limiter runs f right away.
+func (l *marshalLimiter) do(priority func() bool, f func() ([]byte, error)) ([]byte, error) {
+	if l == nil {
+		return f()
+	}
+	start := time.Now()
+	l.mu.Lock()
+	if l.limit > 0 && (l.stats.Running >= l.limit || len(l.waiting) > 0) {
+		w := &marshalWaiter{priority: priority, ready: make(chan struct{})}
+		l.waiting = append(l.waiting, w)
+		l.stats.Queued++
+		l.mu.Unlock()
+		<-w.ready
+		l.mu.Lock()
+		l.stats.WaitTime += time.Since(start)
+	} else {
+		l.stats.Running++
+	}
+	l.mu.Unlock()
+
+	defer func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		l.stats.Running--
+		l.stats.Completed++
+		l.dispatchLocked()
+	}()
+	return f()
+}
+
+// dispatchLocked starts waiting operations while slots are available.
+func (l *marshalLimiter) dispatchLocked() {
+	for len(l.waiting) > 0 && (l.limit <= 0 || l.stats.Running < l.limit) {
+		next := 0
+		for i, w := range l.waiting {
+			if w.priority != nil && w.priority() {
+				next = i
+				break
+			}
+		}
+		w := l.waiting[next]
+		l.waiting = append(l.waiting[:next], l.waiting[next+1:]...)
+		l.stats.Queued--
+		l.stats.Running++
+		close(w.ready)
+	}
+}
+
+func (l *marshalLimiter) getStats() MarshalQueueStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.stats
+}
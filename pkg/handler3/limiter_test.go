@@ -0,0 +1,127 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler3
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"k8s.io/kube-openapi/pkg/spec3"
+)
+
+func TestMarshalLimiter(t *testing.T) {
+	l := &marshalLimiter{}
+	l.setLimit(1)
+
+	// occupy the only slot
+	started := make(chan struct{})
+	unblock := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		l.do(nil, func() ([]byte, error) {
+			close(started)
+			<-unblock
+			return nil, nil
+		})
+	}()
+	<-started
+
+	var mu sync.Mutex
+	var order []string
+	queue := func(name string, priority bool) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			l.do(func() bool { return priority }, func() ([]byte, error) {
+				mu.Lock()
+				defer mu.Unlock()
+				order = append(order, name)
+				return nil, nil
+			})
+		}()
+	}
+	waitQueued := func(n int) {
+		for l.getStats().Queued != n {
+			time.Sleep(time.Millisecond)
+		}
+	}
+	queue("discovery-1", false)
+	waitQueued(1)
+	queue("discovery-2", false)
+	waitQueued(2)
+	queue("requested", true)
+	waitQueued(3)
+
+	if stats := l.getStats(); stats.Running != 1 {
+		t.Errorf("expected 1 running operation, got %d", stats.Running)
+	}
+	close(unblock)
+	wg.Wait()
+
+	expected := []string{"requested", "discovery-1", "discovery-2"}
+	if !reflect.DeepEqual(order, expected) {
+		t.Errorf("expected operations to run in order %v, got %v", expected, order)
+	}
+	stats := l.getStats()
+	if stats.Running != 0 || stats.Queued != 0 || stats.Completed != 4 {
+		t.Errorf("unexpected stats after completion: %+v", stats)
+	}
+	if stats.WaitTime <= 0 {
+		t.Errorf("expected queued operations to account wait time")
+	}
+}
+
+func TestMaxConcurrentMarshals(t *testing.T) {
+	o, err := NewOpenAPIService(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	o.SetMaxConcurrentMarshals(1)
+	for _, gv := range []string{"apis/apps/v1", "apis/batch/v1", "api/v1"} {
+		o.UpdateGroupVersion(gv, &spec3.OpenAPI{Version: "3.0.0"})
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			o.HandleDiscovery(httptest.NewRecorder(), httptest.NewRequest("GET", "/openapi/v3", nil))
+		}()
+		go func() {
+			defer wg.Done()
+			req := httptest.NewRequest("GET", "/openapi/v3/apis/apps/v1", nil)
+			req.Header.Set("Accept", "application/com.github.proto-openapi.spec.v3@v1.0+protobuf")
+			w := httptest.NewRecorder()
+			o.HandleGroupVersion(w, req)
+			if w.Code != 200 {
+				t.Errorf("expected 200, got %d", w.Code)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// one JSON marshal per group-version, and one protobuf conversion
+	if stats := o.MarshalQueueStats(); stats.Completed != 4 || stats.Running != 0 || stats.Queued != 0 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
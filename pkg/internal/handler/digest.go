@@ -0,0 +1,31 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler
+
+import (
+	"encoding/base64"
+	"net/http"
+)
+
+// SetDigestHeaders sets the digest headers of a response whose full,
+// uncompressed body has the given SHA-256 sum: Repr-Digest as defined by
+// RFC 9530, and Digest as defined by RFC 3230 for older clients.
+func SetDigestHeaders(h http.Header, sha256Sum []byte) {
+	encoded := base64.StdEncoding.EncodeToString(sha256Sum)
+	h.Set("Repr-Digest", "sha-256=:"+encoded+":")
+	h.Set("Digest", "SHA-256="+encoded)
+}
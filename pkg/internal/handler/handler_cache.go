@@ -25,8 +25,10 @@ import (
 type HandlerCache struct {
 	BuildCache func() ([]byte, error)
 	once       sync.Once
-	bytes      []byte
-	err        error
+	// mu guards bytes against concurrent calls to Get and New.
+	mu    sync.Mutex
+	bytes []byte
+	err   error
 }
 
 // Get either returns the cached value or calls BuildCache() once before caching and returning
@@ -35,6 +37,8 @@ type HandlerCache struct {
 func (c *HandlerCache) Get() ([]byte, error) {
 	c.once.Do(func() {
 		bytes, err := c.BuildCache()
+		c.mu.Lock()
+		defer c.mu.Unlock()
 		// if there is an error updating the cache, there can be situations where
 		// c.bytes contains a valid value (carried over from the previous update)
 		// but c.err is also not nil; the cache user is expected to check for this
@@ -48,8 +52,10 @@ func (c *HandlerCache) Get() ([]byte, error) {
 }
 
 // New creates a new HandlerCache for situations where a cache refresh is needed.
-// This function is not thread-safe and should not be called at the same time as Get().
+// It may be called at the same time as Get().
 func (c *HandlerCache) New(cacheBuilder func() ([]byte, error)) HandlerCache {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	return HandlerCache{
 		bytes:      c.bytes,
 		BuildCache: cacheBuilder,
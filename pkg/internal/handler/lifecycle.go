@@ -0,0 +1,87 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler
+
+import (
+	"context"
+	"sync"
+)
+
+// Lifecycle tracks the requests in flight in a service, so that it can be
+// shut down gracefully. The zero value accepts requests.
+type Lifecycle struct {
+	mu       sync.Mutex
+	stopped  bool
+	inFlight int
+	// drained is closed once the last request in flight after a shutdown
+	// finishes.
+	drained chan struct{}
+}
+
+// Enter registers a new request. It returns false if the service is shut
+// down, in which case the request must not be served. Otherwise Exit must
+// be called once it is done.
+func (l *Lifecycle) Enter() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.stopped {
+		return false
+	}
+	l.inFlight++
+	return true
+}
+
+// Exit unregisters a request registered by Enter.
+func (l *Lifecycle) Exit() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.inFlight--
+	if l.inFlight == 0 && l.drained != nil {
+		close(l.drained)
+		l.drained = nil
+	}
+}
+
+// Start makes the service accept requests again after Shutdown.
+func (l *Lifecycle) Start() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.stopped = false
+}
+
+// Shutdown makes the service reject new requests, and waits for the ones
+// in flight to finish or for ctx to be done, whichever happens first.
+func (l *Lifecycle) Shutdown(ctx context.Context) error {
+	l.mu.Lock()
+	l.stopped = true
+	if l.inFlight == 0 {
+		l.mu.Unlock()
+		return nil
+	}
+	if l.drained == nil {
+		l.drained = make(chan struct{})
+	}
+	drained := l.drained
+	l.mu.Unlock()
+
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,61 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"k8s.io/kube-openapi/pkg/internal/handler"
+)
+
+func TestLifecycle(t *testing.T) {
+	var l handler.Lifecycle
+	if !l.Enter() {
+		t.Fatalf("expected a new lifecycle to accept requests")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := l.Shutdown(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("expected Shutdown to time out with a request in flight, got %v", err)
+	}
+	if l.Enter() {
+		t.Fatalf("expected new requests to be rejected after Shutdown")
+	}
+
+	done := make(chan error)
+	go func() {
+		done <- l.Shutdown(context.Background())
+	}()
+	l.Exit()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("unexpected error from Shutdown: %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatalf("Shutdown did not return once the requests in flight finished")
+	}
+
+	l.Start()
+	if !l.Enter() {
+		t.Fatalf("expected requests to be accepted after Start")
+	}
+	l.Exit()
+}
@@ -0,0 +1,202 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler
+
+import (
+	"context"
+	"crypto/sha256"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Snapshot holds one version of a spec along with its lazily computed
+// serializations. A snapshot is never modified once it is published, so a
+// request that acquired it serves bytes, ETag and modification time that
+// belong together even if the spec is replaced meanwhile.
+type Snapshot struct {
+	JSON         HandlerCache
+	Proto        HandlerCache
+	ETag         HandlerCache
+	LastModified time.Time
+	// JSONDigest and ProtoDigest hold the SHA-256 sums of JSON and Proto.
+	JSONDigest  HandlerCache
+	ProtoDigest HandlerCache
+
+	// mu guards readers, retired and released.
+	mu sync.Mutex
+	// readers counts the requests using the snapshot.
+	readers int
+	// retired is set once the snapshot is replaced. Requests that acquire
+	// it afterwards retry with the current snapshot.
+	retired bool
+	// released is closed once the snapshot is retired and no request uses
+	// it anymore.
+	released chan struct{}
+}
+
+// acquire registers a request using s, unless s was retired.
+func (s *Snapshot) acquire() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.retired {
+		return false
+	}
+	s.readers++
+	return true
+}
+
+func (s *Snapshot) release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.readers--
+	if s.retired && s.readers == 0 {
+		close(s.released)
+	}
+}
+
+// retire marks s as replaced and returns a channel closed once no request
+// uses it anymore.
+func (s *Snapshot) retire() <-chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.retired = true
+	s.released = make(chan struct{})
+	if s.readers == 0 {
+		close(s.released)
+	}
+	return s.released
+}
+
+// NewSnapshot returns a snapshot whose JSON is computed by marshalJSON, and
+// whose protobuf form and ETag are derived from that JSON by toProto and
+// computeETag. If prev is not nil, its serializations are served when
+// computing the new ones fails.
+func NewSnapshot(prev *Snapshot, marshalJSON func() ([]byte, error), toProto func([]byte) ([]byte, error), computeETag func([]byte) string) *Snapshot {
+	if prev == nil {
+		prev = &Snapshot{}
+	}
+	s := &Snapshot{LastModified: time.Now()}
+	s.JSON = prev.JSON.New(marshalJSON)
+	s.Proto = prev.Proto.New(func() ([]byte, error) {
+		json, err := s.JSON.Get()
+		if err != nil {
+			return nil, err
+		}
+		return toProto(json)
+	})
+	s.ETag = prev.ETag.New(func() ([]byte, error) {
+		json, err := s.JSON.Get()
+		if err != nil {
+			return nil, err
+		}
+		return []byte(computeETag(json)), nil
+	})
+	s.JSONDigest = prev.JSONDigest.New(sha256Of(&s.JSON))
+	s.ProtoDigest = prev.ProtoDigest.New(sha256Of(&s.Proto))
+	return s
+}
+
+func sha256Of(c *HandlerCache) func() ([]byte, error) {
+	return func() ([]byte, error) {
+		data, err := c.Get()
+		if err != nil {
+			return nil, err
+		}
+		sum := sha256.Sum256(data)
+		return sum[:], nil
+	}
+}
+
+// SnapshotHolder publishes the current snapshot of a spec.
+type SnapshotHolder struct {
+	// mu serializes updates, so that each snapshot is built from the one
+	// it replaces.
+	mu      sync.Mutex
+	current atomic.Value // *Snapshot
+	// replaced holds the channels closed once the replaced snapshots are
+	// not in use anymore, for the ones that may still be in use.
+	replaced []<-chan struct{}
+}
+
+// Acquire returns the current snapshot, or nil if none was published yet.
+// The returned func must be called once the caller is done with it.
+func (h *SnapshotHolder) Acquire() (*Snapshot, func()) {
+	for {
+		s, _ := h.current.Load().(*Snapshot)
+		if s == nil {
+			return nil, func() {}
+		}
+		if s.acquire() {
+			return s, s.release
+		}
+		// replaced between loading and acquiring it, use the new one
+	}
+}
+
+// Update publishes the snapshot returned by build, which is passed the
+// snapshot it replaces or nil. Requests that already acquired the replaced
+// snapshot keep serving it.
+func (h *SnapshotHolder) Update(build func(prev *Snapshot) *Snapshot) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.replace(build)
+}
+
+// Swap is like Update, but only returns once every request that acquired a
+// replaced snapshot has released it, or with ctx.Err() once ctx is done.
+// The new snapshot is published in either case, and later updates do not
+// wait for Swap to return.
+func (h *SnapshotHolder) Swap(ctx context.Context, build func(prev *Snapshot) *Snapshot) error {
+	h.mu.Lock()
+	h.replace(build)
+	replaced := append([]<-chan struct{}(nil), h.replaced...)
+	h.mu.Unlock()
+
+	for _, released := range replaced {
+		select {
+		case <-released:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+func (h *SnapshotHolder) replace(build func(prev *Snapshot) *Snapshot) {
+	prev, _ := h.current.Load().(*Snapshot)
+	h.current.Store(build(prev))
+	if prev == nil {
+		return
+	}
+	h.replaced = append(h.replaced, prev.retire())
+
+	// Forget the replaced snapshots that are not in use anymore, retired
+	// snapshots cannot be acquired again.
+	inUse := h.replaced[:0]
+	for _, released := range h.replaced {
+		select {
+		case <-released:
+		default:
+			inUse = append(inUse, released)
+		}
+	}
+	for i := len(inUse); i < len(h.replaced); i++ {
+		h.replaced[i] = nil
+	}
+	h.replaced = inUse
+}
@@ -0,0 +1,148 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"k8s.io/kube-openapi/pkg/internal/handler"
+)
+
+func snapshotOf(value string, err error) func(*handler.Snapshot) *handler.Snapshot {
+	return func(prev *handler.Snapshot) *handler.Snapshot {
+		return handler.NewSnapshot(prev, func() ([]byte, error) {
+			if err != nil {
+				return nil, err
+			}
+			return []byte(value), nil
+		}, func(json []byte) ([]byte, error) {
+			return append([]byte("pb:"), json...), nil
+		}, func(json []byte) string {
+			return "etag:" + string(json)
+		})
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	var h handler.SnapshotHolder
+	if s, release := h.Acquire(); s != nil {
+		release()
+		t.Fatalf("expected no snapshot before the first update")
+	}
+
+	h.Update(snapshotOf("a", nil))
+	s, release := h.Acquire()
+	json, _ := s.JSON.Get()
+	proto, _ := s.Proto.Get()
+	etag, _ := s.ETag.Get()
+	release()
+	if string(json) != "a" || string(proto) != "pb:a" || string(etag) != "etag:a" {
+		t.Fatalf("unexpected snapshot contents %q, %q, %q", json, proto, etag)
+	}
+
+	// a failing update keeps serving the last good serializations
+	h.Update(snapshotOf("", errors.New("marshal error")))
+	s, release = h.Acquire()
+	defer release()
+	json, err := s.JSON.Get()
+	if err == nil || string(json) != "a" {
+		t.Fatalf("expected last good value and an error, got %q, %v", json, err)
+	}
+}
+
+func TestSnapshotSwapFence(t *testing.T) {
+	var h handler.SnapshotHolder
+	h.Update(snapshotOf("a", nil))
+	old, release := h.Acquire()
+
+	// an update without fence does not wait for old readers
+	h.Update(snapshotOf("b", nil))
+
+	swapped := make(chan struct{})
+	go func() {
+		if err := h.Swap(context.Background(), snapshotOf("c", nil)); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		close(swapped)
+	}()
+	select {
+	case <-swapped:
+		t.Fatalf("Swap returned while a replaced snapshot was in use")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// the old snapshot stays consistent while it is held
+	if json, _ := old.JSON.Get(); string(json) != "a" {
+		t.Fatalf("expected held snapshot to serve %q, got %q", "a", json)
+	}
+	release()
+	select {
+	case <-swapped:
+	case <-time.After(10 * time.Second):
+		t.Fatalf("Swap did not return after the replaced snapshot was released")
+	}
+
+	s, release := h.Acquire()
+	defer release()
+	if json, _ := s.JSON.Get(); string(json) != "c" {
+		t.Fatalf("expected %q after the swap, got %q", "c", json)
+	}
+}
+
+func TestSnapshotSwapStalledReader(t *testing.T) {
+	var h handler.SnapshotHolder
+	h.Update(snapshotOf("a", nil))
+	// a reader that never releases its snapshot
+	h.Acquire()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := h.Swap(ctx, snapshotOf("b", nil)); err != context.DeadlineExceeded {
+		t.Fatalf("expected %v, got %v", context.DeadlineExceeded, err)
+	}
+
+	// later updates are not blocked by the stalled reader
+	updated := make(chan struct{})
+	go func() {
+		h.Update(snapshotOf("c", nil))
+		close(updated)
+	}()
+	select {
+	case <-updated:
+	case <-time.After(10 * time.Second):
+		t.Fatalf("Update blocked on a stalled reader")
+	}
+	s, release := h.Acquire()
+	if json, _ := s.JSON.Get(); string(json) != "c" {
+		t.Fatalf("expected %q after the update, got %q", "c", json)
+	}
+	release()
+
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	if err := h.Swap(ctx, snapshotOf("d", nil)); err != context.Canceled {
+		t.Fatalf("expected %v, got %v", context.Canceled, err)
+	}
+	s, release = h.Acquire()
+	defer release()
+	if json, _ := s.JSON.Get(); string(json) != "d" {
+		t.Fatalf("expected %q to be served although Swap was canceled, got %q", "d", json)
+	}
+}
@@ -0,0 +1,187 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mockserver
+
+import (
+	"math"
+	"strings"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+const schemaRefPrefix = "#/components/schemas/"
+
+// ExampleValue returns a value conforming to schema, as it would be decoded
+// from JSON. References of the form "#/components/schemas/<name>" are
+// resolved against schemas. The value is built deterministically:
+//
+//   - the example, default or first enum value of a schema is used as is;
+//   - objects get all of their properties, and the properties of their
+//     allOf schemas;
+//   - arrays get one item, or one item per prefixItems schema;
+//   - anyOf and oneOf use their first schema;
+//   - other values are the zero value of their type, adjusted to the
+//     minimum, minLength and format of the schema.
+//
+// Properties and items referring back to a schema being built are left
+// out, so that recursive schemas produce finite values.
+func ExampleValue(schema *spec.Schema, schemas map[string]*spec.Schema) interface{} {
+	g := &exampleGenerator{schemas: schemas, building: map[string]bool{}}
+	v, _ := g.value(schema)
+	return v
+}
+
+type exampleGenerator struct {
+	schemas map[string]*spec.Schema
+	// building holds the referenced schemas whose value is being built.
+	building map[string]bool
+}
+
+// value returns a value conforming to s. It returns false if s refers back
+// to a schema being built.
+func (g *exampleGenerator) value(s *spec.Schema) (interface{}, bool) {
+	if s == nil {
+		return nil, true
+	}
+	if r := s.Ref.String(); r != "" {
+		name := strings.TrimPrefix(r, schemaRefPrefix)
+		target, ok := g.schemas[name]
+		if !ok || name == r {
+			return nil, true
+		}
+		if g.building[name] {
+			return nil, false
+		}
+		g.building[name] = true
+		defer delete(g.building, name)
+		return g.value(target)
+	}
+
+	switch {
+	case s.Example != nil:
+		return s.Example, true
+	case s.Default != nil:
+		return s.Default, true
+	case len(s.Enum) > 0:
+		return s.Enum[0], true
+	case len(s.AnyOf) > 0:
+		return g.value(&s.AnyOf[0])
+	case len(s.OneOf) > 0:
+		return g.value(&s.OneOf[0])
+	case len(s.AllOf) == 1 && len(s.Type) == 0 && len(s.Properties) == 0:
+		// a reference wrapped to give it a description or default
+		return g.value(&s.AllOf[0])
+	}
+	if v, ok := s.Extensions.GetBool("x-kubernetes-int-or-string"); ok && v {
+		return 0, true
+	}
+
+	switch schemaType(s) {
+	case "object":
+		return g.object(s)
+	case "array":
+		return g.array(s), true
+	case "string":
+		return stringValue(s), true
+	case "integer":
+		if s.Minimum != nil {
+			return int64(math.Ceil(*s.Minimum)), true
+		}
+		return 0, true
+	case "number":
+		if s.Minimum != nil {
+			return *s.Minimum, true
+		}
+		return 0.0, true
+	case "boolean":
+		return false, true
+	}
+	return nil, true
+}
+
+func (g *exampleGenerator) object(s *spec.Schema) (interface{}, bool) {
+	obj := map[string]interface{}{}
+	for i := range s.AllOf {
+		v, ok := g.value(&s.AllOf[i])
+		if !ok {
+			return nil, false
+		}
+		if m, isObj := v.(map[string]interface{}); isObj {
+			for k, v := range m {
+				obj[k] = v
+			}
+		}
+	}
+	for name := range s.Properties {
+		prop := s.Properties[name]
+		if v, ok := g.value(&prop); ok {
+			obj[name] = v
+		}
+	}
+	return obj, true
+}
+
+func (g *exampleGenerator) array(s *spec.Schema) []interface{} {
+	items := []interface{}{}
+	for i := range s.PrefixItems {
+		v, ok := g.value(&s.PrefixItems[i])
+		if !ok {
+			return items
+		}
+		items = append(items, v)
+	}
+	if len(items) == 0 && s.Items != nil && s.Items.Schema != nil {
+		if v, ok := g.value(s.Items.Schema); ok {
+			items = append(items, v)
+		}
+	}
+	return items
+}
+
+func stringValue(s *spec.Schema) string {
+	switch s.Format {
+	case "date-time":
+		return "1970-01-01T00:00:00Z"
+	case "date":
+		return "1970-01-01"
+	case "duration":
+		return "0s"
+	case "uuid":
+		return "00000000-0000-0000-0000-000000000000"
+	}
+	if s.MinLength != nil && *s.MinLength > 0 {
+		return strings.Repeat("x", int(*s.MinLength))
+	}
+	return ""
+}
+
+// schemaType returns the type of the values s describes, or "" if it is
+// unknown.
+func schemaType(s *spec.Schema) string {
+	for _, t := range s.Type {
+		if t != "null" {
+			return t
+		}
+	}
+	if len(s.Type) == 0 && (len(s.Properties) > 0 || len(s.AllOf) > 0 || s.AdditionalProperties != nil) {
+		return "object"
+	}
+	if v, ok := s.Extensions.GetBool("x-kubernetes-preserve-unknown-fields"); ok && v {
+		return "object"
+	}
+	return ""
+}
@@ -0,0 +1,347 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package mockserver serves mock responses conforming to an OpenAPI v3
+// document, so that clients can be tested against the shape of an API
+// without a server implementing it.
+package mockserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"k8s.io/kube-openapi/pkg/spec3"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+const (
+	responseRefPrefix = "#/components/responses/"
+	exampleRefPrefix  = "#/components/examples/"
+)
+
+// pathParamRegexp matches the templated segments of a path, e.g. "{name}".
+var pathParamRegexp = regexp.MustCompile(`\{[^/{}]+\}`)
+
+// Server is an http.Handler answering requests for the operations of an
+// OpenAPI v3 document.
+//
+// A request is routed to the operation of the matching path and method,
+// preferring paths with fewer templated segments. The operation answers
+// with its lowest 2xx response, or else with its default response, or else
+// with its lowest response. The default response is served with status 200,
+// unless its description mentions an error or a failure, in which case it is
+// served with status 500. The body is the response content for
+// "application/json" if there is one, or else for the first media type in
+// lexical order. It is the media type example if there is one, the first
+// of its named examples, or else a value generated from its schema by
+// ExampleValue.
+//
+// Requests for unknown paths get 404, and requests with a method the path
+// has no operation for get 405.
+type Server struct {
+	doc    *spec3.OpenAPI
+	routes []route
+}
+
+type route struct {
+	pattern *regexp.Regexp
+	// params is the number of templated segments of the path.
+	params int
+	path   *spec3.Path
+}
+
+var _ http.Handler = &Server{}
+
+// New returns a Server for doc. It returns an error if a path of doc
+// cannot be routed, or if a response or example reference of an operation
+// does not resolve to a component of doc.
+func New(doc *spec3.OpenAPI) (*Server, error) {
+	s := &Server{doc: doc}
+	if doc.Paths == nil {
+		return s, nil
+	}
+	for template, path := range doc.Paths.Paths {
+		if path == nil {
+			continue
+		}
+		pattern, err := pathPattern(template)
+		if err != nil {
+			return nil, fmt.Errorf("path %q: %v", template, err)
+		}
+		if err := s.checkReferences(path); err != nil {
+			return nil, fmt.Errorf("path %q: %v", template, err)
+		}
+		s.routes = append(s.routes, route{
+			pattern: pattern,
+			params:  len(pathParamRegexp.FindAllString(template, -1)),
+			path:    path,
+		})
+	}
+	sort.Slice(s.routes, func(i, j int) bool {
+		if s.routes[i].params != s.routes[j].params {
+			return s.routes[i].params < s.routes[j].params
+		}
+		return s.routes[i].pattern.String() < s.routes[j].pattern.String()
+	})
+	return s, nil
+}
+
+// pathPattern returns a regular expression matching the request paths of
+// a path template.
+func pathPattern(template string) (*regexp.Regexp, error) {
+	var b strings.Builder
+	b.WriteString("^")
+	last := 0
+	for _, loc := range pathParamRegexp.FindAllStringIndex(template, -1) {
+		b.WriteString(regexp.QuoteMeta(template[last:loc[0]]))
+		b.WriteString("[^/]+")
+		last = loc[1]
+	}
+	b.WriteString(regexp.QuoteMeta(template[last:]))
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var path *spec3.Path
+	for _, rt := range s.routes {
+		if rt.pattern.MatchString(r.URL.Path) {
+			path = rt.path
+			break
+		}
+	}
+	if path == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	op := operation(path, r.Method)
+	if op == nil {
+		w.Header().Set("Allow", strings.Join(allowedMethods(path), ", "))
+		http.Error(w, fmt.Sprintf("method %s not allowed", r.Method), http.StatusMethodNotAllowed)
+		return
+	}
+
+	code, resp := s.response(op)
+	if resp == nil {
+		w.WriteHeader(code)
+		return
+	}
+	mediaType, content := pickContent(resp.Content)
+	if content == nil {
+		w.WriteHeader(code)
+		return
+	}
+	body, err := json.Marshal(s.body(content))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("cannot encode mock response: %v", err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", mediaType)
+	w.WriteHeader(code)
+	if r.Method != http.MethodHead {
+		w.Write(body)
+	}
+}
+
+var methods = []string{
+	http.MethodGet,
+	http.MethodPut,
+	http.MethodPost,
+	http.MethodDelete,
+	http.MethodOptions,
+	http.MethodHead,
+	http.MethodPatch,
+	http.MethodTrace,
+}
+
+func operation(path *spec3.Path, method string) *spec3.Operation {
+	switch method {
+	case http.MethodGet:
+		return path.Get
+	case http.MethodPut:
+		return path.Put
+	case http.MethodPost:
+		return path.Post
+	case http.MethodDelete:
+		return path.Delete
+	case http.MethodOptions:
+		return path.Options
+	case http.MethodHead:
+		return path.Head
+	case http.MethodPatch:
+		return path.Patch
+	case http.MethodTrace:
+		return path.Trace
+	}
+	return nil
+}
+
+func allowedMethods(path *spec3.Path) []string {
+	var allowed []string
+	for _, m := range methods {
+		if operation(path, m) != nil {
+			allowed = append(allowed, m)
+		}
+	}
+	return allowed
+}
+
+// response returns the status code and response the operation answers
+// with. The response is nil if the operation has none.
+func (s *Server) response(op *spec3.Operation) (int, *spec3.Response) {
+	if op.Responses == nil {
+		return http.StatusOK, nil
+	}
+	codes := make([]int, 0, len(op.Responses.StatusCodeResponses))
+	for code := range op.Responses.StatusCodeResponses {
+		codes = append(codes, code)
+	}
+	sort.Ints(codes)
+	for _, code := range codes {
+		if code >= 200 && code < 300 {
+			return code, s.resolveResponse(op.Responses.StatusCodeResponses[code])
+		}
+	}
+	if op.Responses.Default != nil {
+		resp := s.resolveResponse(op.Responses.Default)
+		if resp != nil && describesError(resp.Description) {
+			return http.StatusInternalServerError, resp
+		}
+		return http.StatusOK, resp
+	}
+	if len(codes) > 0 {
+		return codes[0], s.resolveResponse(op.Responses.StatusCodeResponses[codes[0]])
+	}
+	return http.StatusOK, nil
+}
+
+// describesError returns whether the description of a default response
+// says it is served on errors, e.g. "unexpected error".
+func describesError(description string) bool {
+	description = strings.ToLower(description)
+	return strings.Contains(description, "error") || strings.Contains(description, "fail")
+}
+
+// checkReferences checks that the response and example references of the
+// operations of path resolve.
+func (s *Server) checkReferences(path *spec3.Path) error {
+	for _, m := range methods {
+		op := operation(path, m)
+		if op == nil || op.Responses == nil {
+			continue
+		}
+		responses := make(map[string]*spec3.Response, len(op.Responses.StatusCodeResponses)+1)
+		for code, resp := range op.Responses.StatusCodeResponses {
+			responses[fmt.Sprint(code)] = resp
+		}
+		if op.Responses.Default != nil {
+			responses["default"] = op.Responses.Default
+		}
+		for name, resp := range responses {
+			if resp == nil {
+				continue
+			}
+			resolved := s.resolveResponse(resp)
+			if resolved == nil {
+				return fmt.Errorf("%s: response %s: unresolved reference %q", m, name, resp.Ref.String())
+			}
+			for mediaType, content := range resolved.Content {
+				if content == nil {
+					continue
+				}
+				for exampleName, example := range content.Examples {
+					if example != nil && s.resolveExample(example) == nil {
+						return fmt.Errorf("%s: response %s: %s: example %s: unresolved reference %q", m, name, mediaType, exampleName, example.Ref.String())
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Server) resolveResponse(resp *spec3.Response) *spec3.Response {
+	if resp == nil {
+		return nil
+	}
+	if r := resp.Ref.String(); r != "" {
+		if s.doc.Components == nil || !strings.HasPrefix(r, responseRefPrefix) {
+			return nil
+		}
+		return s.doc.Components.Responses[strings.TrimPrefix(r, responseRefPrefix)]
+	}
+	return resp
+}
+
+// pickContent returns the media type the response is served as, and its
+// content.
+func pickContent(content map[string]*spec3.MediaType) (string, *spec3.MediaType) {
+	if c, ok := content["application/json"]; ok && c != nil {
+		return "application/json", c
+	}
+	mediaTypes := make([]string, 0, len(content))
+	for mediaType, c := range content {
+		if c != nil {
+			mediaTypes = append(mediaTypes, mediaType)
+		}
+	}
+	if len(mediaTypes) == 0 {
+		return "", nil
+	}
+	sort.Strings(mediaTypes)
+	return mediaTypes[0], content[mediaTypes[0]]
+}
+
+// body returns the value served for content.
+func (s *Server) body(content *spec3.MediaType) interface{} {
+	if content.Example != nil {
+		return content.Example
+	}
+	names := make([]string, 0, len(content.Examples))
+	for name := range content.Examples {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if example := s.resolveExample(content.Examples[name]); example != nil && example.Value != nil {
+			return example.Value
+		}
+	}
+	var schemas map[string]*spec.Schema
+	if s.doc.Components != nil {
+		schemas = s.doc.Components.Schemas
+	}
+	return ExampleValue(content.Schema, schemas)
+}
+
+func (s *Server) resolveExample(example *spec3.Example) *spec3.Example {
+	if example == nil {
+		return nil
+	}
+	if r := example.Ref.String(); r != "" {
+		if s.doc.Components == nil || !strings.HasPrefix(r, exampleRefPrefix) {
+			return nil
+		}
+		return s.doc.Components.Examples[strings.TrimPrefix(r, exampleRefPrefix)]
+	}
+	return example
+}
@@ -0,0 +1,208 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mockserver
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/kube-openapi/pkg/spec3"
+)
+
+const testDocument = `{
+  "openapi": "3.0.0",
+  "info": {"title": "test", "version": "v1"},
+  "paths": {
+    "/apis/example.com/v1/widgets": {
+      "get": {
+        "responses": {
+          "200": {
+            "description": "OK",
+            "content": {
+              "application/json": {"schema": {"$ref": "#/components/schemas/WidgetList"}},
+              "application/yaml": {"schema": {"$ref": "#/components/schemas/WidgetList"}}
+            }
+          },
+          "401": {"description": "Unauthorized"}
+        }
+      },
+      "post": {
+        "responses": {
+          "201": {"$ref": "#/components/responses/Created"}
+        }
+      }
+    },
+    "/apis/example.com/v1/widgets/{name}": {
+      "get": {
+        "responses": {
+          "default": {
+            "description": "OK",
+            "content": {
+              "application/json": {
+                "examples": {
+                  "b": {"value": {"kind": "Widget", "name": "b"}},
+                  "a": {"$ref": "#/components/examples/Widget"}
+                }
+              }
+            }
+          }
+        }
+      },
+      "delete": {
+        "responses": {"200": {"description": "OK"}}
+      }
+    },
+    "/apis/example.com/v1/widgets/{name}/scale": {
+      "get": {
+        "responses": {
+          "default": {"description": "unexpected error", "content": {"application/json": {"example": {"kind": "Status"}}}}
+        }
+      }
+    },
+    "/apis/example.com/v1/widgets/special": {
+      "get": {
+        "responses": {
+          "200": {
+            "description": "OK",
+            "content": {"application/json": {"example": {"special": true}}}
+          }
+        }
+      }
+    }
+  },
+  "components": {
+    "schemas": {
+      "Widget": {
+        "type": "object",
+        "properties": {
+          "kind": {"type": "string", "enum": ["Widget"]},
+          "size": {"type": "integer", "minimum": 1},
+          "created": {"type": "string", "format": "date-time"},
+          "owner": {"allOf": [{"$ref": "#/components/schemas/Widget"}]},
+          "tags": {"type": "array", "items": {"type": "string", "minLength": 2}},
+          "port": {"x-kubernetes-int-or-string": true}
+        }
+      },
+      "WidgetList": {
+        "type": "object",
+        "properties": {
+          "items": {"type": "array", "items": {"$ref": "#/components/schemas/Widget"}}
+        }
+      }
+    },
+    "responses": {
+      "Created": {
+        "description": "Created",
+        "content": {"application/json": {"example": {"created": true}}}
+      }
+    },
+    "examples": {
+      "Widget": {"value": {"kind": "Widget", "name": "a"}}
+    }
+  }
+}`
+
+func TestServer(t *testing.T) {
+	doc := &spec3.OpenAPI{}
+	require.NoError(t, json.Unmarshal([]byte(testDocument), doc))
+	s, err := New(doc)
+	require.NoError(t, err)
+	server := httptest.NewServer(s)
+	defer server.Close()
+
+	for _, tc := range []struct {
+		method string
+		path   string
+		code   int
+		body   string
+	}{
+		{
+			method: "GET",
+			path:   "/apis/example.com/v1/widgets",
+			code:   http.StatusOK,
+			body:   `{"items": [{"kind": "Widget", "size": 1, "created": "1970-01-01T00:00:00Z", "tags": ["xx"], "port": 0}]}`,
+		},
+		{method: "POST", path: "/apis/example.com/v1/widgets", code: http.StatusCreated, body: `{"created": true}`},
+		{method: "GET", path: "/apis/example.com/v1/widgets/foo", code: http.StatusOK, body: `{"kind": "Widget", "name": "a"}`},
+		{method: "DELETE", path: "/apis/example.com/v1/widgets/foo", code: http.StatusOK},
+		{method: "GET", path: "/apis/example.com/v1/widgets/special", code: http.StatusOK, body: `{"special": true}`},
+		{method: "GET", path: "/apis/example.com/v1/widgets/foo/scale", code: http.StatusInternalServerError, body: `{"kind": "Status"}`},
+		{method: "PUT", path: "/apis/example.com/v1/widgets/foo", code: http.StatusMethodNotAllowed},
+		{method: "GET", path: "/apis/example.com/v1/widgets/foo/status", code: http.StatusNotFound},
+	} {
+		t.Run(tc.method+" "+tc.path, func(t *testing.T) {
+			req, err := http.NewRequest(tc.method, server.URL+tc.path, nil)
+			require.NoError(t, err)
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.code, resp.StatusCode)
+			if tc.body != "" {
+				assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+				assert.JSONEq(t, tc.body, string(body))
+			}
+			if tc.code == http.StatusMethodNotAllowed {
+				assert.Equal(t, "GET, DELETE", resp.Header.Get("Allow"))
+			}
+		})
+	}
+}
+
+func TestNewUnresolvedReferences(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		responses string
+		err       string
+	}{
+		{
+			name:      "missing response",
+			responses: `{"200": {"$ref": "#/components/responses/Missing"}}`,
+			err:       `path "/widgets": GET: response 200: unresolved reference "#/components/responses/Missing"`,
+		},
+		{
+			name:      "response of another kind",
+			responses: `{"default": {"$ref": "#/components/schemas/Widget"}}`,
+			err:       `path "/widgets": GET: response default: unresolved reference "#/components/schemas/Widget"`,
+		},
+		{
+			name:      "missing example",
+			responses: `{"200": {"description": "OK", "content": {"application/json": {"examples": {"a": {"$ref": "#/components/examples/Missing"}}}}}}`,
+			err:       `path "/widgets": GET: response 200: application/json: example a: unresolved reference "#/components/examples/Missing"`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			doc := &spec3.OpenAPI{}
+			require.NoError(t, json.Unmarshal([]byte(`{
+  "openapi": "3.0.0",
+  "info": {"title": "test", "version": "v1"},
+  "paths": {"/widgets": {"get": {"responses": `+tc.responses+`}}},
+  "components": {"schemas": {"Widget": {"type": "object"}}}
+}`), doc))
+			_, err := New(doc)
+			assert.EqualError(t, err, tc.err)
+		})
+	}
+}
@@ -220,19 +220,7 @@ func ConvertOperation(v2Operation *spec.Operation) *spec3.Operation {
 
 	for _, param := range v2Operation.Parameters {
 		if param.ParamProps.Name == "body" && param.ParamProps.Schema != nil {
-			operation.OperationProps.RequestBody = &spec3.RequestBody{
-				RequestBodyProps: spec3.RequestBodyProps{},
-			}
-			if v2Operation.Consumes != nil {
-				operation.RequestBody.Content = make(map[string]*spec3.MediaType)
-			}
-			for _, consumer := range v2Operation.Consumes {
-				operation.RequestBody.Content[consumer] = &spec3.MediaType{
-					MediaTypeProps: spec3.MediaTypeProps{
-						Schema: ConvertSchema(param.ParamProps.Schema),
-					},
-				}
-			}
+			operation.RequestBody = spec3.NewRequestBody(ConvertSchema(param.ParamProps.Schema), false, v2Operation.Consumes...)
 		} else {
 			operation.Parameters = append(operation.Parameters, ConvertParameter(param))
 		}
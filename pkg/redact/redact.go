@@ -0,0 +1,283 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package redact masks or removes sensitive values, such as credentials and
+// tokens, from the defaults and examples of OpenAPI v2 and v3 documents
+// before they are published.
+package redact
+
+import (
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"k8s.io/kube-openapi/pkg/spec3"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+// Redactor redacts the default and example values of documents. A value is
+// sensitive if it is a string matching one of ValuePatterns, or if it is
+// named after one of NamePatterns: the defaults and examples of properties,
+// parameters and headers with a matching name, and the entries of objects
+// with a matching key.
+type Redactor struct {
+	// ValuePatterns match sensitive string values, e.g. `^AKIA[0-9A-Z]{16}$`.
+	ValuePatterns []*regexp.Regexp
+	// NamePatterns match the names of sensitive values, e.g. `(?i)password`.
+	NamePatterns []*regexp.Regexp
+	// Mask replaces the sensitive values. If empty, the whole default or
+	// example holding a sensitive value is removed instead.
+	Mask string
+}
+
+// Swagger returns a copy of sw with its sensitive defaults and examples
+// redacted, and the sorted JSON pointers to the redacted values. sw is not
+// modified.
+func (r *Redactor) Swagger(sw *spec.Swagger) (*spec.Swagger, []string, error) {
+	var redacted *spec.Swagger
+	report, err := r.redact(sw, &redacted, false)
+	return redacted, report, err
+}
+
+// OpenAPI returns a copy of o with its sensitive defaults and examples
+// redacted, and the sorted JSON pointers to the redacted values. o is not
+// modified.
+func (r *Redactor) OpenAPI(o *spec3.OpenAPI) (*spec3.OpenAPI, []string, error) {
+	var redacted *spec3.OpenAPI
+	report, err := r.redact(o, &redacted, true)
+	return redacted, report, err
+}
+
+// redact redacts the JSON form of in into out.
+func (r *Redactor) redact(in, out interface{}, v3 bool) ([]string, error) {
+	data, err := json.Marshal(in)
+	if err != nil {
+		return nil, err
+	}
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	w := walker{Redactor: r, v3: v3}
+	w.object(doc, "")
+	if data, err = json.Marshal(doc); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return nil, err
+	}
+	sort.Strings(w.report)
+	return w.report, nil
+}
+
+// valueKeywords are the keywords holding default or example values.
+var valueKeywords = map[string]bool{
+	"default":   true,
+	"example":   true,
+	"x-example": true,
+}
+
+// namedMaps are the keywords holding maps keyed by names rather than by
+// keywords, whose entries are objects of the specification.
+var namedMaps = map[string]bool{
+	"properties":        true,
+	"patternProperties": true,
+	"definitions":       true,
+	"dependencies":      true,
+	"paths":             true,
+	"parameters":        true,
+	"responses":         true,
+	"headers":           true,
+	"content":           true,
+	"encoding":          true,
+	"links":             true,
+	"callbacks":         true,
+	"schemas":           true,
+	"requestBodies":     true,
+	"securitySchemes":   true,
+	"variables":         true,
+	"scopes":            true,
+}
+
+type walker struct {
+	*Redactor
+	v3     bool
+	report []string
+}
+
+// object walks an object of the specification at path.
+func (w *walker) object(node interface{}, path string) {
+	switch n := node.(type) {
+	case map[string]interface{}:
+		w.fields(n, path, false)
+	case []interface{}:
+		for i, item := range n {
+			w.object(item, path+"/"+strconv.Itoa(i))
+		}
+	}
+}
+
+// named walks an object of the specification named name, e.g. a property.
+func (w *walker) named(node interface{}, name, path string) {
+	if n, ok := node.(map[string]interface{}); ok {
+		w.fields(n, path, w.sensitiveName(name))
+		return
+	}
+	w.object(node, path)
+}
+
+// fields walks the fields of an object of the specification. If sensitive,
+// or if the object is a parameter with a sensitive name, its defaults and
+// examples are redacted as a whole.
+func (w *walker) fields(n map[string]interface{}, path string, sensitive bool) {
+	if name, ok := n["name"].(string); ok && w.sensitiveName(name) {
+		sensitive = true
+	}
+	for _, key := range sortedKeys(n) {
+		child := n[key]
+		childPath := path + "/" + escape(key)
+		switch {
+		case valueKeywords[key]:
+			w.value(n, key, childPath, sensitive)
+		case key == "examples":
+			if _, ok := child.(map[string]interface{}); ok {
+				w.examples(child, childPath, sensitive)
+			} else {
+				w.value(n, key, childPath, sensitive)
+			}
+		case strings.HasPrefix(key, "x-"), key == "enum":
+			// vendor extensions and enums are data
+		case namedMaps[key]:
+			if m, ok := child.(map[string]interface{}); ok {
+				for _, name := range sortedKeys(m) {
+					w.named(m[name], name, childPath+"/"+escape(name))
+				}
+			} else {
+				w.object(child, childPath)
+			}
+		default:
+			w.object(child, childPath)
+		}
+	}
+}
+
+// examples walks the examples of a media type, parameter or header in v3,
+// whose values are in example objects, or of a response in v2, keyed by
+// media type.
+func (w *walker) examples(node interface{}, path string, sensitive bool) {
+	m, ok := node.(map[string]interface{})
+	if !ok {
+		return
+	}
+	for _, name := range sortedKeys(m) {
+		if !w.v3 {
+			w.value(m, name, path+"/"+escape(name), sensitive)
+			continue
+		}
+		if example, ok := m[name].(map[string]interface{}); ok {
+			w.value(example, "value", path+"/"+escape(name)+"/value", sensitive)
+		}
+	}
+}
+
+// value redacts parent[key], a default or example value at path.
+func (w *walker) value(parent map[string]interface{}, key, path string, sensitive bool) {
+	v, ok := parent[key]
+	if !ok {
+		return
+	}
+	if sensitive {
+		w.report = append(w.report, path)
+		if w.Mask == "" {
+			delete(parent, key)
+		} else {
+			parent[key] = w.Mask
+		}
+		return
+	}
+	if w.Mask == "" {
+		var found []string
+		w.mask(v, path, &found)
+		if len(found) > 0 {
+			w.report = append(w.report, path)
+			delete(parent, key)
+		}
+		return
+	}
+	parent[key] = w.mask(v, path, &w.report)
+}
+
+// mask returns v with its sensitive parts replaced by the mask, and appends
+// their paths to found.
+func (w *walker) mask(v interface{}, path string, found *[]string) interface{} {
+	switch v := v.(type) {
+	case string:
+		if w.sensitiveValue(v) {
+			*found = append(*found, path)
+			return w.Mask
+		}
+	case map[string]interface{}:
+		for _, k := range sortedKeys(v) {
+			p := path + "/" + escape(k)
+			if w.sensitiveName(k) {
+				*found = append(*found, p)
+				v[k] = w.Mask
+				continue
+			}
+			v[k] = w.mask(v[k], p, found)
+		}
+	case []interface{}:
+		for i := range v {
+			v[i] = w.mask(v[i], path+"/"+strconv.Itoa(i), found)
+		}
+	}
+	return v
+}
+
+func (r *Redactor) sensitiveValue(s string) bool {
+	for _, p := range r.ValuePatterns {
+		if p.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Redactor) sensitiveName(name string) bool {
+	for _, p := range r.NamePatterns {
+		if p.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// escape escapes a JSON pointer token, see RFC 6901.
+func escape(token string) string {
+	token = strings.Replace(token, "~", "~0", -1)
+	return strings.Replace(token, "/", "~1", -1)
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"math"
 	"math/rand"
+	"os"
 	"reflect"
 	"regexp"
 	"strings"
@@ -389,6 +390,171 @@ func TestReplaceSchema(t *testing.T) {
 	}
 }
 
+func TestCloneTransform(t *testing.T) {
+	orig := &spec.Swagger{SwaggerProps: spec.SwaggerProps{
+		Definitions: spec.Definitions{
+			"changed":   *spec.StringProperty().WithDescription("original"),
+			"unchanged": *spec.StringProperty().WithDescription("kept"),
+			"nested": *new(spec.Schema).
+				SetProperty("a", *spec.StringProperty().WithDescription("original")).
+				SetProperty("b", *spec.Int64Property()),
+		},
+	}}
+	before, err := json.Marshal(orig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := CloneTransform(orig, func(s *spec.Schema) bool {
+		if s.Description != "original" {
+			return false
+		}
+		s.Description = "modified"
+		return true
+	})
+
+	after, err := json.Marshal(orig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(before) != string(after) {
+		t.Errorf("original was mutated: %s", stringDiff(string(before), string(after)))
+	}
+	if got == orig {
+		t.Fatalf("expected a copy of the changed document")
+	}
+	if d := got.Definitions["changed"].Description; d != "modified" {
+		t.Errorf("expected changed definition to be transformed, got %q", d)
+	}
+	if d := got.Definitions["nested"].Properties["a"].Description; d != "modified" {
+		t.Errorf("expected nested property to be transformed, got %q", d)
+	}
+	if d := got.Definitions["unchanged"].Description; d != "kept" {
+		t.Errorf("expected unchanged definition to be kept, got %q", d)
+	}
+
+	if CloneTransform(orig, func(*spec.Schema) bool { return false }) != orig {
+		t.Errorf("expected the original to be returned when nothing changed")
+	}
+}
+
+func TestCloneTransformParentAndChild(t *testing.T) {
+	orig := &spec.Swagger{SwaggerProps: spec.SwaggerProps{
+		Definitions: spec.Definitions{
+			"parent": {SchemaProps: spec.SchemaProps{
+				Description: "parent",
+				Properties: map[string]spec.Schema{
+					"a": *spec.StringProperty().WithDescription("original"),
+				},
+				AllOf: []spec.Schema{*spec.StringProperty().WithDescription("original")},
+			}},
+		},
+	}}
+
+	got := CloneTransform(orig, func(s *spec.Schema) bool {
+		switch s.Description {
+		case "parent":
+			// the maps and slices are shared with orig and replaced
+			properties := map[string]spec.Schema{"added": *spec.Int64Property()}
+			for k, v := range s.Properties {
+				properties[k] = v
+			}
+			s.Properties = properties
+			s.AllOf = append([]spec.Schema{*spec.BoolProperty()}, s.AllOf...)
+			return true
+		case "original":
+			s.Description = "modified"
+			return true
+		}
+		return false
+	})
+
+	parent := got.Definitions["parent"]
+	if len(parent.Properties) != 2 {
+		t.Errorf("expected the added and the transformed property, got %v", parent.Properties)
+	}
+	if _, ok := parent.Properties["added"]; !ok {
+		t.Errorf("expected the property added by the transform to be kept")
+	}
+	if d := parent.Properties["a"].Description; d != "modified" {
+		t.Errorf("expected the nested property to be transformed, got %q", d)
+	}
+	if len(parent.AllOf) != 2 || !parent.AllOf[0].Type.Contains("boolean") || parent.AllOf[1].Description != "modified" {
+		t.Errorf("expected the prepended and the transformed allOf schemas, got %v", parent.AllOf)
+	}
+	if orig := orig.Definitions["parent"]; len(orig.Properties) != 1 || len(orig.AllOf) != 1 || orig.Properties["a"].Description != "original" {
+		t.Errorf("original was mutated: %v", orig)
+	}
+}
+
+func TestRefCacheInvalidatedByWalker(t *testing.T) {
+	orig := &spec.Swagger{SwaggerProps: spec.SwaggerProps{
+		Definitions: spec.Definitions{
+			"changed": *spec.StringProperty().WithDescription("original"),
+		},
+	}}
+	ref := spec.MustCreateRef("#/definitions/changed")
+	c := &spec.RefCache{}
+	if s, err := c.ResolveRef(orig, &ref); err != nil || s.Description != "original" {
+		t.Fatalf("unexpected resolution: %v, %v", s, err)
+	}
+
+	got := CloneTransform(orig, func(s *spec.Schema) bool {
+		s.Description = "modified"
+		return true
+	})
+	if s, err := c.ResolveRef(got, &ref); err != nil || s.Description != "modified" {
+		t.Errorf("expected the resolution against the walked document to see the change, got %v, %v", s, err)
+	}
+	if s, err := c.ResolveRef(orig, &ref); err != nil || s.Description != "original" {
+		t.Errorf("expected the original to be unchanged, got %v, %v", s, err)
+	}
+}
+
+func loadKubernetesSwagger(b *testing.B) *spec.Swagger {
+	bs, err := os.ReadFile("../schemaconv/testdata/swagger.json")
+	if err != nil {
+		b.Fatal(err)
+	}
+	s := &spec.Swagger{}
+	if err := json.Unmarshal(bs, s); err != nil {
+		b.Fatal(err)
+	}
+	return s
+}
+
+func stripDescription(s *spec.Schema) bool {
+	if s.Description == "" {
+		return false
+	}
+	s.Description = ""
+	return true
+}
+
+func BenchmarkCloneTransform(b *testing.B) {
+	sw := loadKubernetesSwagger(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		CloneTransform(sw, stripDescription)
+	}
+}
+
+func BenchmarkCloneThenWalk(b *testing.B) {
+	sw := loadKubernetesSwagger(b)
+	w := &Walker{SchemaCallback: func(schema *spec.Schema) *spec.Schema {
+		stripDescription(schema)
+		return schema
+	}, RefCallback: RefCallbackNoop}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c, err := cloneSwagger(sw)
+		if err != nil {
+			b.Fatal(err)
+		}
+		w.WalkRoot(c)
+	}
+}
+
 func cloneSwagger(orig *spec.Swagger) (*spec.Swagger, error) {
 	bs, err := json.Marshal(orig)
 	if err != nil {
@@ -16,7 +16,13 @@ limitations under the License.
 
 package spec3
 
-import "k8s.io/kube-openapi/pkg/validation/spec"
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
 
 // Components holds a set of reusable objects for different aspects of the OAS.
 // All objects defined within the components object will have no effect on the API
@@ -40,8 +46,80 @@ type Components struct {
 	Links map[string]*Link `json:"links,omitempty"`
 	// Headers holds a maps of a headers name to its definition
 	Headers map[string]*Header `json:"headers,omitempty"`
+	// PathItems holds reusable Path Item objects, only defined by OpenAPI 3.1
+	PathItems map[string]*Path `json:"pathItems,omitempty"`
 	// all fields are defined at https://github.com/OAI/OpenAPI-Specification/blob/master/versions/3.0.0.md#componentsObject
 }
 
 // SecuritySchemes holds reusable Security Scheme Objects, more at https://github.com/OAI/OpenAPI-Specification/blob/master/versions/3.0.0.md#securitySchemeObject
 type SecuritySchemes map[string]*SecurityScheme
+
+// componentKeyRegexp matches the keys allowed in the maps of a Components
+// object, more at https://github.com/OAI/OpenAPI-Specification/blob/master/versions/3.0.0.md#fixed-fields-5
+var componentKeyRegexp = regexp.MustCompile(`^[a-zA-Z0-9.\-_]+$`)
+
+// SchemaRef returns the reference to the schema component with the given name.
+func SchemaRef(name string) string {
+	return componentRef("schemas", name)
+}
+
+// ParameterRef returns the reference to the parameter component with the given name.
+func ParameterRef(name string) string {
+	return componentRef("parameters", name)
+}
+
+// ResponseRef returns the reference to the response component with the given name.
+func ResponseRef(name string) string {
+	return componentRef("responses", name)
+}
+
+func componentRef(kind, name string) string {
+	// Escaping reference name using rfc6901
+	name = strings.Replace(name, "~", "~0", -1)
+	name = strings.Replace(name, "/", "~1", -1)
+	return "#/components/" + kind + "/" + name
+}
+
+// AddSchema adds schema to the schemas of c under name and returns the
+// reference to it. It fails if name is not a valid component key or
+// already holds a different schema.
+func (c *Components) AddSchema(name string, schema *spec.Schema) (string, error) {
+	if err := addComponent(&c.Schemas, "schema", name, schema); err != nil {
+		return "", err
+	}
+	return SchemaRef(name), nil
+}
+
+// AddParameter adds parameter to the parameters of c under name and returns
+// the reference to it. It fails if name is not a valid component key or
+// already holds a different parameter.
+func (c *Components) AddParameter(name string, parameter *Parameter) (string, error) {
+	if err := addComponent(&c.Parameters, "parameter", name, parameter); err != nil {
+		return "", err
+	}
+	return ParameterRef(name), nil
+}
+
+// AddResponse adds response to the responses of c under name and returns
+// the reference to it. It fails if name is not a valid component key or
+// already holds a different response.
+func (c *Components) AddResponse(name string, response *Response) (string, error) {
+	if err := addComponent(&c.Responses, "response", name, response); err != nil {
+		return "", err
+	}
+	return ResponseRef(name), nil
+}
+
+func addComponent[T any](components *map[string]*T, kind, name string, v *T) error {
+	if !componentKeyRegexp.MatchString(name) {
+		return fmt.Errorf("invalid %s name %q: must match %s", kind, name, componentKeyRegexp)
+	}
+	if existing, ok := (*components)[name]; ok && existing != v {
+		return fmt.Errorf("%s %q already exists", kind, name)
+	}
+	if *components == nil {
+		*components = map[string]*T{}
+	}
+	(*components)[name] = v
+	return nil
+}
@@ -173,3 +173,56 @@ func TestSchemasJSONSerialization(t *testing.T) {
 		})
 	}
 }
+
+func TestComponentsAdd(t *testing.T) {
+	c := &spec3.Components{}
+
+	schema := &spec.Schema{SchemaProps: spec.SchemaProps{Type: []string{"object"}}}
+	ref, err := c.AddSchema("io.k8s.api.core.v1.Pod", schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ref != "#/components/schemas/io.k8s.api.core.v1.Pod" {
+		t.Errorf("unexpected schema ref %q", ref)
+	}
+	if c.Schemas["io.k8s.api.core.v1.Pod"] != schema {
+		t.Errorf("schema was not added")
+	}
+	if _, err := c.AddSchema("io.k8s.api.core.v1.Pod", schema); err != nil {
+		t.Errorf("re-adding the same schema should succeed, got %v", err)
+	}
+	if _, err := c.AddSchema("io.k8s.api.core.v1.Pod", &spec.Schema{}); err == nil {
+		t.Errorf("expected an error when replacing a schema")
+	}
+
+	ref, err = c.AddParameter("pretty", &spec3.Parameter{ParameterProps: spec3.ParameterProps{Name: "pretty", In: "query"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ref != "#/components/parameters/pretty" {
+		t.Errorf("unexpected parameter ref %q", ref)
+	}
+
+	ref, err = c.AddResponse("NotFound", &spec3.Response{ResponseProps: spec3.ResponseProps{Description: "not found"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ref != "#/components/responses/NotFound" {
+		t.Errorf("unexpected response ref %q", ref)
+	}
+
+	for _, name := range []string{"", "a/b", "a b", "a~b"} {
+		if _, err := c.AddSchema(name, schema); err == nil {
+			t.Errorf("expected an error for schema name %q", name)
+		}
+	}
+	if len(c.Schemas) != 1 {
+		t.Errorf("invalid names should not be added, got %v", c.Schemas)
+	}
+}
+
+func TestSchemaRefEscaping(t *testing.T) {
+	if ref := spec3.SchemaRef("a/b~c"); ref != "#/components/schemas/a~1b~0c" {
+		t.Errorf("unexpected ref %q", ref)
+	}
+}
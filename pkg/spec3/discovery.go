@@ -0,0 +1,79 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec3
+
+import (
+	"crypto/sha512"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+)
+
+// Discovery is the format of the /openapi/v3 discovery document. It maps
+// group-version paths, e.g. "apis/apps/v1", to the URLs of their documents.
+type Discovery struct {
+	Paths map[string]DiscoveryGroupVersion `json:"paths"`
+}
+
+// DiscoveryGroupVersion holds the URL of the document of a group-version.
+type DiscoveryGroupVersion struct {
+	// ServerRelativeURL is the absolute path of the document, with its hash
+	// as query parameter to support client side caching, e.g.
+	// /openapi/v3/apis/apps/v1?hash=014FBFF9A07C
+	ServerRelativeURL string `json:"serverRelativeURL"`
+}
+
+// DiscoveryHash returns the hash of a serialized document, as used in the
+// discovery URLs and ETags of /openapi/v3.
+func DiscoveryHash(data []byte) string {
+	return fmt.Sprintf("%X", sha512.Sum512(data))
+}
+
+// DiscoveryURL returns the server relative URL of the document of a
+// group-version with the given hash.
+func DiscoveryURL(groupVersion, hash string) string {
+	u := url.URL{Path: path.Join("/openapi/v3", groupVersion)}
+	query := url.Values{}
+	query.Set("hash", hash)
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// NewDiscovery returns the discovery document of the group-versions whose
+// documents have the given hashes.
+func NewDiscovery(hashes map[string]string) *Discovery {
+	d := &Discovery{Paths: make(map[string]DiscoveryGroupVersion, len(hashes))}
+	for gv, hash := range hashes {
+		d.Paths[gv] = DiscoveryGroupVersion{ServerRelativeURL: DiscoveryURL(gv, hash)}
+	}
+	return d
+}
+
+// BuildDiscoveryDocument returns the discovery document of the given
+// documents by group-version, hashing their JSON serializations.
+func BuildDiscoveryDocument(documents map[string]*OpenAPI) (*Discovery, error) {
+	hashes := make(map[string]string, len(documents))
+	for gv, document := range documents {
+		data, err := json.Marshal(document)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize %s: %w", gv, err)
+		}
+		hashes[gv] = DiscoveryHash(data)
+	}
+	return NewDiscovery(hashes), nil
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec3_test
+
+import (
+	"crypto/sha512"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/kube-openapi/pkg/spec3"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+func TestBuildDiscoveryDocument(t *testing.T) {
+	apps := &spec3.OpenAPI{Version: "3.0.0", Info: &spec.Info{InfoProps: spec.InfoProps{Title: "apps", Version: "v1"}}}
+	core := &spec3.OpenAPI{Version: "3.0.0", Info: &spec.Info{InfoProps: spec.InfoProps{Title: "core", Version: "v1"}}}
+	hash := func(o *spec3.OpenAPI) string {
+		data, err := json.Marshal(o)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return fmt.Sprintf("%X", sha512.Sum512(data))
+	}
+
+	discovery, err := spec3.BuildDiscoveryDocument(map[string]*spec3.OpenAPI{
+		"apis/apps/v1": apps,
+		"api/v1":       core,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &spec3.Discovery{Paths: map[string]spec3.DiscoveryGroupVersion{
+		"apis/apps/v1": {ServerRelativeURL: "/openapi/v3/apis/apps/v1?hash=" + hash(apps)},
+		"api/v1":       {ServerRelativeURL: "/openapi/v3/api/v1?hash=" + hash(core)},
+	}}
+	if !cmp.Equal(discovery, expected) {
+		t.Errorf("unexpected discovery document: %s", cmp.Diff(expected, discovery))
+	}
+
+	data, err := json.Marshal(discovery)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedJSON := `{"paths":{"api/v1":{"serverRelativeURL":"/openapi/v3/api/v1?hash=` + hash(core) + `"},` +
+		`"apis/apps/v1":{"serverRelativeURL":"/openapi/v3/apis/apps/v1?hash=` + hash(apps) + `"}}}`
+	if string(data) != expectedJSON {
+		t.Errorf("expected %s, got %s", expectedJSON, data)
+	}
+
+	empty, err := spec3.BuildDiscoveryDocument(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, err := json.Marshal(empty); err != nil {
+		t.Fatal(err)
+	} else if string(data) != `{"paths":{}}` {
+		t.Errorf("expected an empty paths map, got %s", data)
+	}
+}
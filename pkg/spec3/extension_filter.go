@@ -0,0 +1,358 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec3
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+// ExtensionFilter drops the vendor extensions whose names match one of its
+// patterns when marshaling documents, so that a document holding internal
+// extensions can also be published without them. Patterns use the syntax of
+// path.Match, e.g. "x-internal-*".
+type ExtensionFilter struct {
+	patterns []string
+}
+
+// NewExtensionFilter returns a filter for the given patterns, or an error if
+// one of them is malformed.
+func NewExtensionFilter(patterns ...string) (*ExtensionFilter, error) {
+	for _, p := range patterns {
+		if _, err := path.Match(p, ""); err != nil {
+			return nil, fmt.Errorf("invalid extension pattern %q: %v", p, err)
+		}
+	}
+	return &ExtensionFilter{patterns: append([]string(nil), patterns...)}, nil
+}
+
+// Matches returns true if the extension name matches one of the patterns of f.
+func (f *ExtensionFilter) Matches(name string) bool {
+	for _, p := range f.patterns {
+		if matched, _ := path.Match(p, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// Marshal returns the JSON encoding of o without the extensions matched by
+// f. o is not modified.
+func (f *ExtensionFilter) Marshal(o *OpenAPI) ([]byte, error) {
+	data, err := json.Marshal(o)
+	if err != nil || !f.mayMatch(data) {
+		return data, err
+	}
+
+	// work on a copy, which the in-memory document shares nothing with
+	var filtered OpenAPI
+	if err := json.Unmarshal(data, &filtered); err != nil {
+		return nil, err
+	}
+	visitExtensions(&filtered, func(e *spec.VendorExtensible) {
+		for name := range e.Extensions {
+			if f.Matches(name) {
+				delete(e.Extensions, name)
+			}
+		}
+		if len(e.Extensions) == 0 {
+			e.Extensions = nil
+		}
+	})
+	return json.Marshal(&filtered)
+}
+
+// mayMatch returns false if data cannot hold any key matched by f, as checked
+// by looking for the literal prefixes of the patterns.
+func (f *ExtensionFilter) mayMatch(data []byte) bool {
+	for _, p := range f.patterns {
+		prefix := p
+		if i := strings.IndexAny(p, `*?[\`); i >= 0 {
+			prefix = p[:i]
+		}
+		if bytes.Contains(data, []byte(`"`+prefix)) {
+			return true
+		}
+	}
+	return false
+}
+
+// visitExtensions calls fn on the vendor extensions of every object of o that
+// can have some.
+func visitExtensions(o *OpenAPI, fn func(*spec.VendorExtensible)) {
+	v := extensionVisitor{fn: fn, schemas: map[*spec.Schema]bool{}}
+	v.document(o)
+}
+
+// visitSchemas calls fn on every schema of o, once per schema shared by
+// several parts of o.
+func visitSchemas(o *OpenAPI, fn func(*spec.Schema)) {
+	v := extensionVisitor{fn: func(*spec.VendorExtensible) {}, schemaFn: fn, schemas: map[*spec.Schema]bool{}}
+	v.document(o)
+}
+
+type extensionVisitor struct {
+	fn func(*spec.VendorExtensible)
+	// schemaFn, if not nil, is called on every schema.
+	schemaFn func(*spec.Schema)
+	// schemas holds the schemas already visited, which may be shared by
+	// several parts of the document.
+	schemas map[*spec.Schema]bool
+}
+
+func (v *extensionVisitor) document(o *OpenAPI) {
+	if o.Info != nil {
+		v.fn(&o.Info.VendorExtensible)
+	}
+	v.servers(o.Servers)
+	if o.Paths != nil {
+		v.fn(&o.Paths.VendorExtensible)
+		for _, p := range o.Paths.Paths {
+			v.path(p)
+		}
+	}
+	for _, p := range o.Webhooks {
+		v.path(p)
+	}
+	if c := o.Components; c != nil {
+		for _, s := range c.Schemas {
+			v.schema(s)
+		}
+		for _, s := range c.SecuritySchemes {
+			v.securityScheme(s)
+		}
+		for _, r := range c.Responses {
+			v.response(r)
+		}
+		for _, p := range c.Parameters {
+			v.parameter(p)
+		}
+		v.examples(c.Examples)
+		for _, b := range c.RequestBodies {
+			v.requestBody(b)
+		}
+		v.links(c.Links)
+		v.headers(c.Headers)
+		for _, p := range c.PathItems {
+			v.path(p)
+		}
+	}
+	v.externalDocs(o.ExternalDocs)
+}
+
+func (v *extensionVisitor) path(p *Path) {
+	if p == nil {
+		return
+	}
+	v.fn(&p.VendorExtensible)
+	for _, op := range []*Operation{p.Get, p.Put, p.Post, p.Delete, p.Options, p.Head, p.Patch, p.Trace} {
+		v.operation(op)
+	}
+	v.servers(p.Servers)
+	for _, param := range p.Parameters {
+		v.parameter(param)
+	}
+}
+
+func (v *extensionVisitor) operation(op *Operation) {
+	if op == nil {
+		return
+	}
+	v.fn(&op.VendorExtensible)
+	v.externalDocs(op.ExternalDocs)
+	for _, p := range op.Parameters {
+		v.parameter(p)
+	}
+	v.requestBody(op.RequestBody)
+	if r := op.Responses; r != nil {
+		v.fn(&r.VendorExtensible)
+		v.response(r.Default)
+		for _, resp := range r.StatusCodeResponses {
+			v.response(resp)
+		}
+	}
+	v.servers(op.Servers)
+}
+
+func (v *extensionVisitor) parameter(p *Parameter) {
+	if p == nil {
+		return
+	}
+	v.fn(&p.VendorExtensible)
+	v.schema(p.Schema)
+	v.content(p.Content)
+	v.examples(p.Examples)
+}
+
+func (v *extensionVisitor) requestBody(b *RequestBody) {
+	if b == nil {
+		return
+	}
+	v.fn(&b.VendorExtensible)
+	v.content(b.Content)
+}
+
+func (v *extensionVisitor) response(r *Response) {
+	if r == nil {
+		return
+	}
+	v.fn(&r.VendorExtensible)
+	v.headers(r.Headers)
+	v.content(r.Content)
+	v.links(r.Links)
+}
+
+func (v *extensionVisitor) headers(headers map[string]*Header) {
+	for _, h := range headers {
+		if h == nil {
+			continue
+		}
+		v.fn(&h.VendorExtensible)
+		v.schema(h.Schema)
+		v.content(h.Content)
+		v.examples(h.Examples)
+	}
+}
+
+func (v *extensionVisitor) content(content map[string]*MediaType) {
+	for _, m := range content {
+		if m == nil {
+			continue
+		}
+		v.fn(&m.VendorExtensible)
+		v.schema(m.Schema)
+		v.examples(m.Examples)
+		for _, e := range m.Encoding {
+			if e == nil {
+				continue
+			}
+			v.fn(&e.VendorExtensible)
+			v.headers(e.Headers)
+		}
+	}
+}
+
+func (v *extensionVisitor) examples(examples map[string]*Example) {
+	for _, e := range examples {
+		if e != nil {
+			v.fn(&e.VendorExtensible)
+		}
+	}
+}
+
+func (v *extensionVisitor) links(links map[string]*Link) {
+	for _, l := range links {
+		if l == nil {
+			continue
+		}
+		v.fn(&l.VendorExtensible)
+		if l.Server != nil {
+			v.server(l.Server)
+		}
+	}
+}
+
+func (v *extensionVisitor) securityScheme(s *SecurityScheme) {
+	if s == nil {
+		return
+	}
+	v.fn(&s.VendorExtensible)
+	for _, f := range s.Flows {
+		if f != nil {
+			v.fn(&f.VendorExtensible)
+		}
+	}
+}
+
+func (v *extensionVisitor) servers(servers []*Server) {
+	for _, s := range servers {
+		v.server(s)
+	}
+}
+
+func (v *extensionVisitor) server(s *Server) {
+	if s == nil {
+		return
+	}
+	v.fn(&s.VendorExtensible)
+	for _, variable := range s.Variables {
+		if variable != nil {
+			v.fn(&variable.VendorExtensible)
+		}
+	}
+}
+
+func (v *extensionVisitor) externalDocs(d *ExternalDocumentation) {
+	if d != nil {
+		v.fn(&d.VendorExtensible)
+	}
+}
+
+func (v *extensionVisitor) schema(s *spec.Schema) {
+	if s == nil || v.schemas[s] {
+		return
+	}
+	v.schemas[s] = true
+	v.schemaProps(s)
+}
+
+// schemaProps visits s without recording it as visited, for schemas that are
+// not addressable in the document, like the values of maps.
+func (v *extensionVisitor) schemaProps(s *spec.Schema) {
+	if v.schemaFn != nil {
+		v.schemaFn(s)
+	}
+	v.fn(&s.VendorExtensible)
+	if s.Items != nil {
+		v.schema(s.Items.Schema)
+		v.schemaSlice(s.Items.Schemas)
+	}
+	v.schemaSlice(s.PrefixItems)
+	v.schemaSlice(s.AllOf)
+	v.schemaSlice(s.OneOf)
+	v.schemaSlice(s.AnyOf)
+	v.schema(s.Not)
+	v.schemaMap(s.Properties)
+	v.schemaMap(s.PatternProperties)
+	v.schemaMap(s.Definitions)
+	for _, sb := range []*spec.SchemaOrBool{s.AdditionalProperties, s.AdditionalItems, s.UnevaluatedItems, s.UnevaluatedProperties} {
+		if sb != nil {
+			v.schema(sb.Schema)
+		}
+	}
+	for _, d := range s.Dependencies {
+		v.schema(d.Schema)
+	}
+}
+
+func (v *extensionVisitor) schemaSlice(schemas []spec.Schema) {
+	for i := range schemas {
+		v.schema(&schemas[i])
+	}
+}
+
+func (v *extensionVisitor) schemaMap(schemas map[string]spec.Schema) {
+	for name, s := range schemas {
+		v.schemaProps(&s)
+		schemas[name] = s
+	}
+}
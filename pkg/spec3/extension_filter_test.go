@@ -0,0 +1,143 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec3_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/kube-openapi/pkg/spec3"
+)
+
+const extensionFilterDoc = `{
+  "openapi": "3.0.0",
+  "info": {"title": "test", "version": "v1", "x-internal-owner": "team"},
+  "paths": {
+    "/pods": {
+      "x-internal-route": "pods",
+      "get": {
+        "x-internal-handler": "listPods",
+        "x-kubernetes-action": "list",
+        "responses": {
+          "200": {
+            "description": "OK",
+            "content": {
+              "application/json": {
+                "schema": {"$ref": "#/components/schemas/Pod"},
+                "example": {"x-internal-value": "kept, example values are data"}
+              }
+            }
+          }
+        }
+      }
+    }
+  },
+  "components": {
+    "schemas": {
+      "Pod": {
+        "type": "object",
+        "x-internal-storage": "etcd",
+        "x-kubernetes-group-version-kind": [{"group": "", "kind": "Pod", "version": "v1"}],
+        "properties": {
+          "x-internal-name": {"type": "string", "x-internal-deprecated": true},
+          "spec": {
+            "type": "object",
+            "additionalProperties": {"type": "string", "x-internal-secret": true},
+            "x-kubernetes-extra": {"x-internal-nested": "kept, extension values are data"}
+          }
+        }
+      }
+    }
+  }
+}`
+
+const extensionFilterExpected = `{
+  "openapi": "3.0.0",
+  "info": {"title": "test", "version": "v1"},
+  "paths": {
+    "/pods": {
+      "get": {
+        "x-kubernetes-action": "list",
+        "responses": {
+          "200": {
+            "description": "OK",
+            "content": {
+              "application/json": {
+                "schema": {"$ref": "#/components/schemas/Pod"},
+                "example": {"x-internal-value": "kept, example values are data"}
+              }
+            }
+          }
+        }
+      }
+    }
+  },
+  "components": {
+    "schemas": {
+      "Pod": {
+        "type": "object",
+        "x-kubernetes-group-version-kind": [{"group": "", "kind": "Pod", "version": "v1"}],
+        "properties": {
+          "x-internal-name": {"type": "string"},
+          "spec": {
+            "type": "object",
+            "additionalProperties": {"type": "string"},
+            "x-kubernetes-extra": {"x-internal-nested": "kept, extension values are data"}
+          }
+        }
+      }
+    }
+  }
+}`
+
+func TestExtensionFilter(t *testing.T) {
+	var o *spec3.OpenAPI
+	require.NoError(t, json.Unmarshal([]byte(extensionFilterDoc), &o))
+	before, err := json.Marshal(o)
+	require.NoError(t, err)
+
+	f, err := spec3.NewExtensionFilter("x-internal-*")
+	require.NoError(t, err)
+	filtered, err := f.Marshal(o)
+	require.NoError(t, err)
+	assert.JSONEq(t, extensionFilterExpected, string(filtered))
+
+	after, err := json.Marshal(o)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(before), string(after), "document should not be modified")
+
+	f, err = spec3.NewExtensionFilter("x-other-*")
+	require.NoError(t, err)
+	unfiltered, err := f.Marshal(o)
+	require.NoError(t, err)
+	assert.Equal(t, string(before), string(unfiltered))
+}
+
+func TestExtensionFilterMatches(t *testing.T) {
+	f, err := spec3.NewExtensionFilter("x-internal-*", "x-kubernetes-?ebug")
+	require.NoError(t, err)
+	assert.True(t, f.Matches("x-internal-owner"))
+	assert.True(t, f.Matches("x-kubernetes-debug"))
+	assert.False(t, f.Matches("x-kubernetes-group-version-kind"))
+	assert.False(t, f.Matches("x-internal"))
+
+	_, err = spec3.NewExtensionFilter("x-[")
+	assert.Error(t, err)
+}
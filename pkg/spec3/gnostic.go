@@ -0,0 +1,1777 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec3
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"github.com/google/gnostic/compiler"
+	openapi_v3 "github.com/google/gnostic/openapiv3"
+	"gopkg.in/yaml.v3"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+// vendorExtensionsFromGnostic decodes gnostic specification extensions into
+// kube-openapi vendor extensions.
+func vendorExtensionsFromGnostic(g []*openapi_v3.NamedAny) (spec.Extensions, error) {
+	if len(g) == 0 {
+		return nil, nil
+	}
+
+	ext := make(spec.Extensions, len(g))
+	for _, v := range g {
+		if v == nil {
+			continue
+		}
+
+		if v.Value == nil {
+			ext[v.Name] = nil
+			continue
+		}
+
+		var iface interface{}
+		if err := v.Value.ToRawInfo().Decode(&iface); err != nil {
+			return nil, err
+		}
+		ext[v.Name] = iface
+	}
+	return ext, nil
+}
+
+// vendorExtensionsToGnostic encodes kube-openapi vendor extensions as gnostic
+// specification extensions, sorted by name.
+func vendorExtensionsToGnostic(ext spec.Extensions) ([]*openapi_v3.NamedAny, error) {
+	if len(ext) == 0 {
+		return nil, nil
+	}
+
+	names := make([]string, 0, len(ext))
+	for k := range ext {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	ret := make([]*openapi_v3.NamedAny, 0, len(ext))
+	for _, name := range names {
+		bs, err := yaml.Marshal(ext[name])
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, &openapi_v3.NamedAny{
+			Name:  name,
+			Value: &openapi_v3.Any{Yaml: string(bs)},
+		})
+	}
+	return ret, nil
+}
+
+// decodeGnosticNode decodes a node of a gnostic document into v through its
+// JSON representation, so that the JSON decoding of v applies.
+func decodeGnosticNode(node *yaml.Node, v interface{}) error {
+	var iface interface{}
+	if err := node.Decode(&iface); err != nil {
+		return err
+	}
+	bs, err := json.Marshal(iface)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(bs, v)
+}
+
+// encodeGnosticNode encodes v as a node from which gnostic objects can be
+// parsed, through its JSON representation.
+func encodeGnosticNode(v interface{}) (*yaml.Node, *compiler.Context, error) {
+	bs, err := json.Marshal(v)
+	if err != nil {
+		return nil, nil, err
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(bs, &doc); err != nil {
+		return nil, nil, err
+	}
+	node := doc.Content[0]
+	return node, compiler.NewContext("$root", node, nil), nil
+}
+
+// anyFromGnostic decodes the value of a gnostic Any.
+func anyFromGnostic(g *openapi_v3.Any) (interface{}, error) {
+	if g == nil {
+		return nil, nil
+	}
+	var iface interface{}
+	if err := g.ToRawInfo().Decode(&iface); err != nil {
+		return nil, err
+	}
+	return iface, nil
+}
+
+// anyToGnostic encodes v as a gnostic Any.
+func anyToGnostic(v interface{}) (*openapi_v3.Any, error) {
+	if v == nil {
+		return nil, nil
+	}
+	bs, err := yaml.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return &openapi_v3.Any{Yaml: string(bs)}, nil
+}
+
+// refFromGnostic returns the ref of a gnostic reference.
+func refFromGnostic(g *openapi_v3.Reference) (spec.Refable, error) {
+	ref, err := spec.NewRef(g.XRef)
+	if err != nil {
+		return spec.Refable{}, err
+	}
+	return spec.Refable{Ref: ref}, nil
+}
+
+// FromGnostic converts gnostic components into k, including the references
+// and extensions of every component. Callbacks and the extensions of the
+// components object itself have no counterpart in Components and are dropped.
+func (k *Components) FromGnostic(g *openapi_v3.Components) error {
+	if g == nil {
+		return nil
+	}
+
+	c := Components{}
+	if g.Schemas != nil {
+		c.Schemas = make(map[string]*spec.Schema, len(g.Schemas.AdditionalProperties))
+		for _, named := range g.Schemas.AdditionalProperties {
+			s := &spec.Schema{}
+			if err := decodeGnosticComponent(named.Value, s); err != nil {
+				return fmt.Errorf("schema %q: %v", named.Name, err)
+			}
+			c.Schemas[named.Name] = s
+		}
+	}
+	if g.Responses != nil {
+		c.Responses = make(map[string]*Response, len(g.Responses.AdditionalProperties))
+		for _, named := range g.Responses.AdditionalProperties {
+			r := &Response{}
+			if err := r.FromGnostic(named.Value); err != nil {
+				return fmt.Errorf("response %q: %v", named.Name, err)
+			}
+			c.Responses[named.Name] = r
+		}
+	}
+	if g.Parameters != nil {
+		c.Parameters = make(map[string]*Parameter, len(g.Parameters.AdditionalProperties))
+		for _, named := range g.Parameters.AdditionalProperties {
+			p := &Parameter{}
+			if err := decodeGnosticComponent(named.Value, p); err != nil {
+				return fmt.Errorf("parameter %q: %v", named.Name, err)
+			}
+			c.Parameters[named.Name] = p
+		}
+	}
+	if g.Examples != nil {
+		c.Examples = make(map[string]*Example, len(g.Examples.AdditionalProperties))
+		for _, named := range g.Examples.AdditionalProperties {
+			e := &Example{}
+			if err := e.FromGnostic(named.Value); err != nil {
+				return fmt.Errorf("example %q: %v", named.Name, err)
+			}
+			c.Examples[named.Name] = e
+		}
+	}
+	if g.RequestBodies != nil {
+		c.RequestBodies = make(map[string]*RequestBody, len(g.RequestBodies.AdditionalProperties))
+		for _, named := range g.RequestBodies.AdditionalProperties {
+			b := &RequestBody{}
+			if err := b.FromGnostic(named.Value); err != nil {
+				return fmt.Errorf("request body %q: %v", named.Name, err)
+			}
+			c.RequestBodies[named.Name] = b
+		}
+	}
+	if g.Headers != nil {
+		c.Headers = make(map[string]*Header, len(g.Headers.AdditionalProperties))
+		for _, named := range g.Headers.AdditionalProperties {
+			h := &Header{}
+			if err := h.FromGnostic(named.Value); err != nil {
+				return fmt.Errorf("header %q: %v", named.Name, err)
+			}
+			c.Headers[named.Name] = h
+		}
+	}
+	if g.SecuritySchemes != nil {
+		c.SecuritySchemes = make(SecuritySchemes, len(g.SecuritySchemes.AdditionalProperties))
+		for _, named := range g.SecuritySchemes.AdditionalProperties {
+			s := &SecurityScheme{}
+			if err := s.FromGnostic(named.Value); err != nil {
+				return fmt.Errorf("security scheme %q: %v", named.Name, err)
+			}
+			c.SecuritySchemes[named.Name] = s
+		}
+	}
+	if g.Links != nil {
+		c.Links = make(map[string]*Link, len(g.Links.AdditionalProperties))
+		for _, named := range g.Links.AdditionalProperties {
+			l := &Link{}
+			if err := l.FromGnostic(named.Value); err != nil {
+				return fmt.Errorf("link %q: %v", named.Name, err)
+			}
+			c.Links[named.Name] = l
+		}
+	}
+
+	*k = c
+	return nil
+}
+
+// ToGnostic converts k into gnostic components.
+func (k *Components) ToGnostic() (*openapi_v3.Components, error) {
+	if k == nil {
+		return nil, nil
+	}
+
+	c := &openapi_v3.Components{}
+	if k.Schemas != nil {
+		c.Schemas = &openapi_v3.SchemasOrReferences{}
+		for _, name := range sortedKeys(k.Schemas) {
+			s, err := schemaToGnostic(k.Schemas[name])
+			if err != nil {
+				return nil, fmt.Errorf("schema %q: %v", name, err)
+			}
+			c.Schemas.AdditionalProperties = append(c.Schemas.AdditionalProperties, &openapi_v3.NamedSchemaOrReference{Name: name, Value: s})
+		}
+	}
+	if k.Responses != nil {
+		c.Responses = &openapi_v3.ResponsesOrReferences{}
+		for _, name := range sortedKeys(k.Responses) {
+			r, err := k.Responses[name].ToGnostic()
+			if err != nil {
+				return nil, fmt.Errorf("response %q: %v", name, err)
+			}
+			c.Responses.AdditionalProperties = append(c.Responses.AdditionalProperties, &openapi_v3.NamedResponseOrReference{Name: name, Value: r})
+		}
+	}
+	if k.Parameters != nil {
+		c.Parameters = &openapi_v3.ParametersOrReferences{}
+		for _, name := range sortedKeys(k.Parameters) {
+			p, err := k.Parameters[name].ToGnostic()
+			if err != nil {
+				return nil, fmt.Errorf("parameter %q: %v", name, err)
+			}
+			c.Parameters.AdditionalProperties = append(c.Parameters.AdditionalProperties, &openapi_v3.NamedParameterOrReference{Name: name, Value: p})
+		}
+	}
+	if k.Examples != nil {
+		c.Examples = &openapi_v3.ExamplesOrReferences{}
+		for _, name := range sortedKeys(k.Examples) {
+			e, err := k.Examples[name].ToGnostic()
+			if err != nil {
+				return nil, fmt.Errorf("example %q: %v", name, err)
+			}
+			c.Examples.AdditionalProperties = append(c.Examples.AdditionalProperties, &openapi_v3.NamedExampleOrReference{Name: name, Value: e})
+		}
+	}
+	if k.RequestBodies != nil {
+		c.RequestBodies = &openapi_v3.RequestBodiesOrReferences{}
+		for _, name := range sortedKeys(k.RequestBodies) {
+			b, err := k.RequestBodies[name].ToGnostic()
+			if err != nil {
+				return nil, fmt.Errorf("request body %q: %v", name, err)
+			}
+			c.RequestBodies.AdditionalProperties = append(c.RequestBodies.AdditionalProperties, &openapi_v3.NamedRequestBodyOrReference{Name: name, Value: b})
+		}
+	}
+	headers, err := headersToGnostic(k.Headers)
+	if err != nil {
+		return nil, err
+	}
+	c.Headers = headers
+	if k.SecuritySchemes != nil {
+		c.SecuritySchemes = &openapi_v3.SecuritySchemesOrReferences{}
+		for _, name := range sortedKeys(k.SecuritySchemes) {
+			s, err := k.SecuritySchemes[name].ToGnostic()
+			if err != nil {
+				return nil, fmt.Errorf("security scheme %q: %v", name, err)
+			}
+			c.SecuritySchemes.AdditionalProperties = append(c.SecuritySchemes.AdditionalProperties, &openapi_v3.NamedSecuritySchemeOrReference{Name: name, Value: s})
+		}
+	}
+	links, err := linksToGnostic(k.Links)
+	if err != nil {
+		return nil, err
+	}
+	c.Links = links
+	return c, nil
+}
+
+// schemaToGnostic converts s into a gnostic schema, or reference to one.
+func schemaToGnostic(s *spec.Schema) (*openapi_v3.SchemaOrReference, error) {
+	if s == nil {
+		return nil, nil
+	}
+	node, ctx, err := encodeGnosticNode(s)
+	if err != nil {
+		return nil, err
+	}
+	return openapi_v3.NewSchemaOrReference(node, ctx)
+}
+
+// gnosticComponent is a gnostic object, or reference to one, that can be
+// decoded with decodeGnosticNode.
+type gnosticComponent interface {
+	ToRawInfo() *yaml.Node
+}
+
+// decodeGnosticComponent decodes g, a gnostic object or reference to one, into
+// v, whose JSON decoding handles both.
+func decodeGnosticComponent(g gnosticComponent, v interface{}) error {
+	if g == nil || reflect.ValueOf(g).IsNil() {
+		return nil
+	}
+	return decodeGnosticNode(g.ToRawInfo(), v)
+}
+
+// SecurityRequirementsFromGnostic converts gnostic security requirements, as
+// found on documents and operations, into maps from scheme names to scopes.
+func SecurityRequirementsFromGnostic(g []*openapi_v3.SecurityRequirement) []map[string][]string {
+	if g == nil {
+		return nil
+	}
+
+	reqs := make([]map[string][]string, 0, len(g))
+	for _, req := range g {
+		r := make(map[string][]string, len(req.GetAdditionalProperties()))
+		for _, named := range req.GetAdditionalProperties() {
+			scopes := []string{}
+			if named.Value != nil {
+				scopes = append(scopes, named.Value.Value...)
+			}
+			r[named.Name] = scopes
+		}
+		reqs = append(reqs, r)
+	}
+	return reqs
+}
+
+// SecurityRequirementsToGnostic converts security requirements into gnostic
+// ones, with schemes sorted by name.
+func SecurityRequirementsToGnostic(reqs []map[string][]string) []*openapi_v3.SecurityRequirement {
+	if reqs == nil {
+		return nil
+	}
+
+	ret := make([]*openapi_v3.SecurityRequirement, 0, len(reqs))
+	for _, req := range reqs {
+		r := &openapi_v3.SecurityRequirement{}
+		for _, name := range sortedKeys(req) {
+			r.AdditionalProperties = append(r.AdditionalProperties, &openapi_v3.NamedStringArray{
+				Name:  name,
+				Value: &openapi_v3.StringArray{Value: req[name]},
+			})
+		}
+		ret = append(ret, r)
+	}
+	return ret
+}
+
+// FromGnostic converts a gnostic request body, or reference to one, into k.
+func (k *RequestBody) FromGnostic(g *openapi_v3.RequestBodyOrReference) error {
+	if g == nil {
+		return nil
+	}
+
+	if ref := g.GetReference(); ref != nil {
+		refable, err := refFromGnostic(ref)
+		if err != nil {
+			return err
+		}
+		*k = RequestBody{Refable: refable}
+		return nil
+	}
+
+	body := g.GetRequestBody()
+	if body == nil {
+		*k = RequestBody{}
+		return nil
+	}
+	ext, err := vendorExtensionsFromGnostic(body.SpecificationExtension)
+	if err != nil {
+		return err
+	}
+	var content map[string]*MediaType
+	if body.Content != nil && body.Content.AdditionalProperties != nil {
+		content = make(map[string]*MediaType, len(body.Content.AdditionalProperties))
+		for _, named := range body.Content.AdditionalProperties {
+			mediaType := &MediaType{}
+			if err := mediaType.FromGnostic(named.Value); err != nil {
+				return err
+			}
+			content[named.Name] = mediaType
+		}
+	}
+
+	*k = RequestBody{
+		RequestBodyProps: RequestBodyProps{
+			Description: body.Description,
+			Content:     content,
+			Required:    body.Required,
+		},
+		VendorExtensible: spec.VendorExtensible{Extensions: ext},
+	}
+	return nil
+}
+
+// ToGnostic converts k into a gnostic request body, or reference to one.
+func (k *RequestBody) ToGnostic() (*openapi_v3.RequestBodyOrReference, error) {
+	if k == nil {
+		return nil, nil
+	}
+
+	if ref := k.Ref.String(); ref != "" {
+		return &openapi_v3.RequestBodyOrReference{
+			Oneof: &openapi_v3.RequestBodyOrReference_Reference{Reference: &openapi_v3.Reference{XRef: ref}},
+		}, nil
+	}
+
+	ext, err := vendorExtensionsToGnostic(k.Extensions)
+	if err != nil {
+		return nil, err
+	}
+	var content *openapi_v3.MediaTypes
+	if k.Content != nil {
+		content = &openapi_v3.MediaTypes{}
+		for _, name := range sortedKeys(k.Content) {
+			mediaType, err := k.Content[name].ToGnostic()
+			if err != nil {
+				return nil, err
+			}
+			content.AdditionalProperties = append(content.AdditionalProperties, &openapi_v3.NamedMediaType{Name: name, Value: mediaType})
+		}
+	}
+
+	return &openapi_v3.RequestBodyOrReference{
+		Oneof: &openapi_v3.RequestBodyOrReference_RequestBody{RequestBody: &openapi_v3.RequestBody{
+			Description:            k.Description,
+			Content:                content,
+			Required:               k.Required,
+			SpecificationExtension: ext,
+		}},
+	}, nil
+}
+
+// FromGnostic converts a gnostic media type into k.
+func (k *MediaType) FromGnostic(g *openapi_v3.MediaType) error {
+	if g == nil {
+		return nil
+	}
+
+	ext, err := vendorExtensionsFromGnostic(g.SpecificationExtension)
+	if err != nil {
+		return err
+	}
+	var schema *spec.Schema
+	if g.Schema != nil {
+		schema = &spec.Schema{}
+		if err := decodeGnosticNode(g.Schema.ToRawInfo(), schema); err != nil {
+			return err
+		}
+	}
+	example, err := anyFromGnostic(g.Example)
+	if err != nil {
+		return err
+	}
+	var examples map[string]*Example
+	if g.Examples != nil && g.Examples.AdditionalProperties != nil {
+		examples = make(map[string]*Example, len(g.Examples.AdditionalProperties))
+		for _, named := range g.Examples.AdditionalProperties {
+			e := &Example{}
+			if err := e.FromGnostic(named.Value); err != nil {
+				return err
+			}
+			examples[named.Name] = e
+		}
+	}
+	var encoding map[string]*Encoding
+	if g.Encoding != nil && g.Encoding.AdditionalProperties != nil {
+		encoding = make(map[string]*Encoding, len(g.Encoding.AdditionalProperties))
+		for _, named := range g.Encoding.AdditionalProperties {
+			e := &Encoding{}
+			if err := e.FromGnostic(named.Value); err != nil {
+				return err
+			}
+			encoding[named.Name] = e
+		}
+	}
+
+	*k = MediaType{
+		MediaTypeProps: MediaTypeProps{
+			Schema:   schema,
+			Example:  example,
+			Examples: examples,
+			Encoding: encoding,
+		},
+		VendorExtensible: spec.VendorExtensible{Extensions: ext},
+	}
+	return nil
+}
+
+// ToGnostic converts k into a gnostic media type.
+func (k *MediaType) ToGnostic() (*openapi_v3.MediaType, error) {
+	if k == nil {
+		return nil, nil
+	}
+
+	ext, err := vendorExtensionsToGnostic(k.Extensions)
+	if err != nil {
+		return nil, err
+	}
+	var schema *openapi_v3.SchemaOrReference
+	if k.Schema != nil {
+		node, ctx, err := encodeGnosticNode(k.Schema)
+		if err != nil {
+			return nil, err
+		}
+		if schema, err = openapi_v3.NewSchemaOrReference(node, ctx); err != nil {
+			return nil, err
+		}
+	}
+	example, err := anyToGnostic(k.Example)
+	if err != nil {
+		return nil, err
+	}
+	var examples *openapi_v3.ExamplesOrReferences
+	if k.Examples != nil {
+		examples = &openapi_v3.ExamplesOrReferences{}
+		for _, name := range sortedKeys(k.Examples) {
+			e, err := k.Examples[name].ToGnostic()
+			if err != nil {
+				return nil, err
+			}
+			examples.AdditionalProperties = append(examples.AdditionalProperties, &openapi_v3.NamedExampleOrReference{Name: name, Value: e})
+		}
+	}
+	var encoding *openapi_v3.Encodings
+	if k.Encoding != nil {
+		encoding = &openapi_v3.Encodings{}
+		for _, name := range sortedKeys(k.Encoding) {
+			e, err := k.Encoding[name].ToGnostic()
+			if err != nil {
+				return nil, err
+			}
+			encoding.AdditionalProperties = append(encoding.AdditionalProperties, &openapi_v3.NamedEncoding{Name: name, Value: e})
+		}
+	}
+
+	return &openapi_v3.MediaType{
+		Schema:                 schema,
+		Example:                example,
+		Examples:               examples,
+		Encoding:               encoding,
+		SpecificationExtension: ext,
+	}, nil
+}
+
+// FromGnostic converts a gnostic example, or reference to one, into k.
+func (k *Example) FromGnostic(g *openapi_v3.ExampleOrReference) error {
+	if g == nil {
+		return nil
+	}
+
+	if ref := g.GetReference(); ref != nil {
+		refable, err := refFromGnostic(ref)
+		if err != nil {
+			return err
+		}
+		*k = Example{Refable: refable}
+		return nil
+	}
+
+	e := g.GetExample()
+	if e == nil {
+		*k = Example{}
+		return nil
+	}
+	ext, err := vendorExtensionsFromGnostic(e.SpecificationExtension)
+	if err != nil {
+		return err
+	}
+	value, err := anyFromGnostic(e.Value)
+	if err != nil {
+		return err
+	}
+
+	*k = Example{
+		ExampleProps: ExampleProps{
+			Summary:       e.Summary,
+			Description:   e.Description,
+			Value:         value,
+			ExternalValue: e.ExternalValue,
+		},
+		VendorExtensible: spec.VendorExtensible{Extensions: ext},
+	}
+	return nil
+}
+
+// ToGnostic converts k into a gnostic example, or reference to one.
+func (k *Example) ToGnostic() (*openapi_v3.ExampleOrReference, error) {
+	if k == nil {
+		return nil, nil
+	}
+
+	if ref := k.Ref.String(); ref != "" {
+		return &openapi_v3.ExampleOrReference{
+			Oneof: &openapi_v3.ExampleOrReference_Reference{Reference: &openapi_v3.Reference{XRef: ref}},
+		}, nil
+	}
+
+	ext, err := vendorExtensionsToGnostic(k.Extensions)
+	if err != nil {
+		return nil, err
+	}
+	value, err := anyToGnostic(k.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	return &openapi_v3.ExampleOrReference{
+		Oneof: &openapi_v3.ExampleOrReference_Example{Example: &openapi_v3.Example{
+			Summary:                k.Summary,
+			Description:            k.Description,
+			Value:                  value,
+			ExternalValue:          k.ExternalValue,
+			SpecificationExtension: ext,
+		}},
+	}, nil
+}
+
+// FromGnostic converts a gnostic encoding into k.
+func (k *Encoding) FromGnostic(g *openapi_v3.Encoding) error {
+	if g == nil {
+		return nil
+	}
+
+	ext, err := vendorExtensionsFromGnostic(g.SpecificationExtension)
+	if err != nil {
+		return err
+	}
+	headers, err := headersFromGnostic(g.Headers)
+	if err != nil {
+		return err
+	}
+
+	*k = Encoding{
+		EncodingProps: EncodingProps{
+			ContentType:   g.ContentType,
+			Headers:       headers,
+			Style:         g.Style,
+			Explode:       g.Explode,
+			AllowReserved: g.AllowReserved,
+		},
+		VendorExtensible: spec.VendorExtensible{Extensions: ext},
+	}
+	return nil
+}
+
+// ToGnostic converts k into a gnostic encoding.
+func (k *Encoding) ToGnostic() (*openapi_v3.Encoding, error) {
+	if k == nil {
+		return nil, nil
+	}
+
+	ext, err := vendorExtensionsToGnostic(k.Extensions)
+	if err != nil {
+		return nil, err
+	}
+	headers, err := headersToGnostic(k.Headers)
+	if err != nil {
+		return nil, err
+	}
+
+	return &openapi_v3.Encoding{
+		ContentType:            k.ContentType,
+		Headers:                headers,
+		Style:                  k.Style,
+		Explode:                k.Explode,
+		AllowReserved:          k.AllowReserved,
+		SpecificationExtension: ext,
+	}, nil
+}
+
+// FromGnostic converts gnostic responses into k. Status codes that are not
+// integers, like "2XX", have no counterpart in ResponsesProps and are
+// dropped.
+func (k *Responses) FromGnostic(g *openapi_v3.Responses) error {
+	if g == nil {
+		return nil
+	}
+
+	ext, err := vendorExtensionsFromGnostic(g.SpecificationExtension)
+	if err != nil {
+		return err
+	}
+	var def *Response
+	if g.Default != nil {
+		def = &Response{}
+		if err := def.FromGnostic(g.Default); err != nil {
+			return err
+		}
+	}
+	var statusCodeResponses map[int]*Response
+	for _, named := range g.ResponseOrReference {
+		code, err := strconv.Atoi(named.Name)
+		if err != nil {
+			// Responses only holds responses for single status codes, so
+			// ranges such as "2XX" would be lost
+			return fmt.Errorf("response %q: unsupported status code", named.Name)
+		}
+		r := &Response{}
+		if err := r.FromGnostic(named.Value); err != nil {
+			return fmt.Errorf("response %q: %v", named.Name, err)
+		}
+		if statusCodeResponses == nil {
+			statusCodeResponses = map[int]*Response{}
+		}
+		statusCodeResponses[code] = r
+	}
+
+	*k = Responses{
+		ResponsesProps: ResponsesProps{
+			Default:             def,
+			StatusCodeResponses: statusCodeResponses,
+		},
+		VendorExtensible: spec.VendorExtensible{Extensions: ext},
+	}
+	return nil
+}
+
+// ToGnostic converts k into gnostic responses.
+func (k *Responses) ToGnostic() (*openapi_v3.Responses, error) {
+	if k == nil {
+		return nil, nil
+	}
+
+	ext, err := vendorExtensionsToGnostic(k.Extensions)
+	if err != nil {
+		return nil, err
+	}
+	def, err := k.Default.ToGnostic()
+	if err != nil {
+		return nil, err
+	}
+	codes := make([]int, 0, len(k.StatusCodeResponses))
+	for code := range k.StatusCodeResponses {
+		codes = append(codes, code)
+	}
+	sort.Ints(codes)
+	var responses []*openapi_v3.NamedResponseOrReference
+	for _, code := range codes {
+		r, err := k.StatusCodeResponses[code].ToGnostic()
+		if err != nil {
+			return nil, err
+		}
+		responses = append(responses, &openapi_v3.NamedResponseOrReference{Name: strconv.Itoa(code), Value: r})
+	}
+
+	return &openapi_v3.Responses{
+		Default:                def,
+		ResponseOrReference:    responses,
+		SpecificationExtension: ext,
+	}, nil
+}
+
+// FromGnostic converts a gnostic response, or reference to one, into k.
+func (k *Response) FromGnostic(g *openapi_v3.ResponseOrReference) error {
+	if g == nil {
+		return nil
+	}
+
+	if ref := g.GetReference(); ref != nil {
+		refable, err := refFromGnostic(ref)
+		if err != nil {
+			return err
+		}
+		*k = Response{Refable: refable}
+		return nil
+	}
+
+	r := g.GetResponse()
+	if r == nil {
+		*k = Response{}
+		return nil
+	}
+	ext, err := vendorExtensionsFromGnostic(r.SpecificationExtension)
+	if err != nil {
+		return err
+	}
+	headers, err := headersFromGnostic(r.Headers)
+	if err != nil {
+		return err
+	}
+	var content map[string]*MediaType
+	if r.Content != nil && r.Content.AdditionalProperties != nil {
+		content = make(map[string]*MediaType, len(r.Content.AdditionalProperties))
+		for _, named := range r.Content.AdditionalProperties {
+			mediaType := &MediaType{}
+			if err := mediaType.FromGnostic(named.Value); err != nil {
+				return err
+			}
+			content[named.Name] = mediaType
+		}
+	}
+	links, err := linksFromGnostic(r.Links)
+	if err != nil {
+		return err
+	}
+
+	*k = Response{
+		ResponseProps: ResponseProps{
+			Description: r.Description,
+			Headers:     headers,
+			Content:     content,
+			Links:       links,
+		},
+		VendorExtensible: spec.VendorExtensible{Extensions: ext},
+	}
+	return nil
+}
+
+// ToGnostic converts k into a gnostic response, or reference to one.
+func (k *Response) ToGnostic() (*openapi_v3.ResponseOrReference, error) {
+	if k == nil {
+		return nil, nil
+	}
+
+	if ref := k.Ref.String(); ref != "" {
+		return &openapi_v3.ResponseOrReference{
+			Oneof: &openapi_v3.ResponseOrReference_Reference{Reference: &openapi_v3.Reference{XRef: ref}},
+		}, nil
+	}
+
+	ext, err := vendorExtensionsToGnostic(k.Extensions)
+	if err != nil {
+		return nil, err
+	}
+	headers, err := headersToGnostic(k.Headers)
+	if err != nil {
+		return nil, err
+	}
+	var content *openapi_v3.MediaTypes
+	if k.Content != nil {
+		content = &openapi_v3.MediaTypes{}
+		for _, name := range sortedKeys(k.Content) {
+			mediaType, err := k.Content[name].ToGnostic()
+			if err != nil {
+				return nil, err
+			}
+			content.AdditionalProperties = append(content.AdditionalProperties, &openapi_v3.NamedMediaType{Name: name, Value: mediaType})
+		}
+	}
+	links, err := linksToGnostic(k.Links)
+	if err != nil {
+		return nil, err
+	}
+
+	return &openapi_v3.ResponseOrReference{
+		Oneof: &openapi_v3.ResponseOrReference_Response{Response: &openapi_v3.Response{
+			Description:            k.Description,
+			Headers:                headers,
+			Content:                content,
+			Links:                  links,
+			SpecificationExtension: ext,
+		}},
+	}, nil
+}
+
+// FromGnostic converts a gnostic header, or reference to one, into k.
+func (k *Header) FromGnostic(g *openapi_v3.HeaderOrReference) error {
+	if g == nil {
+		return nil
+	}
+
+	if ref := g.GetReference(); ref != nil {
+		refable, err := refFromGnostic(ref)
+		if err != nil {
+			return err
+		}
+		*k = Header{Refable: refable}
+		return nil
+	}
+
+	h := g.GetHeader()
+	if h == nil {
+		*k = Header{}
+		return nil
+	}
+	ext, err := vendorExtensionsFromGnostic(h.SpecificationExtension)
+	if err != nil {
+		return err
+	}
+	var schema *spec.Schema
+	if h.Schema != nil {
+		schema = &spec.Schema{}
+		if err := decodeGnosticNode(h.Schema.ToRawInfo(), schema); err != nil {
+			return err
+		}
+	}
+	var content map[string]*MediaType
+	if h.Content != nil && h.Content.AdditionalProperties != nil {
+		content = make(map[string]*MediaType, len(h.Content.AdditionalProperties))
+		for _, named := range h.Content.AdditionalProperties {
+			mediaType := &MediaType{}
+			if err := mediaType.FromGnostic(named.Value); err != nil {
+				return err
+			}
+			content[named.Name] = mediaType
+		}
+	}
+	example, err := anyFromGnostic(h.Example)
+	if err != nil {
+		return err
+	}
+	var examples map[string]*Example
+	if h.Examples != nil && h.Examples.AdditionalProperties != nil {
+		examples = make(map[string]*Example, len(h.Examples.AdditionalProperties))
+		for _, named := range h.Examples.AdditionalProperties {
+			e := &Example{}
+			if err := e.FromGnostic(named.Value); err != nil {
+				return err
+			}
+			examples[named.Name] = e
+		}
+	}
+
+	*k = Header{
+		HeaderProps: HeaderProps{
+			Description:     h.Description,
+			Required:        h.Required,
+			Deprecated:      h.Deprecated,
+			AllowEmptyValue: h.AllowEmptyValue,
+			Style:           h.Style,
+			Explode:         h.Explode,
+			AllowReserved:   h.AllowReserved,
+			Schema:          schema,
+			Content:         content,
+			Example:         example,
+			Examples:        examples,
+		},
+		VendorExtensible: spec.VendorExtensible{Extensions: ext},
+	}
+	return nil
+}
+
+// ToGnostic converts k into a gnostic header, or reference to one.
+func (k *Header) ToGnostic() (*openapi_v3.HeaderOrReference, error) {
+	if k == nil {
+		return nil, nil
+	}
+
+	if ref := k.Ref.String(); ref != "" {
+		return &openapi_v3.HeaderOrReference{
+			Oneof: &openapi_v3.HeaderOrReference_Reference{Reference: &openapi_v3.Reference{XRef: ref}},
+		}, nil
+	}
+
+	ext, err := vendorExtensionsToGnostic(k.Extensions)
+	if err != nil {
+		return nil, err
+	}
+	var schema *openapi_v3.SchemaOrReference
+	if k.Schema != nil {
+		node, ctx, err := encodeGnosticNode(k.Schema)
+		if err != nil {
+			return nil, err
+		}
+		if schema, err = openapi_v3.NewSchemaOrReference(node, ctx); err != nil {
+			return nil, err
+		}
+	}
+	var content *openapi_v3.MediaTypes
+	if k.Content != nil {
+		content = &openapi_v3.MediaTypes{}
+		for _, name := range sortedKeys(k.Content) {
+			mediaType, err := k.Content[name].ToGnostic()
+			if err != nil {
+				return nil, err
+			}
+			content.AdditionalProperties = append(content.AdditionalProperties, &openapi_v3.NamedMediaType{Name: name, Value: mediaType})
+		}
+	}
+	example, err := anyToGnostic(k.Example)
+	if err != nil {
+		return nil, err
+	}
+	var examples *openapi_v3.ExamplesOrReferences
+	if k.Examples != nil {
+		examples = &openapi_v3.ExamplesOrReferences{}
+		for _, name := range sortedKeys(k.Examples) {
+			e, err := k.Examples[name].ToGnostic()
+			if err != nil {
+				return nil, err
+			}
+			examples.AdditionalProperties = append(examples.AdditionalProperties, &openapi_v3.NamedExampleOrReference{Name: name, Value: e})
+		}
+	}
+
+	return &openapi_v3.HeaderOrReference{
+		Oneof: &openapi_v3.HeaderOrReference_Header{Header: &openapi_v3.Header{
+			Description:            k.Description,
+			Required:               k.Required,
+			Deprecated:             k.Deprecated,
+			AllowEmptyValue:        k.AllowEmptyValue,
+			Style:                  k.Style,
+			Explode:                k.Explode,
+			AllowReserved:          k.AllowReserved,
+			Schema:                 schema,
+			Example:                example,
+			Examples:               examples,
+			Content:                content,
+			SpecificationExtension: ext,
+		}},
+	}, nil
+}
+
+func headersFromGnostic(g *openapi_v3.HeadersOrReferences) (map[string]*Header, error) {
+	if g == nil || g.AdditionalProperties == nil {
+		return nil, nil
+	}
+	headers := make(map[string]*Header, len(g.AdditionalProperties))
+	for _, named := range g.AdditionalProperties {
+		h := &Header{}
+		if err := h.FromGnostic(named.Value); err != nil {
+			return nil, err
+		}
+		headers[named.Name] = h
+	}
+	return headers, nil
+}
+
+func headersToGnostic(headers map[string]*Header) (*openapi_v3.HeadersOrReferences, error) {
+	if headers == nil {
+		return nil, nil
+	}
+	g := &openapi_v3.HeadersOrReferences{}
+	for _, name := range sortedKeys(headers) {
+		h, err := headers[name].ToGnostic()
+		if err != nil {
+			return nil, err
+		}
+		g.AdditionalProperties = append(g.AdditionalProperties, &openapi_v3.NamedHeaderOrReference{Name: name, Value: h})
+	}
+	return g, nil
+}
+
+// FromGnostic converts a gnostic link, or reference to one, into k. Note that
+// gnostic only parses objects as the request bodies of links, so that
+// expressions like "$request.body#/id" are lost when parsing a document.
+func (k *Link) FromGnostic(g *openapi_v3.LinkOrReference) error {
+	if g == nil {
+		return nil
+	}
+
+	if ref := g.GetReference(); ref != nil {
+		refable, err := refFromGnostic(ref)
+		if err != nil {
+			return err
+		}
+		*k = Link{Refable: refable}
+		return nil
+	}
+
+	l := g.GetLink()
+	if l == nil {
+		*k = Link{}
+		return nil
+	}
+	ext, err := vendorExtensionsFromGnostic(l.SpecificationExtension)
+	if err != nil {
+		return err
+	}
+	var parameters map[string]interface{}
+	if l.Parameters != nil {
+		if err := decodeGnosticNode(l.Parameters.ToRawInfo(), &parameters); err != nil {
+			return err
+		}
+	}
+	var requestBody interface{}
+	if l.RequestBody != nil {
+		if err := decodeGnosticNode(l.RequestBody.ToRawInfo(), &requestBody); err != nil {
+			return err
+		}
+	}
+	var server *Server
+	if l.Server != nil {
+		server = &Server{}
+		if err := decodeGnosticNode(l.Server.ToRawInfo(), server); err != nil {
+			return err
+		}
+	}
+
+	*k = Link{
+		LinkProps: LinkProps{
+			OperationRef: l.OperationRef,
+			OperationId:  l.OperationId,
+			Parameters:   parameters,
+			Description:  l.Description,
+			RequestBody:  requestBody,
+			Server:       server,
+		},
+		VendorExtensible: spec.VendorExtensible{Extensions: ext},
+	}
+	return nil
+}
+
+// ToGnostic converts k into a gnostic link, or reference to one.
+func (k *Link) ToGnostic() (*openapi_v3.LinkOrReference, error) {
+	if k == nil {
+		return nil, nil
+	}
+
+	if ref := k.Ref.String(); ref != "" {
+		return &openapi_v3.LinkOrReference{
+			Oneof: &openapi_v3.LinkOrReference_Reference{Reference: &openapi_v3.Reference{XRef: ref}},
+		}, nil
+	}
+
+	ext, err := vendorExtensionsToGnostic(k.Extensions)
+	if err != nil {
+		return nil, err
+	}
+	var parameters *openapi_v3.AnyOrExpression
+	if k.Parameters != nil {
+		if parameters, err = anyOrExpressionToGnostic(k.Parameters); err != nil {
+			return nil, err
+		}
+	}
+	requestBody, err := anyOrExpressionToGnostic(k.RequestBody)
+	if err != nil {
+		return nil, err
+	}
+	var server *openapi_v3.Server
+	if k.Server != nil {
+		node, ctx, err := encodeGnosticNode(k.Server)
+		if err != nil {
+			return nil, err
+		}
+		if server, err = openapi_v3.NewServer(node, ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	return &openapi_v3.LinkOrReference{
+		Oneof: &openapi_v3.LinkOrReference_Link{Link: &openapi_v3.Link{
+			OperationRef:           k.OperationRef,
+			OperationId:            k.OperationId,
+			Parameters:             parameters,
+			RequestBody:            requestBody,
+			Description:            k.Description,
+			Server:                 server,
+			SpecificationExtension: ext,
+		}},
+	}, nil
+}
+
+// anyOrExpressionToGnostic encodes v, which may also be a runtime expression,
+// as a gnostic value. Unlike gnostic parsing, it supports values that are not
+// objects.
+func anyOrExpressionToGnostic(v interface{}) (*openapi_v3.AnyOrExpression, error) {
+	a, err := anyToGnostic(v)
+	if err != nil || a == nil {
+		return nil, err
+	}
+	return &openapi_v3.AnyOrExpression{Oneof: &openapi_v3.AnyOrExpression_Any{Any: a}}, nil
+}
+
+func linksFromGnostic(g *openapi_v3.LinksOrReferences) (map[string]*Link, error) {
+	if g == nil || g.AdditionalProperties == nil {
+		return nil, nil
+	}
+	links := make(map[string]*Link, len(g.AdditionalProperties))
+	for _, named := range g.AdditionalProperties {
+		l := &Link{}
+		if err := l.FromGnostic(named.Value); err != nil {
+			return nil, err
+		}
+		links[named.Name] = l
+	}
+	return links, nil
+}
+
+func linksToGnostic(links map[string]*Link) (*openapi_v3.LinksOrReferences, error) {
+	if links == nil {
+		return nil, nil
+	}
+	g := &openapi_v3.LinksOrReferences{}
+	for _, name := range sortedKeys(links) {
+		l, err := links[name].ToGnostic()
+		if err != nil {
+			return nil, err
+		}
+		g.AdditionalProperties = append(g.AdditionalProperties, &openapi_v3.NamedLinkOrReference{Name: name, Value: l})
+	}
+	return g, nil
+}
+
+// oauthFlowNames are the names of the OAuth flows of a security scheme.
+var oauthFlowNames = []string{"implicit", "password", "clientCredentials", "authorizationCode"}
+
+// FromGnostic converts a gnostic security scheme, or reference to one, into
+// k. The extensions of the OAuth flows object itself have no counterpart in
+// SecurityScheme and are dropped.
+func (k *SecurityScheme) FromGnostic(g *openapi_v3.SecuritySchemeOrReference) error {
+	if g == nil {
+		return nil
+	}
+
+	if ref := g.GetReference(); ref != nil {
+		refable, err := refFromGnostic(ref)
+		if err != nil {
+			return err
+		}
+		*k = SecurityScheme{Refable: refable}
+		return nil
+	}
+
+	s := g.GetSecurityScheme()
+	if s == nil {
+		*k = SecurityScheme{}
+		return nil
+	}
+	ext, err := vendorExtensionsFromGnostic(s.SpecificationExtension)
+	if err != nil {
+		return err
+	}
+	var flows map[string]*OAuthFlow
+	if s.Flows != nil {
+		flows = map[string]*OAuthFlow{}
+		for i, f := range []*openapi_v3.OauthFlow{s.Flows.Implicit, s.Flows.Password, s.Flows.ClientCredentials, s.Flows.AuthorizationCode} {
+			if f == nil {
+				continue
+			}
+			flow := &OAuthFlow{}
+			if err := flow.FromGnostic(f); err != nil {
+				return fmt.Errorf("flow %q: %v", oauthFlowNames[i], err)
+			}
+			flows[oauthFlowNames[i]] = flow
+		}
+	}
+
+	*k = SecurityScheme{
+		SecuritySchemeProps: SecuritySchemeProps{
+			Type:             s.Type,
+			Description:      s.Description,
+			Name:             s.Name,
+			In:               s.In,
+			Scheme:           s.Scheme,
+			BearerFormat:     s.BearerFormat,
+			Flows:            flows,
+			OpenIdConnectUrl: s.OpenIdConnectUrl,
+		},
+		VendorExtensible: spec.VendorExtensible{Extensions: ext},
+	}
+	return nil
+}
+
+// ToGnostic converts k into a gnostic security scheme, or reference to one.
+// Flows with other names than the ones of the specification are dropped.
+func (k *SecurityScheme) ToGnostic() (*openapi_v3.SecuritySchemeOrReference, error) {
+	if k == nil {
+		return nil, nil
+	}
+
+	if ref := k.Ref.String(); ref != "" {
+		return &openapi_v3.SecuritySchemeOrReference{
+			Oneof: &openapi_v3.SecuritySchemeOrReference_Reference{Reference: &openapi_v3.Reference{XRef: ref}},
+		}, nil
+	}
+
+	ext, err := vendorExtensionsToGnostic(k.Extensions)
+	if err != nil {
+		return nil, err
+	}
+	var flows *openapi_v3.OauthFlows
+	if k.Flows != nil {
+		flows = &openapi_v3.OauthFlows{}
+		for i, f := range []**openapi_v3.OauthFlow{&flows.Implicit, &flows.Password, &flows.ClientCredentials, &flows.AuthorizationCode} {
+			if *f, err = k.Flows[oauthFlowNames[i]].ToGnostic(); err != nil {
+				return nil, fmt.Errorf("flow %q: %v", oauthFlowNames[i], err)
+			}
+		}
+	}
+
+	return &openapi_v3.SecuritySchemeOrReference{
+		Oneof: &openapi_v3.SecuritySchemeOrReference_SecurityScheme{SecurityScheme: &openapi_v3.SecurityScheme{
+			Type:                   k.Type,
+			Description:            k.Description,
+			Name:                   k.Name,
+			In:                     k.In,
+			Scheme:                 k.Scheme,
+			BearerFormat:           k.BearerFormat,
+			Flows:                  flows,
+			OpenIdConnectUrl:       k.OpenIdConnectUrl,
+			SpecificationExtension: ext,
+		}},
+	}, nil
+}
+
+// FromGnostic converts a gnostic OAuth flow into k.
+func (k *OAuthFlow) FromGnostic(g *openapi_v3.OauthFlow) error {
+	if g == nil {
+		return nil
+	}
+
+	ext, err := vendorExtensionsFromGnostic(g.SpecificationExtension)
+	if err != nil {
+		return err
+	}
+	var scopes map[string]string
+	if g.Scopes != nil {
+		scopes = make(map[string]string, len(g.Scopes.AdditionalProperties))
+		for _, named := range g.Scopes.AdditionalProperties {
+			scopes[named.Name] = named.Value
+		}
+	}
+
+	*k = OAuthFlow{
+		OAuthFlowProps: OAuthFlowProps{
+			AuthorizationUrl: g.AuthorizationUrl,
+			TokenUrl:         g.TokenUrl,
+			RefreshUrl:       g.RefreshUrl,
+			Scopes:           scopes,
+		},
+		VendorExtensible: spec.VendorExtensible{Extensions: ext},
+	}
+	return nil
+}
+
+// ToGnostic converts k into a gnostic OAuth flow.
+func (k *OAuthFlow) ToGnostic() (*openapi_v3.OauthFlow, error) {
+	if k == nil {
+		return nil, nil
+	}
+
+	ext, err := vendorExtensionsToGnostic(k.Extensions)
+	if err != nil {
+		return nil, err
+	}
+	var scopes *openapi_v3.Strings
+	if k.Scopes != nil {
+		scopes = &openapi_v3.Strings{}
+		for _, name := range sortedKeys(k.Scopes) {
+			scopes.AdditionalProperties = append(scopes.AdditionalProperties, &openapi_v3.NamedString{Name: name, Value: k.Scopes[name]})
+		}
+	}
+
+	return &openapi_v3.OauthFlow{
+		AuthorizationUrl:       k.AuthorizationUrl,
+		TokenUrl:               k.TokenUrl,
+		RefreshUrl:             k.RefreshUrl,
+		Scopes:                 scopes,
+		SpecificationExtension: ext,
+	}, nil
+}
+
+// FromGnostic converts a gnostic server variable into k.
+func (k *ServerVariable) FromGnostic(g *openapi_v3.ServerVariable) error {
+	if g == nil {
+		return nil
+	}
+
+	ext, err := vendorExtensionsFromGnostic(g.SpecificationExtension)
+	if err != nil {
+		return err
+	}
+
+	*k = ServerVariable{
+		ServerVariableProps: ServerVariableProps{
+			Enum:        g.Enum,
+			Default:     g.Default,
+			Description: g.Description,
+		},
+		VendorExtensible: spec.VendorExtensible{Extensions: ext},
+	}
+	return nil
+}
+
+// ToGnostic converts k into a gnostic server variable.
+func (k *ServerVariable) ToGnostic() (*openapi_v3.ServerVariable, error) {
+	if k == nil {
+		return nil, nil
+	}
+
+	ext, err := vendorExtensionsToGnostic(k.Extensions)
+	if err != nil {
+		return nil, err
+	}
+
+	return &openapi_v3.ServerVariable{
+		Enum:                   k.Enum,
+		Default:                k.Default,
+		Description:            k.Description,
+		SpecificationExtension: ext,
+	}, nil
+}
+
+// FromGnostic converts a gnostic document into k. Components and the
+// document-level security requirements are converted like by
+// Components.FromGnostic and SecurityRequirementsFromGnostic, the rest of the
+// document through its YAML form.
+func (k *OpenAPI) FromGnostic(g *openapi_v3.Document) error {
+	if g == nil {
+		return nil
+	}
+
+	o := OpenAPI{}
+	if err := decodeGnosticNode(g.ToRawInfo(), &o); err != nil {
+		return err
+	}
+	o.Components = nil
+	if g.Components != nil {
+		o.Components = &Components{}
+		if err := o.Components.FromGnostic(g.Components); err != nil {
+			return fmt.Errorf("components: %v", err)
+		}
+	}
+	o.SecurityRequirement = SecurityRequirementsFromGnostic(g.Security)
+	*k = o
+	return nil
+}
+
+// ToGnostic converts k into a gnostic document. Schemas are converted through
+// their YAML form, like in the other converters of this package.
+func (k *OpenAPI) ToGnostic() (*openapi_v3.Document, error) {
+	if k == nil {
+		return nil, nil
+	}
+
+	info, err := infoToGnostic(k.Info)
+	if err != nil {
+		return nil, err
+	}
+	paths, err := k.Paths.ToGnostic()
+	if err != nil {
+		return nil, err
+	}
+	servers, err := serversToGnostic(k.Servers)
+	if err != nil {
+		return nil, err
+	}
+	components, err := k.Components.ToGnostic()
+	if err != nil {
+		return nil, err
+	}
+	externalDocs, err := k.ExternalDocs.ToGnostic()
+	if err != nil {
+		return nil, err
+	}
+
+	return &openapi_v3.Document{
+		Openapi:      k.Version,
+		Info:         info,
+		Servers:      servers,
+		Paths:        paths,
+		Components:   components,
+		Security:     SecurityRequirementsToGnostic(k.SecurityRequirement),
+		ExternalDocs: externalDocs,
+	}, nil
+}
+
+// infoToGnostic converts info into a gnostic info.
+func infoToGnostic(info *spec.Info) (*openapi_v3.Info, error) {
+	if info == nil {
+		return nil, nil
+	}
+
+	ext, err := vendorExtensionsToGnostic(info.Extensions)
+	if err != nil {
+		return nil, err
+	}
+	var contact *openapi_v3.Contact
+	if info.Contact != nil {
+		contact = &openapi_v3.Contact{Name: info.Contact.Name, Url: info.Contact.URL, Email: info.Contact.Email}
+	}
+	var license *openapi_v3.License
+	if info.License != nil {
+		license = &openapi_v3.License{Name: info.License.Name, Url: info.License.URL}
+	}
+
+	return &openapi_v3.Info{
+		Title:                  info.Title,
+		Description:            info.Description,
+		TermsOfService:         info.TermsOfService,
+		Contact:                contact,
+		License:                license,
+		Version:                info.Version,
+		SpecificationExtension: ext,
+	}, nil
+}
+
+// ToGnostic converts k into gnostic paths, sorted by path.
+func (k *Paths) ToGnostic() (*openapi_v3.Paths, error) {
+	if k == nil {
+		return nil, nil
+	}
+
+	ext, err := vendorExtensionsToGnostic(k.Extensions)
+	if err != nil {
+		return nil, err
+	}
+	g := &openapi_v3.Paths{SpecificationExtension: ext}
+	for _, name := range sortedKeys(k.Paths) {
+		item, err := k.Paths[name].ToGnostic()
+		if err != nil {
+			return nil, fmt.Errorf("path %q: %v", name, err)
+		}
+		g.Path = append(g.Path, &openapi_v3.NamedPathItem{Name: name, Value: item})
+	}
+	return g, nil
+}
+
+// ToGnostic converts k into a gnostic path item. Unlike the other objects,
+// path items hold their reference next to their fields.
+func (k *Path) ToGnostic() (*openapi_v3.PathItem, error) {
+	if k == nil {
+		return nil, nil
+	}
+
+	ext, err := vendorExtensionsToGnostic(k.Extensions)
+	if err != nil {
+		return nil, err
+	}
+	g := &openapi_v3.PathItem{
+		XRef:                   k.Ref.String(),
+		Summary:                k.Summary,
+		Description:            k.Description,
+		SpecificationExtension: ext,
+	}
+	for _, op := range []struct {
+		name string
+		from *Operation
+		to   **openapi_v3.Operation
+	}{
+		{"get", k.Get, &g.Get},
+		{"put", k.Put, &g.Put},
+		{"post", k.Post, &g.Post},
+		{"delete", k.Delete, &g.Delete},
+		{"options", k.Options, &g.Options},
+		{"head", k.Head, &g.Head},
+		{"patch", k.Patch, &g.Patch},
+		{"trace", k.Trace, &g.Trace},
+	} {
+		if *op.to, err = op.from.ToGnostic(); err != nil {
+			return nil, fmt.Errorf("%s: %v", op.name, err)
+		}
+	}
+	if g.Servers, err = serversToGnostic(k.Servers); err != nil {
+		return nil, err
+	}
+	if g.Parameters, err = parametersToGnostic(k.Parameters); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// ToGnostic converts k into a gnostic operation.
+func (k *Operation) ToGnostic() (*openapi_v3.Operation, error) {
+	if k == nil {
+		return nil, nil
+	}
+
+	ext, err := vendorExtensionsToGnostic(k.Extensions)
+	if err != nil {
+		return nil, err
+	}
+	externalDocs, err := k.ExternalDocs.ToGnostic()
+	if err != nil {
+		return nil, err
+	}
+	parameters, err := parametersToGnostic(k.Parameters)
+	if err != nil {
+		return nil, err
+	}
+	requestBody, err := k.RequestBody.ToGnostic()
+	if err != nil {
+		return nil, err
+	}
+	responses, err := k.Responses.ToGnostic()
+	if err != nil {
+		return nil, err
+	}
+	servers, err := serversToGnostic(k.Servers)
+	if err != nil {
+		return nil, err
+	}
+
+	return &openapi_v3.Operation{
+		Tags:                   k.Tags,
+		Summary:                k.Summary,
+		Description:            k.Description,
+		ExternalDocs:           externalDocs,
+		OperationId:            k.OperationId,
+		Parameters:             parameters,
+		RequestBody:            requestBody,
+		Responses:              responses,
+		Deprecated:             k.Deprecated,
+		Security:               SecurityRequirementsToGnostic(k.SecurityRequirement),
+		Servers:                servers,
+		SpecificationExtension: ext,
+	}, nil
+}
+
+// ToGnostic converts k into a gnostic parameter, or reference to one.
+func (k *Parameter) ToGnostic() (*openapi_v3.ParameterOrReference, error) {
+	if k == nil {
+		return nil, nil
+	}
+
+	if ref := k.Ref.String(); ref != "" {
+		return &openapi_v3.ParameterOrReference{
+			Oneof: &openapi_v3.ParameterOrReference_Reference{Reference: &openapi_v3.Reference{XRef: ref}},
+		}, nil
+	}
+
+	ext, err := vendorExtensionsToGnostic(k.Extensions)
+	if err != nil {
+		return nil, err
+	}
+	schema, err := schemaToGnostic(k.Schema)
+	if err != nil {
+		return nil, err
+	}
+	var content *openapi_v3.MediaTypes
+	if k.Content != nil {
+		content = &openapi_v3.MediaTypes{}
+		for _, name := range sortedKeys(k.Content) {
+			mediaType, err := k.Content[name].ToGnostic()
+			if err != nil {
+				return nil, err
+			}
+			content.AdditionalProperties = append(content.AdditionalProperties, &openapi_v3.NamedMediaType{Name: name, Value: mediaType})
+		}
+	}
+	example, err := anyToGnostic(k.Example)
+	if err != nil {
+		return nil, err
+	}
+	var examples *openapi_v3.ExamplesOrReferences
+	if k.Examples != nil {
+		examples = &openapi_v3.ExamplesOrReferences{}
+		for _, name := range sortedKeys(k.Examples) {
+			e, err := k.Examples[name].ToGnostic()
+			if err != nil {
+				return nil, err
+			}
+			examples.AdditionalProperties = append(examples.AdditionalProperties, &openapi_v3.NamedExampleOrReference{Name: name, Value: e})
+		}
+	}
+
+	return &openapi_v3.ParameterOrReference{
+		Oneof: &openapi_v3.ParameterOrReference_Parameter{Parameter: &openapi_v3.Parameter{
+			Name:                   k.Name,
+			In:                     k.In,
+			Description:            k.Description,
+			Required:               k.Required,
+			Deprecated:             k.Deprecated,
+			AllowEmptyValue:        k.AllowEmptyValue,
+			Style:                  k.Style,
+			Explode:                k.Explode,
+			AllowReserved:          k.AllowReserved,
+			Schema:                 schema,
+			Example:                example,
+			Examples:               examples,
+			Content:                content,
+			SpecificationExtension: ext,
+		}},
+	}, nil
+}
+
+func parametersToGnostic(parameters []*Parameter) ([]*openapi_v3.ParameterOrReference, error) {
+	if parameters == nil {
+		return nil, nil
+	}
+	g := make([]*openapi_v3.ParameterOrReference, 0, len(parameters))
+	for i, p := range parameters {
+		gp, err := p.ToGnostic()
+		if err != nil {
+			return nil, fmt.Errorf("parameter %d: %v", i, err)
+		}
+		g = append(g, gp)
+	}
+	return g, nil
+}
+
+// ToGnostic converts k into a gnostic server.
+func (k *Server) ToGnostic() (*openapi_v3.Server, error) {
+	if k == nil {
+		return nil, nil
+	}
+
+	ext, err := vendorExtensionsToGnostic(k.Extensions)
+	if err != nil {
+		return nil, err
+	}
+	var variables *openapi_v3.ServerVariables
+	if k.Variables != nil {
+		variables = &openapi_v3.ServerVariables{}
+		for _, name := range sortedKeys(k.Variables) {
+			v, err := k.Variables[name].ToGnostic()
+			if err != nil {
+				return nil, err
+			}
+			variables.AdditionalProperties = append(variables.AdditionalProperties, &openapi_v3.NamedServerVariable{Name: name, Value: v})
+		}
+	}
+
+	return &openapi_v3.Server{
+		Url:                    k.URL,
+		Description:            k.Description,
+		Variables:              variables,
+		SpecificationExtension: ext,
+	}, nil
+}
+
+func serversToGnostic(servers []*Server) ([]*openapi_v3.Server, error) {
+	if servers == nil {
+		return nil, nil
+	}
+	g := make([]*openapi_v3.Server, 0, len(servers))
+	for _, s := range servers {
+		gs, err := s.ToGnostic()
+		if err != nil {
+			return nil, err
+		}
+		g = append(g, gs)
+	}
+	return g, nil
+}
+
+// ToGnostic converts k into gnostic external documentation.
+func (k *ExternalDocumentation) ToGnostic() (*openapi_v3.ExternalDocs, error) {
+	if k == nil {
+		return nil, nil
+	}
+
+	ext, err := vendorExtensionsToGnostic(k.Extensions)
+	if err != nil {
+		return nil, err
+	}
+	return &openapi_v3.ExternalDocs{
+		Description:            k.Description,
+		Url:                    k.URL,
+		SpecificationExtension: ext,
+	}, nil
+}
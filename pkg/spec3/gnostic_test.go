@@ -0,0 +1,799 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec3_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	openapi_v3 "github.com/google/gnostic/openapiv3"
+	"gopkg.in/yaml.v3"
+	"k8s.io/kube-openapi/pkg/spec3"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+func TestServerVariableGnosticRoundTrip(t *testing.T) {
+	orig := &spec3.ServerVariable{
+		ServerVariableProps: spec3.ServerVariableProps{
+			Enum:        []string{"eu", "us"},
+			Default:     "us",
+			Description: "region",
+		},
+		VendorExtensible: spec.VendorExtensible{Extensions: spec.Extensions{
+			"x-string": "foo",
+			"x-bool":   true,
+			"x-object": map[string]interface{}{"a": "b"},
+			"x-list":   []interface{}{"c", "d"},
+		}},
+	}
+
+	g, err := orig.ToGnostic()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(g.SpecificationExtension) != 4 || g.SpecificationExtension[0].Name != "x-bool" {
+		t.Errorf("expected sorted gnostic extensions, got %v", g.SpecificationExtension)
+	}
+
+	var got spec3.ServerVariable
+	if err := got.FromGnostic(g); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(orig, &got) {
+		t.Errorf("round trip mismatch:\nwant %#v\ngot  %#v", orig, &got)
+	}
+}
+
+func TestServerVariableFromGnostic(t *testing.T) {
+	var got spec3.ServerVariable
+	if err := got.FromGnostic(&openapi_v3.ServerVariable{
+		Default: "v1",
+		SpecificationExtension: []*openapi_v3.NamedAny{
+			{Name: "x-null"},
+			{Name: "x-number", Value: &openapi_v3.Any{Yaml: "1.5\n"}},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	expected := spec3.ServerVariable{
+		ServerVariableProps: spec3.ServerVariableProps{Default: "v1"},
+		VendorExtensible: spec.VendorExtensible{Extensions: spec.Extensions{
+			"x-null":   nil,
+			"x-number": 1.5,
+		}},
+	}
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("want %#v\ngot  %#v", expected, got)
+	}
+}
+
+func TestRequestBodyGnosticRoundTrip(t *testing.T) {
+	var tests = []struct {
+		name string
+		body *spec3.RequestBody
+	}{
+		{
+			name: "reference",
+			body: &spec3.RequestBody{Refable: spec.Refable{Ref: spec.MustCreateRef("#/components/requestBodies/pod")}},
+		},
+		{
+			name: "full",
+			body: &spec3.RequestBody{
+				RequestBodyProps: spec3.RequestBodyProps{
+					Description: "the pod",
+					Required:    true,
+					Content: map[string]*spec3.MediaType{
+						"application/json": {
+							MediaTypeProps: spec3.MediaTypeProps{
+								Schema:  spec.RefSchema("#/components/schemas/io.k8s.api.core.v1.Pod"),
+								Example: map[string]interface{}{"kind": "Pod"},
+								Examples: map[string]*spec3.Example{
+									"minimal": {
+										ExampleProps: spec3.ExampleProps{Summary: "minimal pod", Value: map[string]interface{}{"kind": "Pod"}},
+										VendorExtensible: spec.VendorExtensible{Extensions: spec.Extensions{
+											"x-example": "foo",
+										}},
+									},
+									"shared": {Refable: spec.Refable{Ref: spec.MustCreateRef("#/components/examples/pod")}},
+								},
+							},
+						},
+						"multipart/form-data": {
+							MediaTypeProps: spec3.MediaTypeProps{
+								Schema: &spec.Schema{SchemaProps: spec.SchemaProps{
+									Type: []string{"object"},
+									Properties: map[string]spec.Schema{
+										"file": {SchemaProps: spec.SchemaProps{Type: []string{"string"}, Format: "binary"}},
+									},
+								}},
+								Encoding: map[string]*spec3.Encoding{
+									"file": {
+										EncodingProps: spec3.EncodingProps{
+											ContentType: "application/octet-stream",
+											Style:       "form",
+											Explode:     true,
+											Headers: map[string]*spec3.Header{
+												"X-Rate-Limit": {HeaderProps: spec3.HeaderProps{
+													Description: "rate limit",
+													Schema:      &spec.Schema{SchemaProps: spec.SchemaProps{Type: []string{"integer"}}},
+												}},
+											},
+										},
+									},
+								},
+							},
+							VendorExtensible: spec.VendorExtensible{Extensions: spec.Extensions{
+								"x-media": []interface{}{"a", "b"},
+							}},
+						},
+					},
+				},
+				VendorExtensible: spec.VendorExtensible{Extensions: spec.Extensions{
+					"x-kubernetes-body": true,
+				}},
+			},
+		},
+		{
+			name: "empty",
+			body: &spec3.RequestBody{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := tt.body.ToGnostic()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got spec3.RequestBody
+			if err := got.FromGnostic(g); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(tt.body, &got) {
+				t.Errorf("round trip mismatch:\nwant %#v\ngot  %#v", tt.body, &got)
+			}
+		})
+	}
+}
+
+func TestRequestBodyFromGnosticDocument(t *testing.T) {
+	doc := `{
+  "openapi": "3.0.0",
+  "info": {"title": "test", "version": "v1"},
+  "paths": {
+    "/pods": {
+      "post": {
+        "requestBody": {
+          "description": "the pod",
+          "required": true,
+          "x-kubernetes-body": {"a": ["b"]},
+          "content": {
+            "application/json": {
+              "schema": {"type": "object", "properties": {"name": {"type": "string", "maxLength": 10}}},
+              "examples": {"shared": {"$ref": "#/components/examples/pod"}}
+            },
+            "application/yaml": {
+              "schema": {"$ref": "#/components/schemas/Pod"},
+              "example": "kind: Pod"
+            }
+          }
+        },
+        "responses": {"200": {"description": "OK"}}
+      },
+      "put": {
+        "requestBody": {"$ref": "#/components/requestBodies/pod"},
+        "responses": {"200": {"description": "OK"}}
+      }
+    }
+  }
+}`
+	g, err := openapi_v3.ParseDocument([]byte(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var expected spec3.OpenAPI
+	if err := json.Unmarshal([]byte(doc), &expected); err != nil {
+		t.Fatal(err)
+	}
+
+	var post, put spec3.RequestBody
+	if err := post.FromGnostic(g.Paths.Path[0].Value.Post.RequestBody); err != nil {
+		t.Fatal(err)
+	}
+	if err := put.FromGnostic(g.Paths.Path[0].Value.Put.RequestBody); err != nil {
+		t.Fatal(err)
+	}
+	if want := expected.Paths.Paths["/pods"].Post.RequestBody; !reflect.DeepEqual(want, &post) {
+		t.Errorf("want %#v\ngot  %#v", want, &post)
+	}
+	if want := expected.Paths.Paths["/pods"].Put.RequestBody; !reflect.DeepEqual(want, &put) {
+		t.Errorf("want %#v\ngot  %#v", want, &put)
+	}
+}
+
+func TestSecurityRequirementsGnostic(t *testing.T) {
+	doc := `{
+  "openapi": "3.0.0",
+  "info": {"title": "test", "version": "v1"},
+  "security": [
+    {"BearerToken": [], "oauth": ["read", "write"]},
+    {"mTLS": []},
+    {}
+  ],
+  "paths": {
+    "/pods": {
+      "get": {
+        "security": [{"oauth": ["read"], "apiKey": []}],
+        "responses": {"200": {"description": "OK"}}
+      }
+    }
+  }
+}`
+	g, err := openapi_v3.ParseDocument([]byte(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var expected spec3.OpenAPI
+	if err := json.Unmarshal([]byte(doc), &expected); err != nil {
+		t.Fatal(err)
+	}
+
+	got := spec3.SecurityRequirementsFromGnostic(g.Security)
+	want := []map[string][]string{
+		{"BearerToken": {}, "oauth": {"read", "write"}},
+		{"mTLS": {}},
+		{},
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("want %#v\ngot  %#v", want, got)
+	}
+	opGot := spec3.SecurityRequirementsFromGnostic(g.Paths.Path[0].Value.Get.Security)
+	if want := expected.Paths.Paths["/pods"].Get.SecurityRequirement; !reflect.DeepEqual(want, opGot) {
+		t.Errorf("want %#v\ngot  %#v", want, opGot)
+	}
+
+	roundTripped := spec3.SecurityRequirementsFromGnostic(spec3.SecurityRequirementsToGnostic(got))
+	if !reflect.DeepEqual(got, roundTripped) {
+		t.Errorf("round trip mismatch:\nwant %#v\ngot  %#v", got, roundTripped)
+	}
+	if names := spec3.SecurityRequirementsToGnostic(got)[0].AdditionalProperties; names[0].Name != "BearerToken" || names[1].Name != "oauth" {
+		t.Errorf("expected schemes sorted by name, got %v", names)
+	}
+
+	if spec3.SecurityRequirementsFromGnostic(nil) != nil || spec3.SecurityRequirementsToGnostic(nil) != nil {
+		t.Error("expected nil requirements to stay nil")
+	}
+}
+
+const componentsDoc = `{
+  "openapi": "3.0.0",
+  "info": {"title": "test", "version": "v1"},
+  "paths": {},
+  "components": {
+    "schemas": {
+      "Pod": {
+        "type": "object",
+        "x-kubernetes-group-version-kind": [{"group": "", "kind": "Pod", "version": "v1"}],
+        "properties": {"spec": {"$ref": "#/components/schemas/PodSpec"}}
+      },
+      "PodSpec": {"type": "object", "additionalProperties": {"type": "string"}}
+    },
+    "responses": {
+      "NotFound": {
+        "description": "not found",
+        "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Status"}}}
+      },
+      "Shared": {"$ref": "#/components/responses/NotFound"}
+    },
+    "parameters": {
+      "name": {"name": "name", "in": "path", "required": true, "schema": {"type": "string"}, "x-param": true},
+      "shared": {"$ref": "#/components/parameters/name"}
+    },
+    "examples": {
+      "pod": {"summary": "a pod", "value": {"kind": "Pod"}}
+    },
+    "requestBodies": {
+      "pod": {"required": true, "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Pod"}}}},
+      "shared": {"$ref": "#/components/requestBodies/pod"}
+    },
+    "headers": {
+      "X-Rate-Limit": {"description": "rate limit", "schema": {"type": "integer"}}
+    },
+    "securitySchemes": {
+      "BearerToken": {"type": "apiKey", "name": "authorization", "in": "header", "x-scheme": "bearer"},
+      "oauth": {
+        "type": "oauth2",
+        "flows": {"clientCredentials": {"tokenUrl": "https://example.com/token", "scopes": {"read": "read access"}}}
+      }
+    },
+    "links": {
+      "pod": {"operationId": "readPod", "parameters": {"name": "$response.body#/metadata/name"}}
+    }
+  }
+}`
+
+func TestComponentsGnostic(t *testing.T) {
+	doc := componentsDoc
+	g, err := openapi_v3.ParseDocument([]byte(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var expected spec3.OpenAPI
+	if err := json.Unmarshal([]byte(doc), &expected); err != nil {
+		t.Fatal(err)
+	}
+
+	var got spec3.Components
+	if err := got.FromGnostic(g.Components); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(expected.Components, &got) {
+		t.Errorf("want %#v\ngot  %#v", expected.Components, &got)
+	}
+
+	gc, err := got.ToGnostic()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var roundTripped spec3.Components
+	if err := roundTripped.FromGnostic(gc); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(&got, &roundTripped) {
+		t.Errorf("round trip mismatch:\nwant %#v\ngot  %#v", &got, &roundTripped)
+	}
+}
+
+func TestResponsesGnostic(t *testing.T) {
+	doc := `{
+  "openapi": "3.0.0",
+  "info": {"title": "test", "version": "v1"},
+  "paths": {
+    "/pods": {
+      "get": {
+        "responses": {
+          "x-kubernetes-responses": {"a": "b"},
+          "default": {"$ref": "#/components/responses/Status"},
+          "200": {
+            "description": "OK",
+            "x-response": true,
+            "headers": {
+              "X-Rate-Limit": {"description": "rate limit", "schema": {"type": "integer"}},
+              "X-Shared": {"$ref": "#/components/headers/Shared"}
+            },
+            "content": {
+              "application/json": {"schema": {"$ref": "#/components/schemas/PodList"}},
+              "application/yaml": {"schema": {"type": "string"}, "example": "kind: PodList"}
+            },
+            "links": {
+              "pod": {"operationId": "readPod", "parameters": {"name": "$response.body#/items/0/metadata/name"}},
+              "shared": {"$ref": "#/components/links/Shared"}
+            }
+          },
+          "401": {"description": "Unauthorized"}
+        }
+      }
+    }
+  }
+}`
+	g, err := openapi_v3.ParseDocument([]byte(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var expected spec3.OpenAPI
+	if err := json.Unmarshal([]byte(doc), &expected); err != nil {
+		t.Fatal(err)
+	}
+
+	var got spec3.Responses
+	if err := got.FromGnostic(g.Paths.Path[0].Value.Get.Responses); err != nil {
+		t.Fatal(err)
+	}
+	if want := expected.Paths.Paths["/pods"].Get.Responses; !reflect.DeepEqual(want, &got) {
+		t.Errorf("want %#v\ngot  %#v", want, &got)
+	}
+
+	gr, err := got.ToGnostic()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var roundTripped spec3.Responses
+	if err := roundTripped.FromGnostic(gr); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(&got, &roundTripped) {
+		t.Errorf("round trip mismatch:\nwant %#v\ngot  %#v", &got, &roundTripped)
+	}
+}
+
+func TestResponsesGnosticRangeCode(t *testing.T) {
+	doc := `{
+  "openapi": "3.0.0",
+  "info": {"title": "test", "version": "v1"},
+  "paths": {
+    "/pods": {
+      "get": {
+        "responses": {
+          "200": {"description": "OK"},
+          "2XX": {"description": "success"}
+        }
+      }
+    }
+  }
+}`
+	g, err := openapi_v3.ParseDocument([]byte(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got spec3.Responses
+	err = got.FromGnostic(g.Paths.Path[0].Value.Get.Responses)
+	if err == nil || !strings.Contains(err.Error(), `"2XX"`) {
+		t.Errorf("expected an error for the 2XX response, got %v", err)
+	}
+}
+
+func TestHeaderGnostic(t *testing.T) {
+	doc := `{
+  "openapi": "3.0.0",
+  "info": {"title": "test", "version": "v1"},
+  "paths": {
+    "/pods": {
+      "get": {
+        "responses": {
+          "200": {
+            "description": "OK",
+            "headers": {
+              "X-Request-Id": {
+                "description": "request id",
+                "required": true,
+                "deprecated": true,
+                "style": "simple",
+                "explode": true,
+                "schema": {"type": "string", "maxLength": 36},
+                "example": "b7ad6b7169203331",
+                "examples": {"short": {"value": "a1"}, "shared": {"$ref": "#/components/examples/requestId"}},
+                "x-header": {"a": "b"}
+              },
+              "X-Trace": {
+                "content": {"application/json": {"schema": {"type": "object"}}}
+              },
+              "X-Shared": {"$ref": "#/components/headers/Shared"}
+            }
+          }
+        }
+      }
+    }
+  }
+}`
+	g, err := openapi_v3.ParseDocument([]byte(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var expected spec3.OpenAPI
+	if err := json.Unmarshal([]byte(doc), &expected); err != nil {
+		t.Fatal(err)
+	}
+	want := expected.Paths.Paths["/pods"].Get.Responses.StatusCodeResponses[200].Headers
+
+	for _, named := range g.Paths.Path[0].Value.Get.Responses.ResponseOrReference[0].Value.GetResponse().Headers.AdditionalProperties {
+		var got spec3.Header
+		if err := got.FromGnostic(named.Value); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(want[named.Name], &got) {
+			t.Errorf("%s: want %#v\ngot  %#v", named.Name, want[named.Name], &got)
+		}
+
+		gh, err := got.ToGnostic()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var roundTripped spec3.Header
+		if err := roundTripped.FromGnostic(gh); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(&got, &roundTripped) {
+			t.Errorf("%s: round trip mismatch:\nwant %#v\ngot  %#v", named.Name, &got, &roundTripped)
+		}
+	}
+}
+
+func TestLinkGnostic(t *testing.T) {
+	doc := `{
+  "openapi": "3.0.0",
+  "info": {"title": "test", "version": "v1"},
+  "paths": {},
+  "components": {
+    "links": {
+      "byId": {
+        "operationId": "readPod",
+        "description": "the created pod",
+        "parameters": {"name": "$response.body#/metadata/name", "pretty": "true"},
+        "requestBody": {"spec": "$request.body#/spec"},
+        "server": {"url": "https://{host}/api", "variables": {"host": {"default": "example.com"}}},
+        "x-link": ["a"]
+      },
+      "byRef": {
+        "operationRef": "#/paths/~1api~1v1~1pods~1{name}/get",
+        "requestBody": {"kind": "Pod"}
+      },
+      "shared": {"$ref": "#/components/links/byId"}
+    }
+  }
+}`
+	g, err := openapi_v3.ParseDocument([]byte(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var expected spec3.OpenAPI
+	if err := json.Unmarshal([]byte(doc), &expected); err != nil {
+		t.Fatal(err)
+	}
+	if expected.Components.Links["byRef"].OperationRef == "" {
+		t.Fatal("expected operationRef to be decoded from JSON")
+	}
+
+	for _, named := range g.Components.Links.AdditionalProperties {
+		var got spec3.Link
+		if err := got.FromGnostic(named.Value); err != nil {
+			t.Fatal(err)
+		}
+		if want := expected.Components.Links[named.Name]; !reflect.DeepEqual(want, &got) {
+			t.Errorf("%s: want %#v\ngot  %#v", named.Name, want, &got)
+		}
+
+		gl, err := got.ToGnostic()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var roundTripped spec3.Link
+		if err := roundTripped.FromGnostic(gl); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(&got, &roundTripped) {
+			t.Errorf("%s: round trip mismatch:\nwant %#v\ngot  %#v", named.Name, &got, &roundTripped)
+		}
+	}
+	scalar := &spec3.Link{LinkProps: spec3.LinkProps{OperationId: "readPod", RequestBody: "$request.body#/spec"}}
+	gl, err := scalar.ToGnostic()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got spec3.Link
+	if err := got.FromGnostic(gl); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(scalar, &got) {
+		t.Errorf("round trip mismatch:\nwant %#v\ngot  %#v", scalar, &got)
+	}
+}
+
+func TestSecuritySchemeGnostic(t *testing.T) {
+	doc := `{
+  "openapi": "3.0.0",
+  "info": {"title": "test", "version": "v1"},
+  "paths": {},
+  "components": {
+    "securitySchemes": {
+      "apiKey": {"type": "apiKey", "description": "a key", "name": "X-API-Key", "in": "header", "x-scheme": "key"},
+      "bearer": {"type": "http", "scheme": "bearer", "bearerFormat": "JWT"},
+      "oidc": {"type": "openIdConnect", "openIdConnectUrl": "https://example.com/.well-known/openid-configuration"},
+      "oauth": {
+        "type": "oauth2",
+        "flows": {
+          "implicit": {"authorizationUrl": "https://example.com/authorize", "scopes": {"read": "read access"}},
+          "password": {"tokenUrl": "https://example.com/token", "refreshUrl": "https://example.com/refresh", "scopes": {}},
+          "clientCredentials": {"tokenUrl": "https://example.com/token", "scopes": {"read": "read access", "write": "write access"}, "x-flow": true},
+          "authorizationCode": {"authorizationUrl": "https://example.com/authorize", "tokenUrl": "https://example.com/token", "scopes": {"admin": "full access"}}
+        }
+      },
+      "shared": {"$ref": "#/components/securitySchemes/oauth"}
+    }
+  }
+}`
+	g, err := openapi_v3.ParseDocument([]byte(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var expected spec3.OpenAPI
+	if err := json.Unmarshal([]byte(doc), &expected); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, named := range g.Components.SecuritySchemes.AdditionalProperties {
+		var got spec3.SecurityScheme
+		if err := got.FromGnostic(named.Value); err != nil {
+			t.Fatal(err)
+		}
+		if want := expected.Components.SecuritySchemes[named.Name]; !reflect.DeepEqual(want, &got) {
+			t.Errorf("%s: want %#v\ngot  %#v", named.Name, want, &got)
+		}
+
+		gs, err := got.ToGnostic()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var roundTripped spec3.SecurityScheme
+		if err := roundTripped.FromGnostic(gs); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(&got, &roundTripped) {
+			t.Errorf("%s: round trip mismatch:\nwant %#v\ngot  %#v", named.Name, &got, &roundTripped)
+		}
+	}
+}
+
+func TestOpenAPIToGnostic(t *testing.T) {
+	doc := `{
+  "openapi": "3.0.0",
+  "info": {
+    "title": "test",
+    "version": "v1",
+    "description": "a test API",
+    "contact": {"name": "sig-api-machinery", "url": "https://example.com", "email": "api@example.com"},
+    "license": {"name": "Apache 2.0", "url": "https://www.apache.org/licenses/LICENSE-2.0"},
+    "x-info": "info"
+  },
+  "servers": [{"url": "https://{host}/", "variables": {"host": {"default": "example.com", "enum": ["example.com"]}}}],
+  "paths": {
+    "/api/v1/pods/{name}": {
+      "summary": "a pod",
+      "parameters": [
+        {"$ref": "#/components/parameters/name"},
+        {"name": "pretty", "in": "query", "schema": {"type": "string"}, "example": "true", "x-param": 1}
+      ],
+      "get": {
+        "tags": ["core_v1"],
+        "operationId": "readPod",
+        "description": "read the specified Pod",
+        "externalDocs": {"url": "https://kubernetes.io/docs"},
+        "responses": {
+          "200": {"description": "OK", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Pod"}}}},
+          "default": {"$ref": "#/components/responses/NotFound"}
+        },
+        "security": [{"BearerToken": []}],
+        "x-kubernetes-action": "get"
+      },
+      "put": {
+        "operationId": "replacePod",
+        "deprecated": true,
+        "requestBody": {"required": true, "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Pod"}}}},
+        "responses": {"200": {"description": "OK"}},
+        "servers": [{"url": "https://write.example.com"}]
+      }
+    },
+    "/api/v1/pods/{name}/status": {"$ref": "#/paths/~1api~1v1~1pods~1{name}"},
+    "x-paths": true
+  },
+  "components": {
+    "schemas": {
+      "Pod": {"type": "object", "properties": {"kind": {"type": "string"}}, "x-kubernetes-group-version-kind": [{"group": "", "kind": "Pod", "version": "v1"}]}
+    },
+    "responses": {"NotFound": {"description": "not found"}},
+    "parameters": {"name": {"name": "name", "in": "path", "required": true, "schema": {"type": "string"}}},
+    "examples": {"pod": {"value": {"kind": "Pod"}}},
+    "requestBodies": {"pod": {"content": {"application/json": {"schema": {"$ref": "#/components/schemas/Pod"}}}}},
+    "headers": {"X-Rate-Limit": {"schema": {"type": "integer"}}},
+    "securitySchemes": {"BearerToken": {"type": "apiKey", "name": "authorization", "in": "header"}},
+    "links": {"pod": {"operationId": "readPod"}}
+  },
+  "security": [{"BearerToken": []}, {}],
+  "externalDocs": {"description": "docs", "url": "https://kubernetes.io"}
+}`
+	var expected spec3.OpenAPI
+	if err := json.Unmarshal([]byte(doc), &expected); err != nil {
+		t.Fatal(err)
+	}
+
+	g, err := expected.ToGnostic()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.Paths.Path[0].Name != "/api/v1/pods/{name}" || g.Paths.Path[1].Value.XRef != expected.Paths.Paths["/api/v1/pods/{name}/status"].Ref.String() {
+		t.Errorf("unexpected paths %v", g.Paths.Path)
+	}
+	data, err := g.YAMLValue("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := openapi_v3.ParseDocument(data); err != nil {
+		t.Fatalf("invalid document: %v\n%s", err, data)
+	}
+	var raw interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		t.Fatal(err)
+	}
+	if data, err = json.Marshal(raw); err != nil {
+		t.Fatal(err)
+	}
+	var got spec3.OpenAPI
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	// references are compared in their JSON form, since the parsed URLs of
+	// escaped and unescaped references differ
+	want, err := json.Marshal(&expected)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, err = json.Marshal(&got); err != nil {
+		t.Fatal(err)
+	}
+	if string(want) != string(data) {
+		t.Errorf("want %s\ngot  %s", want, data)
+	}
+
+	if g, err := (*spec3.OpenAPI)(nil).ToGnostic(); g != nil || err != nil {
+		t.Errorf("expected nil document, got %v, %v", g, err)
+	}
+
+	var fromGnostic spec3.OpenAPI
+	if err := fromGnostic.FromGnostic(g); err != nil {
+		t.Fatal(err)
+	}
+	if data, err = json.Marshal(&fromGnostic); err != nil {
+		t.Fatal(err)
+	}
+	if string(want) != string(data) {
+		t.Errorf("FromGnostic: want %s\ngot  %s", want, data)
+	}
+}
+
+func TestOpenAPIFromGnosticSecurity(t *testing.T) {
+	tests := []struct {
+		name     string
+		security string
+		expected []map[string][]string
+	}{
+		{
+			name:     "alternatives",
+			security: `"security": [{"BearerToken": []}, {"OAuth": ["read", "write"], "ApiKey": []}],`,
+			expected: []map[string][]string{
+				{"BearerToken": {}},
+				{"OAuth": {"read", "write"}, "ApiKey": {}},
+			},
+		},
+		{
+			name:     "optional",
+			security: `"security": [{}],`,
+			expected: []map[string][]string{{}},
+		},
+		{
+			name: "unset",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := `{"openapi": "3.0.0", "info": {"title": "test", "version": "v1"}, ` + tt.security + ` "paths": {}}`
+			g, err := openapi_v3.ParseDocument([]byte(doc))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got spec3.OpenAPI
+			if err := got.FromGnostic(g); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got.SecurityRequirement, tt.expected) {
+				t.Errorf("expected security %v, got %v", tt.expected, got.SecurityRequirement)
+			}
+			if got.Info == nil || got.Info.Title != "test" {
+				t.Errorf("expected the rest of the document to be converted, got %#v", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,380 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec3
+
+import (
+	"sort"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+// Normalize rewrites o in place into a canonical form, so that two
+// semantically equal documents are equal according to reflect.DeepEqual:
+//
+//   - lists whose order carries no meaning are sorted: tags, servers, the
+//     scopes of security requirements, and the required fields and types of
+//     schemas,
+//   - empty maps and lists, which are omitted when serialized, are set to nil,
+//   - references are re-parsed from their string form.
+//
+// Data shared between o and other documents is modified as well.
+func (o *OpenAPI) Normalize() {
+	if o == nil {
+		return
+	}
+	n := normalizer{schemas: map[*spec.Schema]bool{}}
+	o.Servers = n.servers(o.Servers)
+	o.SecurityRequirement = n.securityRequirements(o.SecurityRequirement)
+	if o.Paths != nil {
+		n.extensions(&o.Paths.VendorExtensible)
+		o.Paths.Paths = emptyMapToNil(o.Paths.Paths)
+		for _, p := range o.Paths.Paths {
+			n.path(p)
+		}
+	}
+	o.Webhooks = emptyMapToNil(o.Webhooks)
+	for _, p := range o.Webhooks {
+		n.path(p)
+	}
+	if c := o.Components; c != nil {
+		c.Schemas = emptyMapToNil(c.Schemas)
+		for _, s := range c.Schemas {
+			n.schema(s)
+		}
+		c.SecuritySchemes = emptyMapToNil(c.SecuritySchemes)
+		for _, s := range c.SecuritySchemes {
+			n.securityScheme(s)
+		}
+		c.Responses = emptyMapToNil(c.Responses)
+		for _, r := range c.Responses {
+			n.response(r)
+		}
+		c.Parameters = emptyMapToNil(c.Parameters)
+		for _, p := range c.Parameters {
+			n.parameter(p)
+		}
+		c.Examples = n.examples(c.Examples)
+		c.RequestBodies = emptyMapToNil(c.RequestBodies)
+		for _, b := range c.RequestBodies {
+			n.requestBody(b)
+		}
+		c.Links = n.links(c.Links)
+		c.Headers = n.headers(c.Headers)
+		c.PathItems = emptyMapToNil(c.PathItems)
+		for _, p := range c.PathItems {
+			n.path(p)
+		}
+	}
+	if o.ExternalDocs != nil {
+		n.extensions(&o.ExternalDocs.VendorExtensible)
+	}
+}
+
+type normalizer struct {
+	// schemas holds the schemas already normalized, which may be shared
+	// by several parts of the document.
+	schemas map[*spec.Schema]bool
+}
+
+func (n *normalizer) path(p *Path) {
+	if p == nil {
+		return
+	}
+	n.ref(&p.Ref)
+	n.extensions(&p.VendorExtensible)
+	for _, op := range []*Operation{p.Get, p.Put, p.Post, p.Delete, p.Options, p.Head, p.Patch, p.Trace} {
+		n.operation(op)
+	}
+	p.Servers = n.servers(p.Servers)
+	p.Parameters = n.parameters(p.Parameters)
+}
+
+func (n *normalizer) operation(op *Operation) {
+	if op == nil {
+		return
+	}
+	n.extensions(&op.VendorExtensible)
+	op.Tags = sortedStrings(op.Tags)
+	if op.ExternalDocs != nil {
+		n.extensions(&op.ExternalDocs.VendorExtensible)
+	}
+	op.Parameters = n.parameters(op.Parameters)
+	n.requestBody(op.RequestBody)
+	if r := op.Responses; r != nil {
+		n.extensions(&r.VendorExtensible)
+		n.response(r.Default)
+		r.StatusCodeResponses = emptyMapToNil(r.StatusCodeResponses)
+		for _, resp := range r.StatusCodeResponses {
+			n.response(resp)
+		}
+	}
+	op.SecurityRequirement = n.securityRequirements(op.SecurityRequirement)
+	op.Servers = n.servers(op.Servers)
+}
+
+func (n *normalizer) securityRequirements(reqs []map[string][]string) []map[string][]string {
+	for i, req := range reqs {
+		req = emptyMapToNil(req)
+		for name, scopes := range req {
+			req[name] = sortedStrings(scopes)
+		}
+		reqs[i] = req
+	}
+	return emptySliceToNil(reqs)
+}
+
+func (n *normalizer) parameters(params []*Parameter) []*Parameter {
+	for _, p := range params {
+		n.parameter(p)
+	}
+	return emptySliceToNil(params)
+}
+
+func (n *normalizer) parameter(p *Parameter) {
+	if p == nil {
+		return
+	}
+	n.ref(&p.Ref)
+	n.extensions(&p.VendorExtensible)
+	n.schema(p.Schema)
+	p.Content = n.content(p.Content)
+	p.Examples = n.examples(p.Examples)
+}
+
+func (n *normalizer) requestBody(b *RequestBody) {
+	if b == nil {
+		return
+	}
+	n.ref(&b.Ref)
+	n.extensions(&b.VendorExtensible)
+	b.Content = n.content(b.Content)
+}
+
+func (n *normalizer) response(r *Response) {
+	if r == nil {
+		return
+	}
+	n.ref(&r.Ref)
+	n.extensions(&r.VendorExtensible)
+	r.Headers = n.headers(r.Headers)
+	r.Content = n.content(r.Content)
+	r.Links = n.links(r.Links)
+}
+
+func (n *normalizer) headers(headers map[string]*Header) map[string]*Header {
+	for _, h := range headers {
+		if h == nil {
+			continue
+		}
+		n.ref(&h.Ref)
+		n.extensions(&h.VendorExtensible)
+		n.schema(h.Schema)
+		h.Content = n.content(h.Content)
+		h.Examples = n.examples(h.Examples)
+	}
+	return emptyMapToNil(headers)
+}
+
+func (n *normalizer) content(content map[string]*MediaType) map[string]*MediaType {
+	for _, m := range content {
+		if m == nil {
+			continue
+		}
+		n.extensions(&m.VendorExtensible)
+		n.schema(m.Schema)
+		m.Examples = n.examples(m.Examples)
+		for _, e := range m.Encoding {
+			if e == nil {
+				continue
+			}
+			n.extensions(&e.VendorExtensible)
+			e.Headers = n.headers(e.Headers)
+		}
+		m.Encoding = emptyMapToNil(m.Encoding)
+	}
+	return emptyMapToNil(content)
+}
+
+func (n *normalizer) examples(examples map[string]*Example) map[string]*Example {
+	for _, e := range examples {
+		if e == nil {
+			continue
+		}
+		n.ref(&e.Ref)
+		n.extensions(&e.VendorExtensible)
+	}
+	return emptyMapToNil(examples)
+}
+
+func (n *normalizer) links(links map[string]*Link) map[string]*Link {
+	for _, l := range links {
+		if l == nil {
+			continue
+		}
+		n.ref(&l.Ref)
+		n.extensions(&l.VendorExtensible)
+		l.Parameters = emptyMapToNil(l.Parameters)
+		if l.Server != nil {
+			n.server(l.Server)
+		}
+	}
+	return emptyMapToNil(links)
+}
+
+func (n *normalizer) securityScheme(s *SecurityScheme) {
+	if s == nil {
+		return
+	}
+	n.ref(&s.Ref)
+	n.extensions(&s.VendorExtensible)
+	for _, f := range s.Flows {
+		if f == nil {
+			continue
+		}
+		n.extensions(&f.VendorExtensible)
+		f.Scopes = emptyMapToNil(f.Scopes)
+	}
+	s.Flows = emptyMapToNil(s.Flows)
+}
+
+// servers sorts servers by URL and description.
+func (n *normalizer) servers(servers []*Server) []*Server {
+	for _, s := range servers {
+		n.server(s)
+	}
+	sort.SliceStable(servers, func(i, j int) bool {
+		a, b := servers[i], servers[j]
+		if a == nil || b == nil {
+			return a == nil && b != nil
+		}
+		if a.URL != b.URL {
+			return a.URL < b.URL
+		}
+		return a.Description < b.Description
+	})
+	return emptySliceToNil(servers)
+}
+
+func (n *normalizer) server(s *Server) {
+	if s == nil {
+		return
+	}
+	n.extensions(&s.VendorExtensible)
+	for _, v := range s.Variables {
+		if v == nil {
+			continue
+		}
+		n.extensions(&v.VendorExtensible)
+		v.Enum = emptySliceToNil(v.Enum)
+	}
+	s.Variables = emptyMapToNil(s.Variables)
+}
+
+func (n *normalizer) schema(s *spec.Schema) {
+	if s == nil || n.schemas[s] {
+		return
+	}
+	n.schemas[s] = true
+
+	n.schemaProps(s)
+}
+
+// schemaProps normalizes s without recording it as visited, for schemas that
+// are not addressable in the document, like the values of maps.
+func (n *normalizer) schemaProps(s *spec.Schema) {
+	n.ref(&s.Ref)
+	n.extensions(&s.VendorExtensible)
+	s.ExtraProps = emptyMapToNil(s.ExtraProps)
+	s.Type = spec.StringOrArray(sortedStrings(s.Type))
+	s.Required = sortedStrings(s.Required)
+	s.Enum = emptySliceToNil(s.Enum)
+
+	if s.Items != nil {
+		n.schema(s.Items.Schema)
+		s.Items.Schemas = n.schemaSlice(s.Items.Schemas)
+	}
+	s.PrefixItems = n.schemaSlice(s.PrefixItems)
+	s.AllOf = n.schemaSlice(s.AllOf)
+	s.OneOf = n.schemaSlice(s.OneOf)
+	s.AnyOf = n.schemaSlice(s.AnyOf)
+	n.schema(s.Not)
+	s.Properties = n.schemaMap(s.Properties)
+	s.PatternProperties = n.schemaMap(s.PatternProperties)
+	s.Definitions = n.schemaMap(s.Definitions)
+	for _, sb := range []*spec.SchemaOrBool{s.AdditionalProperties, s.AdditionalItems, s.UnevaluatedItems, s.UnevaluatedProperties} {
+		if sb != nil {
+			n.schema(sb.Schema)
+		}
+	}
+	for name, d := range s.Dependencies {
+		n.schema(d.Schema)
+		d.Property = sortedStrings(d.Property)
+		s.Dependencies[name] = d
+	}
+	s.Dependencies = emptyMapToNil(s.Dependencies)
+}
+
+func (n *normalizer) schemaSlice(schemas []spec.Schema) []spec.Schema {
+	for i := range schemas {
+		n.schema(&schemas[i])
+	}
+	return emptySliceToNil(schemas)
+}
+
+func (n *normalizer) schemaMap(schemas map[string]spec.Schema) map[string]spec.Schema {
+	for name, s := range schemas {
+		n.schemaProps(&s)
+		schemas[name] = s
+	}
+	return emptyMapToNil(schemas)
+}
+
+// ref re-parses r, which drops the differences in the internal state of
+// references with the same string form.
+func (n *normalizer) ref(r *spec.Ref) {
+	str := r.String()
+	if str == "" {
+		*r = spec.Ref{}
+		return
+	}
+	if canonical, err := spec.NewRef(str); err == nil {
+		*r = canonical
+	}
+}
+
+func (n *normalizer) extensions(e *spec.VendorExtensible) {
+	e.Extensions = emptyMapToNil(e.Extensions)
+}
+
+func sortedStrings(s []string) []string {
+	sort.Strings(s)
+	return emptySliceToNil(s)
+}
+
+func emptySliceToNil[T any](s []T) []T {
+	if len(s) == 0 {
+		return nil
+	}
+	return s
+}
+
+func emptyMapToNil[K comparable, V any, M ~map[K]V](m M) M {
+	if len(m) == 0 {
+		return nil
+	}
+	return m
+}
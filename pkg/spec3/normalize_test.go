@@ -0,0 +1,163 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec3_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"k8s.io/kube-openapi/pkg/spec3"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+const normalizeTestDocument = `{
+  "openapi": "3.0.0",
+  "info": {"title": "test", "version": "v1"},
+  "servers": [{"url": "https://b.example.com"}, {"url": "https://a.example.com"}],
+  "security": [{"key": []}, {"oauth": ["write", "read"]}],
+  "paths": {
+    "/foos": {
+      "get": {
+        "tags": ["b", "a"],
+        "security": [{"oauth": ["write", "read"]}, {}],
+        "parameters": [
+          {"name": "limit", "in": "query", "schema": {"type": "integer"}, "examples": {}}
+        ],
+        "responses": {
+          "200": {
+            "description": "OK",
+            "headers": {},
+            "content": {
+              "application/json": {"schema": {"$ref": "#/components/schemas/Foo"}}
+            }
+          }
+        }
+      },
+      "post": {
+        "security": [],
+        "responses": {}
+      }
+    }
+  },
+  "components": {
+    "schemas": {
+      "Foo": {
+        "type": "object",
+        "required": ["spec", "metadata"],
+        "properties": {
+          "metadata": {"type": "object", "properties": {}},
+          "spec": {
+            "type": "object",
+            "required": ["b", "a"],
+            "properties": {"a": {"type": "string"}, "b": {"type": "string"}},
+            "allOf": []
+          }
+        }
+      }
+    },
+    "responses": {}
+  }
+}`
+
+func TestNormalize(t *testing.T) {
+	decoded := &spec3.OpenAPI{}
+	if err := json.Unmarshal([]byte(normalizeTestDocument), decoded); err != nil {
+		t.Fatal(err)
+	}
+	decoded.Normalize()
+
+	stringSchema := spec.Schema{SchemaProps: spec.SchemaProps{Type: []string{"string"}}}
+	built := &spec3.OpenAPI{
+		Version: "3.0.0",
+		Info:    &spec.Info{InfoProps: spec.InfoProps{Title: "test", Version: "v1"}},
+		Servers: []*spec3.Server{
+			{ServerProps: spec3.ServerProps{URL: "https://a.example.com"}},
+			{ServerProps: spec3.ServerProps{URL: "https://b.example.com"}},
+		},
+		SecurityRequirement: []map[string][]string{{"key": nil}, {"oauth": {"read", "write"}}},
+		Paths: &spec3.Paths{Paths: map[string]*spec3.Path{
+			"/foos": {PathProps: spec3.PathProps{
+				Get: &spec3.Operation{OperationProps: spec3.OperationProps{
+					Tags:                []string{"a", "b"},
+					SecurityRequirement: []map[string][]string{{"oauth": {"read", "write"}}, nil},
+					Parameters: []*spec3.Parameter{{ParameterProps: spec3.ParameterProps{
+						Name:   "limit",
+						In:     "query",
+						Schema: &spec.Schema{SchemaProps: spec.SchemaProps{Type: []string{"integer"}}},
+					}}},
+					Responses: &spec3.Responses{ResponsesProps: spec3.ResponsesProps{
+						StatusCodeResponses: map[int]*spec3.Response{
+							200: {ResponseProps: spec3.ResponseProps{
+								Description: "OK",
+								Content: map[string]*spec3.MediaType{
+									"application/json": {MediaTypeProps: spec3.MediaTypeProps{
+										Schema: spec.RefSchema("#/components/schemas/Foo"),
+									}},
+								},
+							}},
+						},
+					}},
+				}},
+				Post: &spec3.Operation{OperationProps: spec3.OperationProps{
+					SecurityRequirement: []map[string][]string{},
+					Responses:           &spec3.Responses{},
+				}},
+			}},
+		}},
+		Components: &spec3.Components{
+			Schemas: map[string]*spec.Schema{
+				"Foo": {SchemaProps: spec.SchemaProps{
+					Type:     []string{"object"},
+					Required: []string{"metadata", "spec"},
+					Properties: map[string]spec.Schema{
+						"metadata": {SchemaProps: spec.SchemaProps{Type: []string{"object"}, Properties: map[string]spec.Schema{}}},
+						"spec": {SchemaProps: spec.SchemaProps{
+							Type:       []string{"object"},
+							Required:   []string{"a", "b"},
+							Properties: map[string]spec.Schema{"a": stringSchema, "b": stringSchema},
+						}},
+					},
+				}},
+			},
+		},
+	}
+	built.Normalize()
+
+	if !reflect.DeepEqual(decoded, built) {
+		t.Errorf("normalized documents differ: %s", cmp.Diff(decoded, built, cmp.Comparer(func(a, b spec.Ref) bool {
+			return a.String() == b.String()
+		})))
+	}
+
+	b, err := json.Marshal(decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again := &spec3.OpenAPI{}
+	if err := json.Unmarshal(b, again); err != nil {
+		t.Fatal(err)
+	}
+	again.Normalize()
+	if !reflect.DeepEqual(decoded, again) {
+		t.Errorf("normalized document should round-trip through JSON: %s", cmp.Diff(decoded, again, cmp.Comparer(func(a, b spec.Ref) bool {
+			return a.String() == b.String()
+		})))
+	}
+}
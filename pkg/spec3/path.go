@@ -18,6 +18,8 @@ package spec3
 
 import (
 	"encoding/json"
+	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/go-openapi/swag"
@@ -74,6 +76,253 @@ func (p *Paths) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// PathConflictPolicy decides how Paths.Merge handles a path that is defined
+// in both documents.
+type PathConflictPolicy int
+
+const (
+	// PathConflictError makes Merge fail without modifying the receiver.
+	PathConflictError PathConflictPolicy = iota
+	// PathConflictKeepExisting keeps the path item already in the receiver.
+	PathConflictKeepExisting
+	// PathConflictReplace replaces the receiver's path item with the other one.
+	PathConflictReplace
+)
+
+// Add inserts a path item under the given path template. It returns an error
+// if the path does not start with a slash or is already defined.
+func (p *Paths) Add(path string, item *Path) error {
+	if !strings.HasPrefix(path, "/") {
+		return fmt.Errorf("path %q must begin with a slash", path)
+	}
+	if _, found := p.Paths[path]; found {
+		return fmt.Errorf("duplicate path %q", path)
+	}
+	if p.Paths == nil {
+		p.Paths = make(map[string]*Path)
+	}
+	p.Paths[path] = item
+	return nil
+}
+
+// Merge copies the path items and vendor extensions of other into p. Paths
+// defined in both are resolved according to policy. With PathConflictError,
+// p is left untouched if any path conflicts.
+func (p *Paths) Merge(other *Paths, policy PathConflictPolicy) error {
+	if other == nil {
+		return nil
+	}
+	if policy == PathConflictError {
+		var conflicts []string
+		for k := range other.Paths {
+			if _, found := p.Paths[k]; found {
+				conflicts = append(conflicts, k)
+			}
+		}
+		if len(conflicts) > 0 {
+			sort.Strings(conflicts)
+			return fmt.Errorf("duplicate paths: %s", strings.Join(conflicts, ", "))
+		}
+	}
+	for k, v := range other.Paths {
+		if _, found := p.Paths[k]; found && policy == PathConflictKeepExisting {
+			continue
+		}
+		if p.Paths == nil {
+			p.Paths = make(map[string]*Path)
+		}
+		p.Paths[k] = v
+	}
+	// extensions are copied verbatim, AddExtension would lowercase their keys
+	for k, v := range other.Extensions {
+		if _, found := p.Extensions[k]; found {
+			continue
+		}
+		if p.Extensions == nil {
+			p.Extensions = make(spec.Extensions)
+		}
+		p.Extensions[k] = v
+	}
+	return nil
+}
+
+// Lookup finds the path item serving a concrete request path such as
+// "/apis/apps/v1/namespaces/default/deployments". An exact match is
+// preferred; otherwise templated paths are matched segment by segment, with
+// each {param} matching exactly one non-empty segment. If several templates
+// match, the one with the most literal segments wins, as required by the
+// OpenAPI specification.
+//
+// It returns the matched template, its path item and the values of the
+// template parameters. A nil Paths matches nothing.
+func (p *Paths) Lookup(requestPath string) (template string, item *Path, params map[string]string, ok bool) {
+	if p == nil {
+		return "", nil, nil, false
+	}
+	if item, found := p.Paths[requestPath]; found {
+		return requestPath, item, map[string]string{}, true
+	}
+	segments := strings.Split(requestPath, "/")
+	bestLiterals := -1
+	for tmpl, candidate := range p.Paths {
+		literals, values, matched := matchPathTemplate(strings.Split(tmpl, "/"), segments)
+		if !matched {
+			continue
+		}
+		// break ties deterministically so the result does not depend on map order
+		if literals > bestLiterals || (literals == bestLiterals && tmpl < template) {
+			template, item, params, ok = tmpl, candidate, values, true
+			bestLiterals = literals
+		}
+	}
+	return template, item, params, ok
+}
+
+// Resolve returns the path item that item stands for. If item is a reference
+// to another path item of p, such as "#/paths/~1api~1v1~1pods", the
+// reference is followed, through further references if need be. Other
+// references are not supported.
+func (p *Paths) Resolve(item *Path) (*Path, error) {
+	chain, err := p.resolveChain(item)
+	if err != nil {
+		return nil, err
+	}
+	return chain[len(chain)-1], nil
+}
+
+// resolveChain returns item followed by the path items it references,
+// directly or not, ending with one that is not a reference.
+func (p *Paths) resolveChain(item *Path) ([]*Path, error) {
+	chain := []*Path{item}
+	seen := map[string]bool{}
+	for item != nil && item.Ref.String() != "" {
+		ref := item.Ref.String()
+		if seen[ref] {
+			return nil, fmt.Errorf("path item reference cycle through %q", ref)
+		}
+		seen[ref] = true
+		if item.Ref.HasFullURL || item.Ref.HasURLPathOnly || item.Ref.HasFileScheme || item.Ref.HasFullFilePath {
+			return nil, fmt.Errorf("path item reference %q is not local to the document", ref)
+		}
+		tokens := item.Ref.GetPointer().DecodedTokens()
+		if len(tokens) != 2 || tokens[0] != "paths" {
+			return nil, fmt.Errorf("path item reference %q does not point to a path item", ref)
+		}
+		target, ok := p.Paths[tokens[1]]
+		if !ok || target == nil {
+			return nil, fmt.Errorf("path item reference %q: path %q not found", ref, tokens[1])
+		}
+		item = target
+		chain = append(chain, item)
+	}
+	return chain, nil
+}
+
+// Flatten replaces the path items of p that are references to other path
+// items by copies of the items they reference. Fields set next to a
+// reference take precedence over the ones of the referenced item, whose
+// behavior the specification leaves undefined. Path items are not modified,
+// only the map of p, so p may share its items with other documents. On
+// error, p is left untouched.
+func (p *Paths) Flatten() error {
+	if p == nil {
+		return nil
+	}
+	flattened := map[string]*Path{}
+	for path, item := range p.Paths {
+		if item == nil || item.Ref.String() == "" {
+			continue
+		}
+		chain, err := p.resolveChain(item)
+		if err != nil {
+			return fmt.Errorf("path %q: %v", path, err)
+		}
+		merged := *chain[len(chain)-1]
+		merged.Extensions = mergeExtensions(nil, merged.Extensions)
+		for i := len(chain) - 2; i >= 0; i-- {
+			overlayPath(&merged, chain[i])
+		}
+		flattened[path] = &merged
+	}
+	for path, item := range flattened {
+		p.Paths[path] = item
+	}
+	return nil
+}
+
+// overlayPath sets the fields of item that are set in over, except its
+// reference.
+func overlayPath(item, over *Path) {
+	props := &item.PathProps
+	if over.Summary != "" {
+		props.Summary = over.Summary
+	}
+	if over.Description != "" {
+		props.Description = over.Description
+	}
+	for _, op := range []struct {
+		dst **Operation
+		src *Operation
+	}{
+		{&props.Get, over.Get},
+		{&props.Put, over.Put},
+		{&props.Post, over.Post},
+		{&props.Delete, over.Delete},
+		{&props.Options, over.Options},
+		{&props.Head, over.Head},
+		{&props.Patch, over.Patch},
+		{&props.Trace, over.Trace},
+	} {
+		if op.src != nil {
+			*op.dst = op.src
+		}
+	}
+	if over.Servers != nil {
+		props.Servers = over.Servers
+	}
+	if over.Parameters != nil {
+		props.Parameters = over.Parameters
+	}
+	item.Extensions = mergeExtensions(item.Extensions, over.Extensions)
+}
+
+// mergeExtensions returns a new map holding the extensions of base and over,
+// with the ones of over taking precedence, or nil if there are none.
+func mergeExtensions(base, over spec.Extensions) spec.Extensions {
+	if len(base)+len(over) == 0 {
+		return nil
+	}
+	merged := make(spec.Extensions, len(base)+len(over))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range over {
+		merged[k] = v
+	}
+	return merged
+}
+
+func matchPathTemplate(tmpl, segments []string) (literals int, params map[string]string, ok bool) {
+	if len(tmpl) != len(segments) {
+		return 0, nil, false
+	}
+	params = map[string]string{}
+	for i, t := range tmpl {
+		if len(t) > 2 && strings.HasPrefix(t, "{") && strings.HasSuffix(t, "}") {
+			if segments[i] == "" {
+				return 0, nil, false
+			}
+			params[t[1:len(t)-1]] = segments[i]
+			continue
+		}
+		if t != segments[i] {
+			return 0, nil, false
+		}
+		literals++
+	}
+	return literals, params, true
+}
+
 // Path describes the operations available on a single path, more at https://github.com/OAI/OpenAPI-Specification/blob/master/versions/3.0.0.md#pathItemObject
 //
 // Note that this struct is actually a thin wrapper around PathProps to make it referable and extensible
@@ -18,6 +18,8 @@ package spec3_test
 
 import (
 	"encoding/json"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -112,3 +114,251 @@ func TestPathJSONSerialization(t *testing.T) {
 		})
 	}
 }
+
+func TestPathsAdd(t *testing.T) {
+	paths := &spec3.Paths{}
+	if err := paths.Add("/foo", &spec3.Path{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := paths.Add("/foo", &spec3.Path{}); err == nil {
+		t.Errorf("expected error adding duplicate path")
+	}
+	if err := paths.Add("bar", &spec3.Path{}); err == nil {
+		t.Errorf("expected error adding path without leading slash")
+	}
+	if len(paths.Paths) != 1 {
+		t.Errorf("expected 1 path, got %d", len(paths.Paths))
+	}
+}
+
+func TestPathsMerge(t *testing.T) {
+	existing := &spec3.Path{PathProps: spec3.PathProps{Summary: "existing"}}
+	incoming := &spec3.Path{PathProps: spec3.PathProps{Summary: "incoming"}}
+	other := &spec3.Paths{
+		Paths: map[string]*spec3.Path{"/foo": incoming, "/bar": incoming},
+		VendorExtensible: spec.VendorExtensible{
+			Extensions: spec.Extensions{"x-a": "other", "x-b": "other", "X-Mixed-Case": "other"},
+		},
+	}
+	newPaths := func() *spec3.Paths {
+		return &spec3.Paths{
+			Paths:            map[string]*spec3.Path{"/foo": existing},
+			VendorExtensible: spec.VendorExtensible{Extensions: spec.Extensions{"x-a": "mine"}},
+		}
+	}
+
+	paths := newPaths()
+	if err := paths.Merge(other, spec3.PathConflictError); err == nil {
+		t.Errorf("expected conflict error")
+	}
+	if !reflect.DeepEqual(newPaths(), paths) {
+		t.Errorf("failed merge modified receiver: %#v", paths)
+	}
+
+	paths = newPaths()
+	if err := paths.Merge(other, spec3.PathConflictKeepExisting); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := &spec3.Paths{
+		Paths:            map[string]*spec3.Path{"/foo": existing, "/bar": incoming},
+		VendorExtensible: spec.VendorExtensible{Extensions: spec.Extensions{"x-a": "mine", "x-b": "other", "X-Mixed-Case": "other"}},
+	}
+	if !reflect.DeepEqual(expected, paths) {
+		t.Errorf("expected %#v, got %#v", expected, paths)
+	}
+
+	paths = newPaths()
+	if err := paths.Merge(other, spec3.PathConflictReplace); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if paths.Paths["/foo"] != incoming {
+		t.Errorf("expected /foo to be replaced")
+	}
+
+	paths = &spec3.Paths{}
+	if err := paths.Merge(other, spec3.PathConflictError); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(other.Extensions, paths.Extensions) {
+		t.Errorf("expected extensions %v to be copied verbatim, got %v", other.Extensions, paths.Extensions)
+	}
+}
+
+func TestPathsLookup(t *testing.T) {
+	list := &spec3.Path{}
+	get := &spec3.Path{}
+	status := &spec3.Path{}
+	paths := &spec3.Paths{Paths: map[string]*spec3.Path{
+		"/apis/apps/v1/namespaces/{namespace}/deployments":               list,
+		"/apis/apps/v1/namespaces/{namespace}/deployments/{name}":        get,
+		"/apis/apps/v1/namespaces/{namespace}/deployments/{name}/status": status,
+		"/apis/apps/v1/namespaces/default/deployments/{name}/status":     status,
+	}}
+
+	cases := []struct {
+		path     string
+		template string
+		item     *spec3.Path
+		params   map[string]string
+		ok       bool
+	}{
+		{
+			path:     "/apis/apps/v1/namespaces/kube-system/deployments",
+			template: "/apis/apps/v1/namespaces/{namespace}/deployments",
+			item:     list,
+			params:   map[string]string{"namespace": "kube-system"},
+			ok:       true,
+		},
+		{
+			path:     "/apis/apps/v1/namespaces/ns/deployments/foo",
+			template: "/apis/apps/v1/namespaces/{namespace}/deployments/{name}",
+			item:     get,
+			params:   map[string]string{"namespace": "ns", "name": "foo"},
+			ok:       true,
+		},
+		{
+			path:     "/apis/apps/v1/namespaces/default/deployments/foo/status",
+			template: "/apis/apps/v1/namespaces/default/deployments/{name}/status",
+			item:     status,
+			params:   map[string]string{"name": "foo"},
+			ok:       true,
+		},
+		{
+			path: "/apis/apps/v1/namespaces//deployments",
+		},
+		{
+			path: "/apis/apps/v1/namespaces/ns/deployments/foo/scale",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.path, func(t *testing.T) {
+			template, item, params, ok := paths.Lookup(tc.path)
+			if ok != tc.ok || template != tc.template || item != tc.item {
+				t.Fatalf("got (%q, %p, %v), want (%q, %p, %v)", template, item, ok, tc.template, tc.item, tc.ok)
+			}
+			if diff := cmp.Diff(tc.params, params); diff != "" {
+				t.Errorf("unexpected params (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	var nilPaths *spec3.Paths
+	if template, item, params, ok := nilPaths.Lookup("/api/v1/pods"); ok || template != "" || item != nil || params != nil {
+		t.Errorf("expected no match on nil paths, got (%q, %p, %v, %v)", template, item, params, ok)
+	}
+}
+
+const pathRefsDoc = `{
+  "/pods": {
+    "summary": "pods",
+    "x-kubernetes-list": true,
+    "get": {"operationId": "listPods"},
+    "post": {"operationId": "createPod"}
+  },
+  "/api/v1/pods": {"$ref": "#/paths/~1pods"},
+  "/api/v1/namespaces/{namespace}/pods": {
+    "$ref": "#/paths/~1api~1v1~1pods",
+    "summary": "namespaced pods",
+    "x-kubernetes-namespaced": true,
+    "post": {"operationId": "createNamespacedPod"}
+  },
+  "/cycle/a": {"$ref": "#/paths/~1cycle~1b"},
+  "/cycle/b": {"$ref": "#/paths/~1cycle~1a"},
+  "/missing": {"$ref": "#/paths/~1nope"},
+  "/component": {"$ref": "#/components/schemas/Pod"},
+  "/remote": {"$ref": "https://example.com/openapi.json#/paths/~1pods"}
+}`
+
+func TestPathsResolve(t *testing.T) {
+	var paths spec3.Paths
+	if err := json.Unmarshal([]byte(pathRefsDoc), &paths); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range []string{"/pods", "/api/v1/pods", "/api/v1/namespaces/{namespace}/pods"} {
+		got, err := paths.Resolve(paths.Paths[path])
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", path, err)
+		}
+		if got != paths.Paths["/pods"] {
+			t.Errorf("%s: expected to resolve to /pods", path)
+		}
+	}
+
+	cases := map[string]string{
+		"/cycle/a":   `path item reference cycle through "#/paths/~1cycle~1b"`,
+		"/missing":   `path "/nope" not found`,
+		"/component": `does not point to a path item`,
+		"/remote":    `is not local to the document`,
+	}
+	for path, expected := range cases {
+		_, err := paths.Resolve(paths.Paths[path])
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("%s: expected error containing %q, got %v", path, expected, err)
+		}
+	}
+}
+
+func TestPathsFlatten(t *testing.T) {
+	var paths spec3.Paths
+	if err := json.Unmarshal([]byte(pathRefsDoc), &paths); err != nil {
+		t.Fatal(err)
+	}
+	before, err := json.Marshal(&paths)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := paths.Flatten(); err == nil {
+		t.Fatalf("expected an error, got %v", err)
+	}
+	after, err := json.Marshal(&paths)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(before) != string(after) {
+		t.Errorf("expected paths to be left untouched on error")
+	}
+
+	for _, path := range []string{"/cycle/a", "/cycle/b", "/missing", "/component", "/remote"} {
+		delete(paths.Paths, path)
+	}
+	pods := paths.Paths["/pods"]
+	if err := paths.Flatten(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if paths.Paths["/pods"] != pods {
+		t.Errorf("expected /pods to be kept as is")
+	}
+
+	var expected spec3.Paths
+	if err := json.Unmarshal([]byte(`{
+  "/pods": {
+    "summary": "pods",
+    "x-kubernetes-list": true,
+    "get": {"operationId": "listPods"},
+    "post": {"operationId": "createPod"}
+  },
+  "/api/v1/pods": {
+    "summary": "pods",
+    "x-kubernetes-list": true,
+    "get": {"operationId": "listPods"},
+    "post": {"operationId": "createPod"}
+  },
+  "/api/v1/namespaces/{namespace}/pods": {
+    "summary": "namespaced pods",
+    "x-kubernetes-list": true,
+    "x-kubernetes-namespaced": true,
+    "get": {"operationId": "listPods"},
+    "post": {"operationId": "createNamespacedPod"}
+  }
+}`), &expected); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(&expected, &paths) {
+		got, _ := json.Marshal(&paths)
+		t.Errorf("unexpected flattened paths: %s", got)
+	}
+	if pods.Extensions["x-kubernetes-namespaced"] != nil || pods.Post.OperationId != "createPod" {
+		t.Errorf("expected referenced path item not to be modified")
+	}
+}
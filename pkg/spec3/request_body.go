@@ -71,3 +71,28 @@ type RequestBodyProps struct {
 	// Required determines if the request body is required in the request
 	Required bool `json:"required,omitempty"`
 }
+
+// NewRequestBody returns a request body whose content is described by
+// schema for each of the given media types.
+func NewRequestBody(schema *spec.Schema, required bool, mediaTypes ...string) *RequestBody {
+	r := &RequestBody{
+		RequestBodyProps: RequestBodyProps{
+			Content:  make(map[string]*MediaType, len(mediaTypes)),
+			Required: required,
+		},
+	}
+	for _, mediaType := range mediaTypes {
+		r.Content[mediaType] = &MediaType{
+			MediaTypeProps: MediaTypeProps{
+				Schema: schema,
+			},
+		}
+	}
+	return r
+}
+
+// NewJSONRequestBody returns a request body whose "application/json"
+// content is described by schema.
+func NewJSONRequestBody(schema *spec.Schema, required bool) *RequestBody {
+	return NewRequestBody(schema, required, "application/json")
+}
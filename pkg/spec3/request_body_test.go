@@ -18,6 +18,7 @@ package spec3_test
 
 import (
 	"encoding/json"
+	"reflect"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -65,3 +66,54 @@ func TestRequestBodyJSONSerialization(t *testing.T) {
 		})
 	}
 }
+
+func TestNewRequestBody(t *testing.T) {
+	schema := spec.RefSchema("#/components/schemas/User")
+	cases := []struct {
+		name     string
+		target   *spec3.RequestBody
+		expected *spec3.RequestBody
+	}{
+		{
+			name:   "media types",
+			target: spec3.NewRequestBody(schema, false, "application/json", "application/yaml"),
+			expected: &spec3.RequestBody{
+				RequestBodyProps: spec3.RequestBodyProps{
+					Content: map[string]*spec3.MediaType{
+						"application/json": {MediaTypeProps: spec3.MediaTypeProps{Schema: schema}},
+						"application/yaml": {MediaTypeProps: spec3.MediaTypeProps{Schema: schema}},
+					},
+				},
+			},
+		},
+		{
+			name:   "no media types",
+			target: spec3.NewRequestBody(schema, true),
+			expected: &spec3.RequestBody{
+				RequestBodyProps: spec3.RequestBodyProps{
+					Content:  map[string]*spec3.MediaType{},
+					Required: true,
+				},
+			},
+		},
+		{
+			name:   "json",
+			target: spec3.NewJSONRequestBody(schema, true),
+			expected: &spec3.RequestBody{
+				RequestBodyProps: spec3.RequestBodyProps{
+					Content: map[string]*spec3.MediaType{
+						"application/json": {MediaTypeProps: spec3.MediaTypeProps{Schema: schema}},
+					},
+					Required: true,
+				},
+			},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if !reflect.DeepEqual(tc.target, tc.expected) {
+				t.Fatalf("expected %#v, got %#v", tc.expected, tc.target)
+			}
+		})
+	}
+}
@@ -189,6 +189,8 @@ func (r *Link) UnmarshalJSON(data []byte) error {
 
 // LinkProps describes a single response from an API Operation, more at https://github.com/OAI/OpenAPI-Specification/blob/master/versions/3.0.0.md#responseObject
 type LinkProps struct {
+	// OperationRef is a relative or absolute reference to an OAS operation, mutually exclusive with OperationId
+	OperationRef string `json:"operationRef,omitempty"`
 	// OperationId is the name of an existing, resolvable OAS operation
 	OperationId string `json:"operationId,omitempty"`
 	// Parameters is a map representing parameters to pass to an operation as specified with operationId or identified via operationRef
@@ -0,0 +1,124 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec3
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/kube-openapi/pkg/validation/errors"
+)
+
+// EffectiveSecurityRequirements returns the security requirements that apply
+// to op given the document-level requirements, normalized by
+// NormalizeSecurityRequirements. As mandated by the specification,
+// requirements declared on the operation, even an empty list, replace those
+// declared on the document.
+func EffectiveSecurityRequirements(document []map[string][]string, op *Operation) []map[string][]string {
+	if op != nil && op.SecurityRequirement != nil {
+		return NormalizeSecurityRequirements(op.SecurityRequirement)
+	}
+	return NormalizeSecurityRequirements(document)
+}
+
+// MergeSecurityRequirements returns the requirements of all lists, any one
+// of which grants access, normalized by NormalizeSecurityRequirements.
+func MergeSecurityRequirements(lists ...[]map[string][]string) []map[string][]string {
+	var merged []map[string][]string
+	for _, reqs := range lists {
+		merged = append(merged, reqs...)
+	}
+	return NormalizeSecurityRequirements(merged)
+}
+
+// NormalizeSecurityRequirements returns a copy of reqs with the scopes of
+// every scheme sorted and deduplicated, and duplicate requirements removed.
+// The order of the requirements is kept, and an empty requirement, which
+// makes security optional, is preserved. A nil reqs gives nil, and an
+// empty one an empty list.
+func NormalizeSecurityRequirements(reqs []map[string][]string) []map[string][]string {
+	if reqs == nil {
+		return nil
+	}
+	normalized := make([]map[string][]string, 0, len(reqs))
+	seen := map[string]bool{}
+	for _, req := range reqs {
+		n := make(map[string][]string, len(req))
+		for name, scopes := range req {
+			n[name] = uniqueSortedStrings(scopes)
+		}
+		key := securityRequirementKey(n)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		normalized = append(normalized, n)
+	}
+	return normalized
+}
+
+func uniqueSortedStrings(s []string) []string {
+	if len(s) == 0 {
+		return []string{}
+	}
+	sorted := append([]string(nil), s...)
+	sort.Strings(sorted)
+	unique := sorted[:1]
+	for _, v := range sorted[1:] {
+		if v != unique[len(unique)-1] {
+			unique = append(unique, v)
+		}
+	}
+	return unique
+}
+
+// securityRequirementKey returns a string identifying a normalized
+// requirement.
+func securityRequirementKey(req map[string][]string) string {
+	var b strings.Builder
+	for _, name := range sortedKeys(req) {
+		fmt.Fprintf(&b, "%q:", name)
+		for _, scope := range req[name] {
+			fmt.Fprintf(&b, "%q,", scope)
+		}
+		b.WriteString(";")
+	}
+	return b.String()
+}
+
+// validateSecurityRequirements checks that the schemes of reqs are declared
+// in schemes, and that only OAuth2 and OpenID Connect schemes list scopes.
+func validateSecurityRequirements(path string, reqs []map[string][]string, schemes SecuritySchemes) []error {
+	var errs []error
+	for i, req := range reqs {
+		for _, name := range sortedKeys(req) {
+			scheme, ok := schemes[name]
+			if !ok {
+				errs = append(errs, errors.PropertyNotAllowed(fmt.Sprintf("%s[%d]", path, i), "", name))
+				continue
+			}
+			if scheme == nil || scheme.Ref.String() != "" {
+				continue
+			}
+			if scopes := req[name]; len(scopes) > 0 && scheme.Type != "oauth2" && scheme.Type != "openIdConnect" {
+				errs = append(errs, errors.TooManyItems(fmt.Sprintf("%s[%d].%s", path, i, name), "", 0, scopes))
+			}
+		}
+	}
+	return errs
+}
@@ -0,0 +1,185 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec3_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/kube-openapi/pkg/spec3"
+)
+
+func TestNormalizeSecurityRequirements(t *testing.T) {
+	cases := []struct {
+		name     string
+		reqs     []map[string][]string
+		expected []map[string][]string
+	}{
+		{name: "nil"},
+		{name: "empty", reqs: []map[string][]string{}, expected: []map[string][]string{}},
+		{
+			name:     "scopes",
+			reqs:     []map[string][]string{{"oauth": {"write", "read", "write"}, "key": nil}},
+			expected: []map[string][]string{{"oauth": {"read", "write"}, "key": {}}},
+		},
+		{
+			name: "duplicates",
+			reqs: []map[string][]string{
+				{"key": {}},
+				{"oauth": {"read", "write"}},
+				{},
+				{"key": nil},
+				{"oauth": {"write", "read"}},
+				{},
+			},
+			expected: []map[string][]string{{"key": {}}, {"oauth": {"read", "write"}}, {}},
+		},
+		{
+			name:     "combined schemes",
+			reqs:     []map[string][]string{{"a": {}, "b": {}}, {"a": {}}, {"b": {}, "a": {}}},
+			expected: []map[string][]string{{"a": {}, "b": {}}, {"a": {}}},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := spec3.NormalizeSecurityRequirements(tc.reqs); !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+
+	reqs := []map[string][]string{{"oauth": {"write", "read"}}}
+	spec3.NormalizeSecurityRequirements(reqs)
+	if !reflect.DeepEqual(reqs[0]["oauth"], []string{"write", "read"}) {
+		t.Errorf("input was modified: %v", reqs)
+	}
+}
+
+func TestMergeSecurityRequirements(t *testing.T) {
+	got := spec3.MergeSecurityRequirements(
+		[]map[string][]string{{"key": {}}},
+		nil,
+		[]map[string][]string{{"oauth": {"read"}}, {"key": {}}},
+	)
+	expected := []map[string][]string{{"key": {}}, {"oauth": {"read"}}}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
+
+func TestEffectiveSecurityRequirements(t *testing.T) {
+	doc := []map[string][]string{{"key": {}}}
+	withSecurity := func(reqs []map[string][]string) *spec3.Operation {
+		return &spec3.Operation{OperationProps: spec3.OperationProps{SecurityRequirement: reqs}}
+	}
+	cases := []struct {
+		name     string
+		doc      []map[string][]string
+		op       *spec3.Operation
+		expected []map[string][]string
+	}{
+		{name: "inherited", doc: doc, op: withSecurity(nil), expected: []map[string][]string{{"key": {}}}},
+		{name: "no operation", doc: doc, expected: []map[string][]string{{"key": {}}}},
+		{name: "overridden", doc: doc, op: withSecurity([]map[string][]string{{"oauth": {"read"}}}), expected: []map[string][]string{{"oauth": {"read"}}}},
+		{name: "removed", doc: doc, op: withSecurity([]map[string][]string{}), expected: []map[string][]string{}},
+		{name: "no document", op: withSecurity(nil)},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := spec3.EffectiveSecurityRequirements(tc.doc, tc.op); !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestValidateSecurityRequirements(t *testing.T) {
+	schemes := spec3.SecuritySchemes{
+		"key":   {SecuritySchemeProps: spec3.SecuritySchemeProps{Type: "apiKey", Name: "X-Key", In: "header"}},
+		"oauth": {SecuritySchemeProps: spec3.SecuritySchemeProps{Type: "oauth2"}},
+	}
+	withSecurity := func(doc, op []map[string][]string) *spec3.OpenAPI {
+		return &spec3.OpenAPI{
+			SecurityRequirement: doc,
+			Components:          &spec3.Components{SecuritySchemes: schemes},
+			Paths: &spec3.Paths{Paths: map[string]*spec3.Path{
+				"/foo": {PathProps: spec3.PathProps{
+					Get: &spec3.Operation{OperationProps: spec3.OperationProps{SecurityRequirement: op}},
+				}},
+			}},
+		}
+	}
+	cases := []struct {
+		name   string
+		doc    *spec3.OpenAPI
+		errors []string
+	}{
+		{
+			name: "valid",
+			doc:  withSecurity([]map[string][]string{{"key": {}}}, []map[string][]string{{"oauth": {"read"}}, {"key": {}}, {}}),
+		},
+		{
+			name:   "undeclared scheme",
+			doc:    withSecurity([]map[string][]string{{"basic": {}}}, []map[string][]string{{"key": {}, "basic": {}}, {"bearer": {}}}),
+			errors: []string{"security[0].basic", "paths[/foo].get.security[0].basic", "paths[/foo].get.security[1].bearer"},
+		},
+		{
+			name:   "no components",
+			doc:    &spec3.OpenAPI{SecurityRequirement: []map[string][]string{{"key": {}}}},
+			errors: []string{"security[0].key"},
+		},
+		{
+			name: "no components on operation",
+			doc: &spec3.OpenAPI{Paths: &spec3.Paths{Paths: map[string]*spec3.Path{
+				"/foo": {PathProps: spec3.PathProps{
+					Get: &spec3.Operation{OperationProps: spec3.OperationProps{SecurityRequirement: []map[string][]string{{"key": {}}}}},
+				}},
+			}}},
+			errors: []string{"paths[/foo].get.security[0].key"},
+		},
+		{
+			name:   "scopes on api key",
+			doc:    withSecurity([]map[string][]string{{"key": {"read"}}}, nil),
+			errors: []string{"security[0].key"},
+		},
+		{
+			name:   "scopes on api key on operation",
+			doc:    withSecurity(nil, []map[string][]string{{"key": {"read"}}}),
+			errors: []string{"paths[/foo].get.security[0].key"},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := spec3.Validate(tc.doc)
+			if len(tc.errors) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("expected errors for %v", tc.errors)
+			}
+			for _, e := range tc.errors {
+				if !strings.Contains(err.Error(), e) {
+					t.Errorf("expected error mentioning %q, got %v", e, err)
+				}
+			}
+		})
+	}
+}
@@ -24,12 +24,21 @@ import (
 type OpenAPI struct {
 	// Version represents the semantic version number of the OpenAPI Specification that this document uses
 	Version string `json:"openapi"`
+	// JSONSchemaDialect is the default $schema of the schemas of the document,
+	// only defined by OpenAPI 3.1
+	JSONSchemaDialect string `json:"jsonSchemaDialect,omitempty"`
 	// Info provides metadata about the API
 	Info *spec.Info `json:"info"`
 	// Paths holds the available target and operations for the API
 	Paths *Paths `json:"paths,omitempty"`
+	// Webhooks holds the requests that the API may send, keyed by name, as
+	// described by the path items of the requests it receives. Only defined
+	// by OpenAPI 3.1
+	Webhooks map[string]*Path `json:"webhooks,omitempty"`
 	// Servers is an array of Server objects which provide connectivity information to a target server
 	Servers []*Server `json:"servers,omitempty"`
+	// SecurityRequirement holds a declaration of which security mechanisms can be used across the API
+	SecurityRequirement []map[string][]string `json:"security,omitempty"`
 	// Components hold various schemas for the specification
 	Components *Components `json:"components,omitempty"`
 	// ExternalDocs holds additional external documentation
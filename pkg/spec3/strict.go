@@ -0,0 +1,215 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec3
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+// UnknownFieldsError lists the members of a JSON document that were dropped
+// when decoding it, because they match no field of the types decoded.
+type UnknownFieldsError struct {
+	// Paths are the JSON pointers to the unknown members, in document order
+	// with object members sorted by name, e.g. "/paths/~1foo/get/requried".
+	Paths []string
+}
+
+func (e *UnknownFieldsError) Error() string {
+	return fmt.Sprintf("unknown fields: %s", strings.Join(e.Paths, ", "))
+}
+
+// UnmarshalJSONStrict decodes data into v like json.Unmarshal, where v is a
+// pointer to an OpenAPI document or to one of its objects. If data has
+// members that v cannot hold, v is decoded anyway and an *UnknownFieldsError
+// listing them is returned, so that typos such as "requried" are caught
+// instead of being silently dropped.
+//
+// Vendor extensions are only known in the objects that hold them, which the
+// document, its components, and the contacts, licenses and external
+// documentation of its info and schemas do not. Schema keywords that
+// spec.Schema has no field for are unknown as well, even though they are
+// kept in its ExtraProps.
+func UnmarshalJSONStrict(data []byte, v interface{}) error {
+	if err := json.Unmarshal(data, v); err != nil {
+		return err
+	}
+	var generic interface{}
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return err
+	}
+	c := strictChecker{}
+	c.check("", generic, reflect.TypeOf(v))
+	if len(c.unknown) > 0 {
+		return &UnknownFieldsError{Paths: c.unknown}
+	}
+	return nil
+}
+
+var (
+	pathsType               = reflect.TypeOf(Paths{})
+	pathType                = reflect.TypeOf(Path{})
+	responsesType           = reflect.TypeOf(Responses{})
+	responseType            = reflect.TypeOf(Response{})
+	schemaType              = reflect.TypeOf(spec.Schema{})
+	schemaOrArrayType       = reflect.TypeOf(spec.SchemaOrArray{})
+	schemaOrBoolType        = reflect.TypeOf(spec.SchemaOrBool{})
+	schemaOrStringArrayType = reflect.TypeOf(spec.SchemaOrStringArray{})
+	stringOrArrayType       = reflect.TypeOf(spec.StringOrArray{})
+	refableType             = reflect.TypeOf(spec.Refable{})
+	vendorExtensibleType    = reflect.TypeOf(spec.VendorExtensible{})
+)
+
+// strictChecker walks a generic JSON value along the Go type it was decoded
+// into, and collects the members that the type has no field for.
+type strictChecker struct {
+	unknown []string
+}
+
+func (c *strictChecker) check(path string, data interface{}, t reflect.Type) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t {
+	case pathsType:
+		c.members(path, data, func(name string) reflect.Type {
+			if isExtension(name) {
+				return nil
+			}
+			return pathType
+		})
+		return
+	case responsesType:
+		c.members(path, data, func(name string) reflect.Type {
+			if isExtension(name) {
+				return nil
+			}
+			if _, err := strconv.Atoi(name); err == nil || name == "default" {
+				return responseType
+			}
+			c.unknown = append(c.unknown, path+"/"+escapeToken(name))
+			return nil
+		})
+		return
+	case schemaOrArrayType, schemaOrBoolType, schemaOrStringArrayType:
+		// objects and arrays are schemas, other values are bools or strings
+		if items, ok := data.([]interface{}); ok && t == schemaOrArrayType {
+			for i, item := range items {
+				c.check(path+"/"+strconv.Itoa(i), item, schemaType)
+			}
+		} else {
+			c.check(path, data, schemaType)
+		}
+		return
+	case stringOrArrayType:
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		fields, refable, extensible := structMembers(t)
+		c.members(path, data, func(name string) reflect.Type {
+			if f, ok := fields[name]; ok {
+				return f
+			}
+			known := extensible && isExtension(name) ||
+				refable && name == "$ref" ||
+				t == schemaType && (name == "$ref" || name == "$schema")
+			if !known {
+				c.unknown = append(c.unknown, path+"/"+escapeToken(name))
+			}
+			return nil
+		})
+	case reflect.Map:
+		c.members(path, data, func(string) reflect.Type { return t.Elem() })
+	case reflect.Slice, reflect.Array:
+		items, _ := data.([]interface{})
+		for i, item := range items {
+			c.check(path+"/"+strconv.Itoa(i), item, t.Elem())
+		}
+	}
+}
+
+// members checks the members of data, if it is an object, against the types
+// returned by typeOf, skipping the ones it returns nil for.
+func (c *strictChecker) members(path string, data interface{}, typeOf func(name string) reflect.Type) {
+	obj, ok := data.(map[string]interface{})
+	if !ok {
+		return
+	}
+	for _, name := range sortedKeys(obj) {
+		if t := typeOf(name); t != nil {
+			c.check(path+"/"+escapeToken(name), obj[name], t)
+		}
+	}
+}
+
+// structMembers returns the types of the fields of struct type t by JSON
+// name, including the ones of the props structs it embeds, and whether t
+// embeds spec.Refable and spec.VendorExtensible.
+func structMembers(t reflect.Type) (fields map[string]reflect.Type, refable, extensible bool) {
+	fields = map[string]reflect.Type{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag, hasTag := f.Tag.Lookup("json")
+		if f.Anonymous && !hasTag {
+			switch f.Type {
+			case refableType:
+				refable = true
+			case vendorExtensibleType:
+				extensible = true
+			default:
+				if f.Type.Kind() == reflect.Struct {
+					embedded, r, e := structMembers(f.Type)
+					for name, ft := range embedded {
+						fields[name] = ft
+					}
+					refable, extensible = refable || r, extensible || e
+				}
+			}
+			continue
+		}
+		if f.PkgPath != "" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields[name] = f.Type
+	}
+	return fields, refable, extensible
+}
+
+func isExtension(name string) bool {
+	return strings.HasPrefix(strings.ToLower(name), "x-")
+}
+
+var tokenEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// escapeToken escapes a JSON pointer token, see RFC 6901.
+func escapeToken(token string) string {
+	return tokenEscaper.Replace(token)
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec3_test
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+
+	"k8s.io/kube-openapi/pkg/spec3"
+)
+
+func TestUnmarshalJSONStrict(t *testing.T) {
+	data := []byte(`{
+  "openapi": "3.0.0",
+  "info": {"title": "test", "version": "v1", "x-team": "api", "licence": {"name": "MIT"}},
+  "x-root": true,
+  "paths": {
+    "x-paths": {},
+    "/foo/{name}": {
+      "summary": "foo",
+      "x-path": 1,
+      "parameters": [{"name": "name", "in": "path", "requried": true}],
+      "get": {
+        "operationId": "getFoo",
+        "responses": {
+          "200": {
+            "description": "OK",
+            "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Foo"}}}
+          },
+          "2XX": {"description": "other"},
+          "default": {"$ref": "#/components/responses/Error", "descripton": "error"}
+        }
+      }
+    }
+  },
+  "components": {
+    "schemas": {
+      "Foo": {
+        "type": "object",
+        "requried": ["name"],
+        "x-kubernetes-preserve-unknown-fields": true,
+        "properties": {
+          "name": {"type": "string", "maxlength": 5},
+          "tags": {"type": "array", "items": {"type": "string", "patern": "^a"}},
+          "labels": {"type": "object", "additionalProperties": {"type": "string", "formt": "byte"}}
+        },
+        "allOf": [{"$schema": "http://json-schema.org/draft-04/schema#", "titel": "base"}]
+      }
+    },
+    "securitySchemes": {"token": {"type": "http", "sheme": "bearer"}}
+  },
+  "securty": []
+}`)
+	var o spec3.OpenAPI
+	err := spec3.UnmarshalJSONStrict(data, &o)
+	var unknown *spec3.UnknownFieldsError
+	if !errors.As(err, &unknown) {
+		t.Fatalf("expected an UnknownFieldsError, got %v", err)
+	}
+	expected := []string{
+		"/components/schemas/Foo/allOf/0/titel",
+		"/components/schemas/Foo/properties/labels/additionalProperties/formt",
+		"/components/schemas/Foo/properties/name/maxlength",
+		"/components/schemas/Foo/properties/tags/items/patern",
+		"/components/schemas/Foo/requried",
+		"/components/securitySchemes/token/sheme",
+		"/info/licence",
+		"/paths/~1foo~1{name}/get/responses/2XX",
+		"/paths/~1foo~1{name}/get/responses/default/descripton",
+		"/paths/~1foo~1{name}/parameters/0/requried",
+		"/securty",
+		"/x-root",
+	}
+	if !reflect.DeepEqual(unknown.Paths, expected) {
+		t.Errorf("expected unknown fields\n%q\ngot\n%q", expected, unknown.Paths)
+	}
+
+	// the document is decoded anyway
+	if o.Paths.Paths["/foo/{name}"].Get.OperationId != "getFoo" {
+		t.Errorf("expected the document to be decoded, got %#v", o.Paths.Paths["/foo/{name}"])
+	}
+}
+
+func TestUnmarshalJSONStrictObjects(t *testing.T) {
+	var p spec3.Parameter
+	err := spec3.UnmarshalJSONStrict([]byte(`{"name": "pretty", "in": "query", "schema": {"type": "boolean", "defualt": false}}`), &p)
+	if err == nil || err.Error() != "unknown fields: /schema/defualt" {
+		t.Errorf("expected unknown field /schema/defualt, got %v", err)
+	}
+	if p.Name != "pretty" {
+		t.Errorf("expected the parameter to be decoded, got %#v", p)
+	}
+
+	if err := spec3.UnmarshalJSONStrict([]byte(`{"name": 1}`), &p); err == nil {
+		t.Errorf("expected a decoding error")
+	} else if errors.As(err, new(*spec3.UnknownFieldsError)) {
+		t.Errorf("expected a decoding error, got %v", err)
+	}
+}
+
+func TestUnmarshalJSONStrictKubernetesSpec(t *testing.T) {
+	data, err := os.ReadFile("testdata/appsv1spec.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var o spec3.OpenAPI
+	if err := spec3.UnmarshalJSONStrict(data, &o); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec3
+
+import (
+	"fmt"
+	"mime"
+	"sort"
+	"strings"
+
+	"k8s.io/kube-openapi/pkg/validation/errors"
+)
+
+// Validate checks o for violations of the OpenAPI specification that cannot
+// be expressed by the Go types alone. It returns nil if o is valid, or a
+// composite error listing every violation found.
+func Validate(o *OpenAPI) error {
+	if o == nil {
+		return nil
+	}
+
+	var schemes SecuritySchemes
+	if o.Components != nil {
+		schemes = o.Components.SecuritySchemes
+	}
+
+	var errs []error
+	errs = append(errs, validateServers("servers", o.Servers)...)
+	errs = append(errs, validateSecurityRequirements("security", o.SecurityRequirement, schemes)...)
+	if o.Paths != nil {
+		errs = append(errs, validatePathItems("paths", o.Paths.Paths, schemes)...)
+	}
+	errs = append(errs, validatePathItems("webhooks", o.Webhooks, schemes)...)
+	if o.Components != nil {
+		for _, name := range sortedKeys(o.Components.RequestBodies) {
+			errs = append(errs, validateRequestBody("components.requestBodies."+name, o.Components.RequestBodies[name])...)
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return errors.CompositeValidationError(errs...)
+}
+
+// validatePathItems checks the servers of the given path items and of their
+// operations, and the request bodies and security requirements of the
+// operations.
+func validatePathItems(path string, items map[string]*Path, schemes SecuritySchemes) []error {
+	var errs []error
+	for _, name := range sortedKeys(items) {
+		item := items[name]
+		if item == nil {
+			continue
+		}
+		prefix := fmt.Sprintf("%s[%s]", path, name)
+		errs = append(errs, validateServers(prefix+".servers", item.Servers)...)
+		for _, op := range []struct {
+			method string
+			op     *Operation
+		}{
+			{"get", item.Get},
+			{"put", item.Put},
+			{"post", item.Post},
+			{"delete", item.Delete},
+			{"options", item.Options},
+			{"head", item.Head},
+			{"patch", item.Patch},
+			{"trace", item.Trace},
+		} {
+			if op.op != nil {
+				errs = append(errs, validateServers(prefix+"."+op.method+".servers", op.op.Servers)...)
+				errs = append(errs, validateRequestBody(prefix+"."+op.method+".requestBody", op.op.RequestBody)...)
+				errs = append(errs, validateSecurityRequirements(prefix+"."+op.method+".security", op.op.SecurityRequirement, schemes)...)
+			}
+		}
+	}
+	return errs
+}
+
+func validateServers(path string, servers []*Server) []error {
+	var errs []error
+	for i, s := range servers {
+		if s == nil {
+			continue
+		}
+		for _, name := range sortedKeys(s.Variables) {
+			errs = append(errs, validateServerVariable(fmt.Sprintf("%s[%d].variables.%s", path, i, name), s.Variables[name])...)
+		}
+	}
+	return errs
+}
+
+// validateServerVariable checks that the default value of v is one of its
+// enum values, if any are declared.
+func validateServerVariable(path string, v *ServerVariable) []error {
+	if v == nil || len(v.Enum) == 0 {
+		return nil
+	}
+	for _, e := range v.Enum {
+		if e == v.Default {
+			return nil
+		}
+	}
+	values := make([]interface{}, 0, len(v.Enum))
+	for _, e := range v.Enum {
+		values = append(values, e)
+	}
+	return []error{errors.EnumFail(path+".default", "", v.Default, values)}
+}
+
+// validateRequestBody checks that b has content, keyed by valid media types
+// or media type ranges.
+func validateRequestBody(path string, b *RequestBody) []error {
+	if b == nil || b.Ref.String() != "" {
+		return nil
+	}
+	if len(b.Content) == 0 {
+		return []error{errors.Required(path+".content", "")}
+	}
+	var errs []error
+	for _, mediaType := range sortedKeys(b.Content) {
+		// mime also accepts content dispositions, which have no subtype
+		if mt, _, err := mime.ParseMediaType(mediaType); err != nil || !strings.Contains(mt, "/") {
+			errs = append(errs, errors.PropertyNotAllowed(path+".content", "", mediaType))
+		}
+	}
+	return errs
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
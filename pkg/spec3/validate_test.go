@@ -0,0 +1,169 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec3_test
+
+import (
+	"strings"
+	"testing"
+
+	"k8s.io/kube-openapi/pkg/spec3"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+func serverWithVariable(enum []string, def string) *spec3.Server {
+	return &spec3.Server{ServerProps: spec3.ServerProps{
+		URL: "https://{region}.example.com",
+		Variables: map[string]*spec3.ServerVariable{
+			"region": {ServerVariableProps: spec3.ServerVariableProps{Enum: enum, Default: def}},
+		},
+	}}
+}
+
+func TestValidateServerVariables(t *testing.T) {
+	cases := []struct {
+		name   string
+		doc    *spec3.OpenAPI
+		errors []string
+	}{
+		{
+			name: "nil document",
+		},
+		{
+			name: "default in enum",
+			doc:  &spec3.OpenAPI{Servers: []*spec3.Server{serverWithVariable([]string{"eu", "us"}, "us")}},
+		},
+		{
+			name: "no enum",
+			doc:  &spec3.OpenAPI{Servers: []*spec3.Server{serverWithVariable(nil, "anything")}},
+		},
+		{
+			name:   "default not in enum",
+			doc:    &spec3.OpenAPI{Servers: []*spec3.Server{serverWithVariable([]string{"eu", "us"}, "ap")}},
+			errors: []string{"servers[0].variables.region.default"},
+		},
+		{
+			name: "path and operation servers",
+			doc: &spec3.OpenAPI{Paths: &spec3.Paths{Paths: map[string]*spec3.Path{
+				"/foo": {
+					PathProps: spec3.PathProps{
+						Servers: []*spec3.Server{serverWithVariable([]string{"eu"}, "")},
+						Get: &spec3.Operation{OperationProps: spec3.OperationProps{
+							Servers: []*spec3.Server{nil, serverWithVariable([]string{"eu"}, "us")},
+						}},
+					},
+				},
+			}}},
+			errors: []string{
+				"paths[/foo].servers[0].variables.region.default",
+				"paths[/foo].get.servers[1].variables.region.default",
+			},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := spec3.Validate(tc.doc)
+			if len(tc.errors) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("expected errors for %v", tc.errors)
+			}
+			for _, e := range tc.errors {
+				if !strings.Contains(err.Error(), e) {
+					t.Errorf("expected error mentioning %q, got %v", e, err)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateRequestBodies(t *testing.T) {
+	schema := spec.StringProperty()
+	withBody := func(b *spec3.RequestBody) *spec3.OpenAPI {
+		return &spec3.OpenAPI{Paths: &spec3.Paths{Paths: map[string]*spec3.Path{
+			"/foo": {PathProps: spec3.PathProps{
+				Post: &spec3.Operation{OperationProps: spec3.OperationProps{RequestBody: b}},
+			}},
+		}}}
+	}
+	cases := []struct {
+		name   string
+		doc    *spec3.OpenAPI
+		errors []string
+	}{
+		{
+			name: "json body",
+			doc:  withBody(spec3.NewJSONRequestBody(schema, true)),
+		},
+		{
+			name: "media type ranges",
+			doc:  withBody(spec3.NewRequestBody(schema, false, "application/*", "*/*", "text/plain; charset=utf-8")),
+		},
+		{
+			name: "reference",
+			doc:  withBody(&spec3.RequestBody{Refable: spec.Refable{Ref: spec.MustCreateRef("#/components/requestBodies/foo")}}),
+		},
+		{
+			name:   "no content",
+			doc:    withBody(spec3.NewRequestBody(schema, true)),
+			errors: []string{"paths[/foo].post.requestBody.content"},
+		},
+		{
+			name:   "invalid media types",
+			doc:    withBody(spec3.NewRequestBody(schema, true, "application/json", "json")),
+			errors: []string{"paths[/foo].post.requestBody.content.json"},
+		},
+		{
+			name: "components",
+			doc: &spec3.OpenAPI{Components: &spec3.Components{RequestBodies: map[string]*spec3.RequestBody{
+				"empty": {},
+			}}},
+			errors: []string{"components.requestBodies.empty.content"},
+		},
+		{
+			name: "webhooks",
+			doc: &spec3.OpenAPI{Webhooks: map[string]*spec3.Path{
+				"newPet": {PathProps: spec3.PathProps{
+					Post: &spec3.Operation{OperationProps: spec3.OperationProps{RequestBody: spec3.NewRequestBody(schema, true)}},
+				}},
+			}},
+			errors: []string{"webhooks[newPet].post.requestBody.content"},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := spec3.Validate(tc.doc)
+			if len(tc.errors) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("expected errors for %v", tc.errors)
+			}
+			for _, e := range tc.errors {
+				if !strings.Contains(err.Error(), e) {
+					t.Errorf("expected error mentioning %q, got %v", e, err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec3
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+// Versions of the OpenAPI specification that documents can be marshaled as.
+const (
+	Version30 = "3.0.0"
+	Version31 = "3.1.0"
+)
+
+// MarshalVersion returns the JSON encoding of o as a document of the given
+// OpenAPI version, 3.0.x or 3.1.x. The data that the two versions represent
+// differently is converted:
+//
+//   - 3.1 lists "null" among the types of nullable schemas, where 3.0 sets
+//     nullable,
+//   - 3.1 exclusive bounds are numbers, where 3.0 uses booleans qualifying
+//     minimum and maximum.
+//
+// The data only defined by 3.1, i.e. jsonSchemaDialect, webhooks, license
+// identifiers and the path items of components, is dropped from 3.0 documents. Schemas
+// with several types besides "null" cannot be represented in 3.0 and are
+// kept as they are. o is not modified.
+func (o *OpenAPI) MarshalVersion(version string) ([]byte, error) {
+	var schemaFn func(*spec.Schema)
+	switch {
+	case strings.HasPrefix(version, "3.0."):
+		schemaFn = schemaToVersion30
+	case strings.HasPrefix(version, "3.1."):
+		schemaFn = schemaToVersion31
+	default:
+		return nil, fmt.Errorf("unsupported OpenAPI version %q", version)
+	}
+	if o == nil {
+		return json.Marshal(o)
+	}
+
+	data, err := json.Marshal(o)
+	if err != nil {
+		return nil, err
+	}
+	// work on a copy, which the in-memory document shares nothing with
+	var converted OpenAPI
+	if err := json.Unmarshal(data, &converted); err != nil {
+		return nil, err
+	}
+	converted.Version = version
+	if strings.HasPrefix(version, "3.0.") {
+		converted.JSONSchemaDialect = ""
+		converted.Webhooks = nil
+		if converted.Info != nil && converted.Info.License != nil {
+			converted.Info.License.Identifier = ""
+		}
+		if converted.Components != nil {
+			converted.Components.PathItems = nil
+		}
+	}
+	visitSchemas(&converted, schemaFn)
+	return json.Marshal(&converted)
+}
+
+// schemaToVersion30 replaces the "null" type of s by nullable.
+func schemaToVersion30(s *spec.Schema) {
+	if !s.Type.Contains("null") {
+		return
+	}
+	var types spec.StringOrArray
+	for _, t := range s.Type {
+		if t != "null" {
+			types = append(types, t)
+		}
+	}
+	s.Type = types
+	s.Nullable = true
+}
+
+// schemaToVersion31 replaces nullable by the "null" type, and the exclusive
+// minimum and maximum of s by numeric exclusive bounds.
+func schemaToVersion31(s *spec.Schema) {
+	if s.Nullable {
+		if len(s.Type) > 0 && !s.Type.Contains("null") {
+			s.Type = append(s.Type, "null")
+		}
+		s.Nullable = false
+	}
+	// the schema props only hold boolean exclusive bounds, which are left
+	// unset for the numeric ones to be marshaled from the extra props
+	if s.ExclusiveMaximum && s.Maximum != nil {
+		setExtraProp(s, "exclusiveMaximum", *s.Maximum)
+		s.Maximum = nil
+	}
+	if s.ExclusiveMinimum && s.Minimum != nil {
+		setExtraProp(s, "exclusiveMinimum", *s.Minimum)
+		s.Minimum = nil
+	}
+	s.ExclusiveMaximum = false
+	s.ExclusiveMinimum = false
+}
+
+func setExtraProp(s *spec.Schema, key string, value interface{}) {
+	if s.ExtraProps == nil {
+		s.ExtraProps = map[string]interface{}{}
+	}
+	s.ExtraProps[key] = value
+}
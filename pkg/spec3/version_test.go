@@ -0,0 +1,144 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec3_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"k8s.io/kube-openapi/pkg/spec3"
+)
+
+const version31Doc = `{
+  "openapi": "3.1.0",
+  "jsonSchemaDialect": "https://spec.openapis.org/oas/3.1/dialect/base",
+  "info": {"title": "test", "version": "v1", "license": {"name": "Apache 2.0", "identifier": "Apache-2.0"}},
+  "components": {
+    "schemas": {
+      "Replicas": {"type": ["integer", "null"], "exclusiveMinimum": 0, "maximum": 10},
+      "Name": {"type": "string", "nullable": true}
+    },
+    "pathItems": {
+      "status": {"get": {"responses": {"200": {"description": "OK"}}}}
+    }
+  }
+}`
+
+const version30Doc = `{
+  "openapi": "3.0.3",
+  "info": {"title": "test", "version": "v1", "license": {"name": "Apache 2.0"}},
+  "components": {
+    "schemas": {
+      "Replicas": {"type": "integer", "nullable": true, "minimum": 0, "exclusiveMinimum": true, "maximum": 10},
+      "Name": {"type": "string", "nullable": true}
+    }
+  }
+}`
+
+func TestMarshalVersion(t *testing.T) {
+	var o *spec3.OpenAPI
+	if err := json.Unmarshal([]byte(version31Doc), &o); err != nil {
+		t.Fatal(err)
+	}
+	before, err := json.Marshal(o)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		version  string
+		expected string
+	}{
+		{
+			version: "3.1.0",
+			expected: `{
+  "openapi": "3.1.0",
+  "jsonSchemaDialect": "https://spec.openapis.org/oas/3.1/dialect/base",
+  "info": {"title": "test", "version": "v1", "license": {"name": "Apache 2.0", "identifier": "Apache-2.0"}},
+  "components": {
+    "schemas": {
+      "Replicas": {"type": ["integer", "null"], "exclusiveMinimum": 0, "maximum": 10},
+      "Name": {"type": ["string", "null"]}
+    },
+    "pathItems": {
+      "status": {"get": {"responses": {"200": {"description": "OK"}}}}
+    }
+  }
+}`,
+		},
+		{
+			version:  "3.0.3",
+			expected: version30Doc,
+		},
+	} {
+		t.Run(tc.version, func(t *testing.T) {
+			data, err := o.MarshalVersion(tc.version)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertJSONEqual(t, tc.expected, string(data))
+		})
+	}
+
+	after, err := json.Marshal(o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(before) != string(after) {
+		t.Errorf("expected the document not to be modified, got %s", after)
+	}
+
+	// 3.0 documents read back as the same 3.1 document, without the data only
+	// defined by 3.1
+	var v30 *spec3.OpenAPI
+	if err := json.Unmarshal([]byte(version30Doc), &v30); err != nil {
+		t.Fatal(err)
+	}
+	data, err := v30.MarshalVersion(spec3.Version31)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertJSONEqual(t, `{
+  "openapi": "3.1.0",
+  "info": {"title": "test", "version": "v1", "license": {"name": "Apache 2.0"}},
+  "components": {
+    "schemas": {
+      "Replicas": {"type": ["integer", "null"], "exclusiveMinimum": 0, "maximum": 10},
+      "Name": {"type": ["string", "null"]}
+    }
+  }
+}`, string(data))
+
+	if _, err := o.MarshalVersion("2.0"); err == nil {
+		t.Errorf("expected an error for OpenAPI 2.0")
+	}
+}
+
+func assertJSONEqual(t *testing.T, expected, actual string) {
+	t.Helper()
+	var e, a interface{}
+	if err := json.Unmarshal([]byte(expected), &e); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(actual), &a); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(e, a) {
+		t.Errorf("expected %s, got %s", expected, actual)
+	}
+}
@@ -0,0 +1,335 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package specstats computes summary statistics for OpenAPI v2 and v3
+// documents. The output is meant to be serialized and compared across
+// releases to track how the published specs grow.
+package specstats
+
+import (
+	"k8s.io/kube-openapi/pkg/spec3"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+// Stats holds cardinality information about a single OpenAPI document.
+type Stats struct {
+	// Paths is the number of entries in the paths object.
+	Paths int `json:"paths"`
+	// Operations is the number of operations across all paths.
+	Operations int `json:"operations"`
+	// OperationsByMethod breaks Operations down by lowercase HTTP method.
+	OperationsByMethod map[string]int `json:"operationsByMethod,omitempty"`
+	// Definitions is the number of top-level named schemas, i.e. the
+	// definitions section for v2 and components.schemas for v3.
+	Definitions int `json:"definitions"`
+	// Schemas is the number of schema nodes visited, including nested
+	// properties, items and composition members. References are not followed.
+	Schemas int `json:"schemas"`
+	// Enums is the number of schemas declaring an enum.
+	Enums int `json:"enums"`
+	// EnumValues is the total number of enum values across all schemas.
+	EnumValues int `json:"enumValues"`
+	// MaxEnumSize is the size of the largest enum.
+	MaxEnumSize int `json:"maxEnumSize"`
+	// DescriptionBytes is the total length of all description fields.
+	DescriptionBytes int `json:"descriptionBytes"`
+	// Extensions counts occurrences of each vendor extension key.
+	Extensions map[string]int `json:"extensions,omitempty"`
+}
+
+// ForSwagger computes statistics for an OpenAPI v2 document.
+func ForSwagger(sw *spec.Swagger) *Stats {
+	s := newStats()
+	if sw == nil {
+		return s
+	}
+	s.addExtensions(sw.Extensions)
+	if sw.Info != nil {
+		s.DescriptionBytes += len(sw.Info.Description)
+		s.addExtensions(sw.Info.Extensions)
+	}
+	if sw.Paths != nil {
+		s.addExtensions(sw.Paths.Extensions)
+		for _, item := range sw.Paths.Paths {
+			s.Paths++
+			s.addExtensions(item.Extensions)
+			s.addParameters(item.Parameters)
+			s.addOperation("get", item.Get)
+			s.addOperation("put", item.Put)
+			s.addOperation("post", item.Post)
+			s.addOperation("delete", item.Delete)
+			s.addOperation("options", item.Options)
+			s.addOperation("head", item.Head)
+			s.addOperation("patch", item.Patch)
+		}
+	}
+	s.Definitions = len(sw.Definitions)
+	for k := range sw.Definitions {
+		def := sw.Definitions[k]
+		s.addSchema(&def)
+	}
+	s.addParameters(mapValues(sw.Parameters))
+	for k := range sw.Responses {
+		r := sw.Responses[k]
+		s.addResponse(&r)
+	}
+	return s
+}
+
+// ForOpenAPI computes statistics for an OpenAPI v3 document.
+func ForOpenAPI(o *spec3.OpenAPI) *Stats {
+	s := newStats()
+	if o == nil {
+		return s
+	}
+	if o.Info != nil {
+		s.DescriptionBytes += len(o.Info.Description)
+		s.addExtensions(o.Info.Extensions)
+	}
+	if o.Paths != nil {
+		s.addExtensions(o.Paths.Extensions)
+		for _, item := range o.Paths.Paths {
+			if item == nil {
+				continue
+			}
+			s.Paths++
+			s.DescriptionBytes += len(item.Description)
+			s.addExtensions(item.Extensions)
+			for _, p := range item.Parameters {
+				s.addParameter3(p)
+			}
+			s.addOperation3("get", item.Get)
+			s.addOperation3("put", item.Put)
+			s.addOperation3("post", item.Post)
+			s.addOperation3("delete", item.Delete)
+			s.addOperation3("options", item.Options)
+			s.addOperation3("head", item.Head)
+			s.addOperation3("patch", item.Patch)
+			s.addOperation3("trace", item.Trace)
+		}
+	}
+	if c := o.Components; c != nil {
+		s.Definitions = len(c.Schemas)
+		for _, sch := range c.Schemas {
+			s.addSchema(sch)
+		}
+		for _, p := range c.Parameters {
+			s.addParameter3(p)
+		}
+		for _, r := range c.Responses {
+			s.addResponse3(r)
+		}
+		for _, rb := range c.RequestBodies {
+			s.addRequestBody3(rb)
+		}
+		for _, h := range c.Headers {
+			s.addHeader3(h)
+		}
+	}
+	return s
+}
+
+func newStats() *Stats {
+	return &Stats{
+		OperationsByMethod: map[string]int{},
+		Extensions:         map[string]int{},
+	}
+}
+
+func mapValues(m map[string]spec.Parameter) []spec.Parameter {
+	var ret []spec.Parameter
+	for _, v := range m {
+		ret = append(ret, v)
+	}
+	return ret
+}
+
+func (s *Stats) addExtensions(ext map[string]interface{}) {
+	for k := range ext {
+		s.Extensions[k]++
+	}
+}
+
+func (s *Stats) addOperation(method string, op *spec.Operation) {
+	if op == nil {
+		return
+	}
+	s.Operations++
+	s.OperationsByMethod[method]++
+	s.DescriptionBytes += len(op.Description)
+	s.addExtensions(op.Extensions)
+	s.addParameters(op.Parameters)
+	if op.Responses != nil {
+		s.addExtensions(op.Responses.Extensions)
+		s.addResponse(op.Responses.Default)
+		for k := range op.Responses.StatusCodeResponses {
+			r := op.Responses.StatusCodeResponses[k]
+			s.addResponse(&r)
+		}
+	}
+}
+
+func (s *Stats) addParameters(params []spec.Parameter) {
+	for i := range params {
+		p := &params[i]
+		s.DescriptionBytes += len(p.Description)
+		s.addExtensions(p.Extensions)
+		s.addEnum(len(p.Enum))
+		s.addSchema(p.Schema)
+	}
+}
+
+func (s *Stats) addResponse(r *spec.Response) {
+	if r == nil {
+		return
+	}
+	s.DescriptionBytes += len(r.Description)
+	s.addExtensions(r.Extensions)
+	s.addSchema(r.Schema)
+}
+
+func (s *Stats) addOperation3(method string, op *spec3.Operation) {
+	if op == nil {
+		return
+	}
+	s.Operations++
+	s.OperationsByMethod[method]++
+	s.DescriptionBytes += len(op.Description)
+	s.addExtensions(op.Extensions)
+	for _, p := range op.Parameters {
+		s.addParameter3(p)
+	}
+	s.addRequestBody3(op.RequestBody)
+	if op.Responses != nil {
+		s.addExtensions(op.Responses.Extensions)
+		s.addResponse3(op.Responses.Default)
+		for _, r := range op.Responses.StatusCodeResponses {
+			s.addResponse3(r)
+		}
+	}
+}
+
+func (s *Stats) addParameter3(p *spec3.Parameter) {
+	if p == nil {
+		return
+	}
+	s.DescriptionBytes += len(p.Description)
+	s.addExtensions(p.Extensions)
+	s.addSchema(p.Schema)
+	s.addContent3(p.Content)
+}
+
+func (s *Stats) addRequestBody3(rb *spec3.RequestBody) {
+	if rb == nil {
+		return
+	}
+	s.DescriptionBytes += len(rb.Description)
+	s.addExtensions(rb.Extensions)
+	s.addContent3(rb.Content)
+}
+
+func (s *Stats) addResponse3(r *spec3.Response) {
+	if r == nil {
+		return
+	}
+	s.DescriptionBytes += len(r.Description)
+	s.addExtensions(r.Extensions)
+	for _, h := range r.Headers {
+		s.addHeader3(h)
+	}
+	s.addContent3(r.Content)
+}
+
+func (s *Stats) addHeader3(h *spec3.Header) {
+	if h == nil {
+		return
+	}
+	s.DescriptionBytes += len(h.Description)
+	s.addExtensions(h.Extensions)
+	s.addSchema(h.Schema)
+	s.addContent3(h.Content)
+}
+
+func (s *Stats) addContent3(content map[string]*spec3.MediaType) {
+	for _, mt := range content {
+		if mt == nil {
+			continue
+		}
+		s.addExtensions(mt.Extensions)
+		s.addSchema(mt.Schema)
+	}
+}
+
+func (s *Stats) addEnum(n int) {
+	if n == 0 {
+		return
+	}
+	s.Enums++
+	s.EnumValues += n
+	if n > s.MaxEnumSize {
+		s.MaxEnumSize = n
+	}
+}
+
+func (s *Stats) addSchema(sch *spec.Schema) {
+	if sch == nil {
+		return
+	}
+	s.Schemas++
+	s.DescriptionBytes += len(sch.Description)
+	s.addExtensions(sch.Extensions)
+	s.addEnum(len(sch.Enum))
+
+	for k := range sch.Properties {
+		p := sch.Properties[k]
+		s.addSchema(&p)
+	}
+	for k := range sch.PatternProperties {
+		p := sch.PatternProperties[k]
+		s.addSchema(&p)
+	}
+	for k := range sch.Definitions {
+		d := sch.Definitions[k]
+		s.addSchema(&d)
+	}
+	for _, list := range [][]spec.Schema{sch.AllOf, sch.AnyOf, sch.OneOf} {
+		for i := range list {
+			s.addSchema(&list[i])
+		}
+	}
+	s.addSchema(sch.Not)
+	if sch.Items != nil {
+		s.addSchema(sch.Items.Schema)
+		for i := range sch.Items.Schemas {
+			s.addSchema(&sch.Items.Schemas[i])
+		}
+	}
+	if sch.AdditionalProperties != nil {
+		s.addSchema(sch.AdditionalProperties.Schema)
+	}
+	if sch.AdditionalItems != nil {
+		s.addSchema(sch.AdditionalItems.Schema)
+	}
+	for i := range sch.PrefixItems {
+		s.addSchema(&sch.PrefixItems[i])
+	}
+	if sch.UnevaluatedItems != nil {
+		s.addSchema(sch.UnevaluatedItems.Schema)
+	}
+	if sch.UnevaluatedProperties != nil {
+		s.addSchema(sch.UnevaluatedProperties.Schema)
+	}
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package specstats
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/kube-openapi/pkg/spec3"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+func TestForSwagger(t *testing.T) {
+	var sw spec.Swagger
+	if err := json.Unmarshal([]byte(`{
+  "swagger": "2.0",
+  "info": {"title": "t", "version": "v1", "description": "abcd"},
+  "paths": {
+    "/foo": {
+      "get": {
+        "description": "get foo",
+        "x-kubernetes-action": "get",
+        "responses": {"200": {"description": "ok", "schema": {"$ref": "#/definitions/Foo"}}}
+      },
+      "post": {
+        "x-kubernetes-action": "post",
+        "parameters": [{"name": "p", "in": "query", "type": "string", "enum": ["a", "b", "c"]}],
+        "responses": {"201": {"description": "created"}}
+      }
+    },
+    "/bar": {}
+  },
+  "definitions": {
+    "Foo": {
+      "type": "object",
+      "x-kubernetes-group-version-kind": [],
+      "properties": {
+        "kind": {"type": "string", "enum": ["A", "B"]},
+        "items": {"type": "array", "items": {"type": "string", "description": "xy"}}
+      }
+    }
+  }
+}`), &sw); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := &Stats{
+		Paths:              2,
+		Operations:         2,
+		OperationsByMethod: map[string]int{"get": 1, "post": 1},
+		Definitions:        1,
+		Schemas:            5,
+		Enums:              2,
+		EnumValues:         5,
+		MaxEnumSize:        3,
+		DescriptionBytes:   len("abcd") + len("get foo") + len("ok") + len("created") + len("xy"),
+		Extensions:         map[string]int{"x-kubernetes-action": 2, "x-kubernetes-group-version-kind": 1},
+	}
+	if diff := cmp.Diff(expected, ForSwagger(&sw)); diff != "" {
+		t.Errorf("unexpected stats (-want +got):\n%s", diff)
+	}
+}
+
+func TestForOpenAPI(t *testing.T) {
+	var o spec3.OpenAPI
+	if err := json.Unmarshal([]byte(`{
+  "openapi": "3.0.0",
+  "info": {"title": "t", "version": "v1"},
+  "paths": {
+    "/foo": {
+      "parameters": [{"name": "p", "in": "query", "schema": {"type": "string", "enum": ["a"]}}],
+      "put": {
+        "requestBody": {"content": {"application/json": {"schema": {"$ref": "#/components/schemas/Foo"}}}},
+        "responses": {"200": {"description": "ok", "content": {"application/json": {"schema": {"type": "object"}}}}}
+      },
+      "trace": {}
+    }
+  },
+  "components": {
+    "schemas": {
+      "Foo": {"type": "object", "description": "foo", "x-kubernetes-preserve-unknown-fields": true}
+    }
+  }
+}`), &o); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := &Stats{
+		Paths:              1,
+		Operations:         2,
+		OperationsByMethod: map[string]int{"put": 1, "trace": 1},
+		Definitions:        1,
+		Schemas:            4,
+		Enums:              1,
+		EnumValues:         1,
+		MaxEnumSize:        1,
+		DescriptionBytes:   len("ok") + len("foo"),
+		Extensions:         map[string]int{"x-kubernetes-preserve-unknown-fields": 1},
+	}
+	if diff := cmp.Diff(expected, ForOpenAPI(&o)); diff != "" {
+		t.Errorf("unexpected stats (-want +got):\n%s", diff)
+	}
+}
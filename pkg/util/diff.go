@@ -0,0 +1,119 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DiffType is the kind of difference found at a field path.
+type DiffType string
+
+const (
+	// FieldAdded means the field is only present in the second value.
+	FieldAdded DiffType = "added"
+	// FieldRemoved means the field is only present in the first value.
+	FieldRemoved DiffType = "removed"
+	// FieldChanged means the field is present in both values, with
+	// different contents.
+	FieldChanged DiffType = "changed"
+)
+
+// FieldDiff is a difference between two unstructured values.
+type FieldDiff struct {
+	// Path is the path of the field, e.g. "spec.containers[0].name".
+	Path string
+	Type DiffType
+	// Old is the value in the first object, nil if the field was added.
+	Old interface{}
+	// New is the value in the second object, nil if the field was removed.
+	New interface{}
+}
+
+func (d FieldDiff) String() string {
+	switch d.Type {
+	case FieldAdded:
+		return fmt.Sprintf("%s: added %v", d.Path, d.New)
+	case FieldRemoved:
+		return fmt.Sprintf("%s: removed %v", d.Path, d.Old)
+	default:
+		return fmt.Sprintf("%s: changed from %v to %v", d.Path, d.Old, d.New)
+	}
+}
+
+// DiffUnstructured returns the differences between a and b, sorted by
+// path. Maps and lists are compared recursively so that the differences
+// are reported at the deepest fields, and leaves are compared with
+// JSONEqual. A list whose length changed is reported as changed as a
+// whole, since its items cannot be matched without a schema.
+func DiffUnstructured(a, b map[string]interface{}) []FieldDiff {
+	var diffs []FieldDiff
+	diffMaps("", a, b, &diffs)
+	sort.SliceStable(diffs, func(i, j int) bool { return diffs[i].Path < diffs[j].Path })
+	return diffs
+}
+
+func diffValues(path string, a, b interface{}, diffs *[]FieldDiff) {
+	switch a := a.(type) {
+	case map[string]interface{}:
+		if b, ok := b.(map[string]interface{}); ok && a != nil && b != nil {
+			diffMaps(path, a, b, diffs)
+			return
+		}
+	case []interface{}:
+		if b, ok := b.([]interface{}); ok && a != nil && b != nil && len(a) == len(b) {
+			for i := range a {
+				diffValues(path+"["+strconv.Itoa(i)+"]", a[i], b[i], diffs)
+			}
+			return
+		}
+	}
+	if !JSONEqual(a, b) {
+		*diffs = append(*diffs, FieldDiff{Path: path, Type: FieldChanged, Old: a, New: b})
+	}
+}
+
+func diffMaps(path string, a, b map[string]interface{}, diffs *[]FieldDiff) {
+	for k, av := range a {
+		p := fieldPath(path, k)
+		if bv, ok := b[k]; ok {
+			diffValues(p, av, bv, diffs)
+		} else {
+			*diffs = append(*diffs, FieldDiff{Path: p, Type: FieldRemoved, Old: av})
+		}
+	}
+	for k, bv := range b {
+		if _, ok := a[k]; !ok {
+			*diffs = append(*diffs, FieldDiff{Path: fieldPath(path, k), Type: FieldAdded, New: bv})
+		}
+	}
+}
+
+// fieldPath appends the field named key to path. Keys that would make the
+// path ambiguous are quoted in brackets, e.g. metadata.labels["a.b/c"].
+func fieldPath(path, key string) string {
+	if key == "" || strings.ContainsAny(key, `.[]"`) {
+		return path + "[" + strconv.Quote(key) + "]"
+	}
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
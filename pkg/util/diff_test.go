@@ -0,0 +1,92 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiffUnstructured(t *testing.T) {
+	var tests = []struct {
+		name     string
+		a, b     string
+		expected []FieldDiff
+	}{
+		{
+			name: "equal",
+			a:    `{"a": 1, "b": {"c": [1, 2]}}`,
+			b:    `{"b": {"c": [1.0, 2]}, "a": 1}`,
+		},
+		{
+			name: "nested",
+			a:    `{"spec": {"replicas": 1, "paused": true, "selector": {}}}`,
+			b:    `{"spec": {"replicas": 2, "strategy": "Recreate", "selector": {}}}`,
+			expected: []FieldDiff{
+				{Path: "spec.paused", Type: FieldRemoved, Old: true},
+				{Path: "spec.replicas", Type: FieldChanged, Old: json.Number("1"), New: json.Number("2")},
+				{Path: "spec.strategy", Type: FieldAdded, New: "Recreate"},
+			},
+		},
+		{
+			name: "lists",
+			a:    `{"containers": [{"name": "a", "image": "x"}], "args": ["a"]}`,
+			b:    `{"containers": [{"name": "a", "image": "y"}], "args": ["a", "b"]}`,
+			expected: []FieldDiff{
+				{Path: "args", Type: FieldChanged, Old: []interface{}{"a"}, New: []interface{}{"a", "b"}},
+				{Path: "containers[0].image", Type: FieldChanged, Old: "x", New: "y"},
+			},
+		},
+		{
+			name: "type change",
+			a:    `{"a": {"b": 1}}`,
+			b:    `{"a": "b"}`,
+			expected: []FieldDiff{
+				{Path: "a", Type: FieldChanged, Old: map[string]interface{}{"b": json.Number("1")}, New: "b"},
+			},
+		},
+		{
+			name: "quoted keys",
+			a:    `{"metadata": {"labels": {"example.com/a": "x"}}}`,
+			b:    `{"metadata": {"labels": {"": "y"}}}`,
+			expected: []FieldDiff{
+				{Path: `metadata.labels[""]`, Type: FieldAdded, New: "y"},
+				{Path: `metadata.labels["example.com/a"]`, Type: FieldRemoved, Old: "x"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DiffUnstructured(decode(t, tt.a), decode(t, tt.b))
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func decode(t *testing.T, s string) map[string]interface{} {
+	var m map[string]interface{}
+	d := json.NewDecoder(strings.NewReader(s))
+	d.UseNumber()
+	if err := d.Decode(&m); err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"encoding/json"
+	"math/big"
+	"reflect"
+)
+
+// JSONEqual reports whether a and b represent the same JSON value. They are
+// unstructured trees made of maps keyed by strings, slices, strings, bools,
+// numbers and nil, as decoded by encoding/json or converted from objects.
+//
+// Unlike reflect.DeepEqual, numbers are compared by value whatever their Go
+// type: int64(1), float64(1) and json.Number("1.0") are equal. Nil maps and
+// slices are equal to nil, as they are all encoded as null, but not to
+// empty maps and slices. Values of other types are compared with
+// reflect.DeepEqual.
+func JSONEqual(a, b interface{}) bool {
+	if isNull(a) || isNull(b) {
+		return isNull(a) && isNull(b)
+	}
+	switch a := a.(type) {
+	case map[string]interface{}:
+		b, ok := b.(map[string]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for k, av := range a {
+			bv, ok := b[k]
+			if !ok || !JSONEqual(av, bv) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		b, ok := b.([]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !JSONEqual(a[i], b[i]) {
+				return false
+			}
+		}
+		return true
+	case string:
+		b, ok := b.(string)
+		return ok && a == b
+	case bool:
+		b, ok := b.(bool)
+		return ok && a == b
+	}
+
+	if _, isNumber := a.(json.Number); !isNumber && reflect.TypeOf(a) == reflect.TypeOf(b) {
+		return reflect.DeepEqual(a, b)
+	}
+	ar, ok := toRat(a)
+	if !ok {
+		return false
+	}
+	br, ok := toRat(b)
+	return ok && ar.Cmp(br) == 0
+}
+
+func isNull(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	switch v := v.(type) {
+	case map[string]interface{}:
+		return v == nil
+	case []interface{}:
+		return v == nil
+	}
+	return false
+}
+
+// toRat returns the exact value of a number, or false if v is not a number
+// or is not finite.
+func toRat(v interface{}) (*big.Rat, bool) {
+	switch v := v.(type) {
+	case int:
+		return new(big.Rat).SetInt64(int64(v)), true
+	case int8:
+		return new(big.Rat).SetInt64(int64(v)), true
+	case int16:
+		return new(big.Rat).SetInt64(int64(v)), true
+	case int32:
+		return new(big.Rat).SetInt64(int64(v)), true
+	case int64:
+		return new(big.Rat).SetInt64(v), true
+	case uint:
+		return new(big.Rat).SetUint64(uint64(v)), true
+	case uint8:
+		return new(big.Rat).SetUint64(uint64(v)), true
+	case uint16:
+		return new(big.Rat).SetUint64(uint64(v)), true
+	case uint32:
+		return new(big.Rat).SetUint64(uint64(v)), true
+	case uint64:
+		return new(big.Rat).SetUint64(v), true
+	case float32:
+		r := new(big.Rat).SetFloat64(float64(v))
+		return r, r != nil
+	case float64:
+		r := new(big.Rat).SetFloat64(v)
+		return r, r != nil
+	case json.Number:
+		return new(big.Rat).SetString(string(v))
+	}
+	return nil, false
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func TestJSONEqual(t *testing.T) {
+	var tests = []struct {
+		name  string
+		a, b  interface{}
+		equal bool
+	}{
+		{"nil", nil, nil, true},
+		{"nil map", map[string]interface{}(nil), nil, true},
+		{"nil slice", []interface{}(nil), map[string]interface{}(nil), true},
+		{"empty map", map[string]interface{}{}, nil, false},
+		{"empty slice", []interface{}{}, []interface{}(nil), false},
+		{"empty map and slice", map[string]interface{}{}, []interface{}{}, false},
+		{"strings", "a", "a", true},
+		{"different strings", "a", "b", false},
+		{"bools", true, true, true},
+		{"bool and string", true, "true", false},
+		{"int64 and float64", int64(1), float64(1), true},
+		{"int and int64", 3, int64(3), true},
+		{"fractional float64", int64(1), 1.5, false},
+		{"large int64", int64(math.MaxInt64), float64(math.MaxInt64), false},
+		{"uint64 and int64", uint64(7), int64(7), true},
+		{"json.Number", json.Number("2.0"), int64(2), true},
+		{"json.Number exponent", json.Number("1e2"), float64(100), true},
+		{"json.Numbers", json.Number("1"), json.Number("1.0"), true},
+		{"invalid json.Number", json.Number("x"), int64(1), false},
+		{"NaN", math.NaN(), int64(0), false},
+		{"number and string", int64(1), "1", false},
+		{
+			"nested",
+			map[string]interface{}{"a": []interface{}{int64(1), map[string]interface{}{"b": 2.0}}},
+			map[string]interface{}{"a": []interface{}{1.0, map[string]interface{}{"b": json.Number("2")}}},
+			true,
+		},
+		{
+			"missing key",
+			map[string]interface{}{"a": nil},
+			map[string]interface{}{"b": nil},
+			false,
+		},
+		{
+			"different lengths",
+			[]interface{}{int64(1)},
+			[]interface{}{int64(1), int64(1)},
+			false,
+		},
+	}
+	for _, test := range tests {
+		if got := JSONEqual(test.a, test.b); got != test.equal {
+			t.Errorf("%s: JSONEqual(%#v, %#v) = %v", test.name, test.a, test.b, got)
+		}
+		if got := JSONEqual(test.b, test.a); got != test.equal {
+			t.Errorf("%s: JSONEqual(%#v, %#v) = %v", test.name, test.b, test.a, got)
+		}
+	}
+}
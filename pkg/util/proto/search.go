@@ -0,0 +1,178 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proto
+
+import (
+	"sort"
+	"strings"
+)
+
+// Match kinds, from the most to the least relevant.
+const (
+	// MatchName is a field whose name is the query.
+	MatchName = iota
+	// MatchNamePrefix is a field whose name starts with the query.
+	MatchNamePrefix
+	// MatchNameSubstring is a field whose name contains the query.
+	MatchNameSubstring
+	// MatchDescription is a field whose description contains the query.
+	MatchDescription
+)
+
+// SearchMatch is a field found by Search.
+type SearchMatch struct {
+	// Model is the name of the model holding the field.
+	Model string
+	// Path is the path of the field in the model, e.g. ["spec", "replicas"].
+	// Arrays and maps are transparent, like in kubectl explain.
+	Path []string
+	// Kind is how the field matched, e.g. MatchName.
+	Kind int
+	// Schema is the schema of the field.
+	Schema Schema
+}
+
+// String returns the path of the field, e.g.
+// "io.k8s.api.apps.v1.DeploymentSpec.replicas".
+func (m SearchMatch) String() string {
+	return strings.Join(append([]string{m.Model}, m.Path...), ".")
+}
+
+// SearchOptions configures Search.
+type SearchOptions struct {
+	// Descriptions makes Search also match the descriptions of the fields.
+	Descriptions bool
+	// Limit is the maximum number of matches returned, all of them if 0.
+	Limit int
+}
+
+// Search finds the fields of all the models whose name, or description if
+// enabled, contains query, ignoring case. The fields of the objects, arrays
+// and maps defined inline in the models are searched too, but references are
+// not followed since the referenced models are searched themselves.
+//
+// Matches are ranked by kind, then by the length of their path so that the
+// shallowest fields come first, then by model and path.
+func Search(models Models, query string, options SearchOptions) []SearchMatch {
+	query = strings.ToLower(query)
+	if query == "" {
+		return nil
+	}
+	s := &searcher{query: query, options: options}
+	for _, model := range models.ListModels() {
+		schema := models.LookupModel(model)
+		if schema == nil {
+			continue
+		}
+		s.model = model
+		s.path = nil
+		schema.Accept(s)
+	}
+
+	sort.SliceStable(s.matches, func(i, j int) bool {
+		a, b := s.matches[i], s.matches[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if len(a.Path) != len(b.Path) {
+			return len(a.Path) < len(b.Path)
+		}
+		if a.Model != b.Model {
+			return a.Model < b.Model
+		}
+		return strings.Join(a.Path, ".") < strings.Join(b.Path, ".")
+	})
+	if options.Limit > 0 && len(s.matches) > options.Limit {
+		s.matches = s.matches[:options.Limit]
+	}
+	return s.matches
+}
+
+// searcher visits the schemas of a model and collects the matching fields.
+type searcher struct {
+	query   string
+	options SearchOptions
+
+	model   string
+	path    []string
+	matches []SearchMatch
+}
+
+var _ SchemaVisitorArbitrary = &searcher{}
+
+func (s *searcher) VisitArray(a *Array) {
+	a.SubType.Accept(s)
+}
+
+func (s *searcher) VisitMap(m *Map) {
+	m.SubType.Accept(s)
+}
+
+func (s *searcher) VisitPrimitive(*Primitive) {}
+
+func (s *searcher) VisitArbitrary(*Arbitrary) {}
+
+func (s *searcher) VisitReference(Reference) {}
+
+func (s *searcher) VisitKind(k *Kind) {
+	parent := s.path
+	for _, field := range k.Keys() {
+		schema := k.Fields[field]
+		// copy the path, so that matches do not share their backing array
+		path := append(append([]string{}, parent...), field)
+		if kind, ok := s.match(field, schema); ok {
+			s.matches = append(s.matches, SearchMatch{
+				Model:  s.model,
+				Path:   path,
+				Kind:   kind,
+				Schema: schema,
+			})
+		}
+		s.path = path
+		schema.Accept(s)
+	}
+	s.path = parent
+}
+
+// match returns how the field named name matches the query, and false if it
+// does not.
+func (s *searcher) match(name string, schema Schema) (int, bool) {
+	name = strings.ToLower(name)
+	switch {
+	case name == s.query:
+		return MatchName, true
+	case strings.HasPrefix(name, s.query):
+		return MatchNamePrefix, true
+	case strings.Contains(name, s.query):
+		return MatchNameSubstring, true
+	case s.options.Descriptions && strings.Contains(strings.ToLower(fieldDescription(schema)), s.query):
+		return MatchDescription, true
+	}
+	return 0, false
+}
+
+// fieldDescription returns the description of a field, or of the model it
+// references if it has none.
+func fieldDescription(schema Schema) string {
+	if description := schema.GetDescription(); description != "" {
+		return description
+	}
+	if ref, ok := schema.(Reference); ok && ref.SubSchema() != nil {
+		return ref.SubSchema().GetDescription()
+	}
+	return ""
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proto_test
+
+import (
+	"sort"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/kube-openapi/pkg/util/proto"
+)
+
+var _ = Describe("Searching fields of the models", func() {
+	var models proto.Models
+	BeforeEach(func() {
+		s, err := fakeSchema.OpenAPISchema()
+		Expect(err).To(BeNil())
+		models, err = proto.NewOpenAPIData(s)
+		Expect(err).To(BeNil())
+	})
+
+	It("should rank exact names, then prefixes, then substrings", func() {
+		matches := proto.Search(models, "Replicas", proto.SearchOptions{})
+		Expect(matches).ToNot(BeEmpty())
+		Expect(matches[0].Kind).To(Equal(proto.MatchName))
+		Expect(matches[0].String()).To(Equal("io.k8s.api.apps.v1beta1.DeploymentSpec.replicas"))
+		for i := 1; i < len(matches); i++ {
+			Expect(matches[i].Kind).To(BeNumerically(">=", matches[i-1].Kind))
+		}
+		Expect(matches[len(matches)-1].Kind).To(Equal(proto.MatchNameSubstring))
+
+		var paths []string
+		for _, m := range matches {
+			Expect(strings.ToLower(m.Path[len(m.Path)-1])).To(ContainSubstring("replicas"))
+			paths = append(paths, m.String())
+		}
+		Expect(paths).To(ContainElement("io.k8s.api.apps.v1beta1.DeploymentStatus.readyReplicas"))
+		Expect(paths).To(ContainElement("io.k8s.api.apps.v1beta1.StatefulSetStatus.currentReplicas"))
+	})
+
+	It("should find fields of inline objects with their path", func() {
+		models := fakeModels{"Pod": &proto.Kind{Fields: map[string]proto.Schema{
+			"containers": &proto.Array{SubType: &proto.Kind{Fields: map[string]proto.Schema{
+				"ports": &proto.Map{SubType: &proto.Kind{Fields: map[string]proto.Schema{
+					"containerPort": &proto.Primitive{Type: proto.Integer},
+				}}},
+			}}},
+		}}}
+		matches := proto.Search(models, "port", proto.SearchOptions{})
+		Expect(matches).To(HaveLen(2))
+		Expect(matches[0].String()).To(Equal("Pod.containers.ports"))
+		Expect(matches[0].Kind).To(Equal(proto.MatchNamePrefix))
+		Expect(matches[1].String()).To(Equal("Pod.containers.ports.containerPort"))
+		Expect(matches[1].Kind).To(Equal(proto.MatchNameSubstring))
+	})
+
+	It("should only match descriptions if enabled", func() {
+		query := "created by the StatefulSet controller that have a Ready Condition"
+		Expect(proto.Search(models, query, proto.SearchOptions{})).To(BeEmpty())
+
+		matches := proto.Search(models, query, proto.SearchOptions{Descriptions: true})
+		Expect(matches).To(HaveLen(1))
+		Expect(matches[0].Kind).To(Equal(proto.MatchDescription))
+		Expect(matches[0].Path).To(Equal([]string{"readyReplicas"}))
+		Expect(matches[0].Schema.GetName()).To(Equal("integer (int32)"))
+	})
+
+	It("should limit the matches", func() {
+		Expect(proto.Search(models, "replicas", proto.SearchOptions{Limit: 2})).To(HaveLen(2))
+		Expect(proto.Search(models, "", proto.SearchOptions{})).To(BeEmpty())
+	})
+})
+
+// fakeModels are models defined in place, by name.
+type fakeModels map[string]proto.Schema
+
+func (m fakeModels) LookupModel(name string) proto.Schema {
+	return m[name]
+}
+
+func (m fakeModels) ListModels() []string {
+	models := []string{}
+	for name := range m {
+		models = append(models, name)
+	}
+	sort.Strings(models)
+	return models
+}
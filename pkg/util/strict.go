@@ -0,0 +1,32 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"k8s.io/kube-openapi/pkg/internal/third_party/go-json-experiment/json"
+)
+
+// FromJSONStrict decodes the JSON document data into obj, which must be a
+// non-nil pointer. Unlike encoding/json, it fails on duplicate names in an
+// object, on object members that match no field of the destination struct,
+// and on invalid UTF-8. Member names are matched to fields case-sensitively.
+//
+// Types implementing json.Unmarshaler decode their own values, so unknown
+// members within them are only rejected if their UnmarshalJSON does so.
+func FromJSONStrict(data []byte, obj interface{}) error {
+	return json.UnmarshalOptions{RejectUnknownMembers: true}.Unmarshal(json.DecodeOptions{}, data, obj)
+}
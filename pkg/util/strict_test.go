@@ -0,0 +1,71 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type strictTestObject struct {
+	Name   string            `json:"name"`
+	Labels map[string]string `json:"labels,omitempty"`
+	Spec   struct {
+		Replicas int `json:"replicas"`
+	} `json:"spec"`
+}
+
+func TestFromJSONStrict(t *testing.T) {
+	var tests = []struct {
+		name     string
+		data     string
+		expected strictTestObject
+		err      string
+	}{
+		{
+			name: "valid",
+			data: `{"name": "foo", "labels": {"a": "b"}, "spec": {"replicas": 2}}`,
+			expected: strictTestObject{Name: "foo", Labels: map[string]string{"a": "b"}, Spec: struct {
+				Replicas int `json:"replicas"`
+			}{2}},
+		},
+		{name: "duplicate field", data: `{"name": "foo", "name": "bar"}`, err: `duplicate name "name"`},
+		{name: "duplicate map key", data: `{"labels": {"a": "b", "a": "c"}}`, err: `duplicate name "a"`},
+		{name: "unknown field", data: `{"name": "foo", "spec": {"replicas": 2, "paused": true}}`, err: `unknown name "paused"`},
+		{name: "case mismatch", data: `{"Name": "foo"}`, err: `unknown name "Name"`},
+		{name: "invalid UTF-8", data: "{\"name\": \"\xff\"}", err: "invalid UTF-8"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got strictTestObject
+			err := FromJSONStrict([]byte(tt.data), &got)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Errorf("expected error containing %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("expected %+v, got %+v", tt.expected, got)
+			}
+		})
+	}
+}
@@ -234,6 +234,10 @@ var SwaggerFuzzFuncs []interface{} = []interface{}{
 		c.FuzzNoCustom(*v)
 
 		(*v).Title = c.RandString() + "x"
+		if (*v).License != nil {
+			// only defined by OpenAPI 3.1
+			(*v).License.Identifier = ""
+		}
 	},
 	func(v *Extensions, c fuzz.Continue) {
 		// gnostic parser only picks up x- vendor extensions
@@ -486,11 +490,16 @@ var SwaggerFuzzFuncs []interface{} = []interface{}{
 		v.OneOf = nil
 		v.Not = nil
 		v.Nullable = false
+		v.Deprecated = false
+		v.WriteOnly = false
 		v.AdditionalItems = nil
 		v.Schema = ""
 		v.PatternProperties = nil
 		v.Definitions = nil
 		v.Dependencies = nil
+		v.PrefixItems = nil
+		v.UnevaluatedItems = nil
+		v.UnevaluatedProperties = nil
 	},
 }
 
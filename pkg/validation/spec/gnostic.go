@@ -458,6 +458,7 @@ func (k *License) FromGnostic(g *openapi_v2.License) (ok bool, err error) {
 
 	k.Name = g.Name
 	k.URL = g.Url
+	// License.Identifier - in openapi 3.1, not v2
 
 	// License does not embed to VendorExtensible!
 	// data loss from g.VendorExtension
@@ -1131,6 +1132,8 @@ func (k *Schema) FromGnostic(g *openapi_v2.Schema) (ok bool, err error) {
 	// Schema.ID is not available in official spec
 	// Schema.$schema
 	// Schema.Nullable - in openapiv3, not v2
+	// Schema.Deprecated - in openapiv3, not v2
+	// Schema.WriteOnly - in openapiv3, not v2
 	// Schema.AnyOf - in openapiv3, not v2
 	// Schema.OneOf - in openapiv3, not v2
 	// Schema.Not - in openapiv3, not v2
@@ -1138,6 +1141,7 @@ func (k *Schema) FromGnostic(g *openapi_v2.Schema) (ok bool, err error) {
 	// Schema.Dependencies - in openapiv3, not v2
 	// Schema.AdditionalItems
 	// Schema.Definitions - not part of spec
+	// Schema.PrefixItems, Schema.UnevaluatedItems, Schema.UnevaluatedProperties - JSON schema 2020-12 only
 	// Schema.ExtraProps - gnostic parser rejects any keys it does not recognize
 
 	if g.GetDefault() != nil {
@@ -0,0 +1,284 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec
+
+import (
+	"strconv"
+	"strings"
+
+	openapi_v2 "github.com/google/gnostic/openapiv2"
+)
+
+// Vendor extensions holding the gnostic data preserved by FromGnosticLossless.
+// Their values map the names of the dropped vendor extensions to their values.
+const (
+	// GnosticContactExtension holds the vendor extensions of the contact of
+	// an info.
+	GnosticContactExtension = "x-kubernetes-gnostic-contact"
+	// GnosticLicenseExtension holds the vendor extensions of the license of
+	// an info.
+	GnosticLicenseExtension = "x-kubernetes-gnostic-license"
+	// GnosticExternalDocsExtension holds the vendor extensions of the
+	// external documentation of a document, tag, operation or schema.
+	GnosticExternalDocsExtension = "x-kubernetes-gnostic-external-docs"
+)
+
+// ConversionLoss is a value of a gnostic document dropped by its conversion.
+type ConversionLoss struct {
+	// Path is the JSON pointer to the dropped value in the gnostic document,
+	// e.g. "/info/contact/x-team".
+	Path string
+	// Reason tells why the value was dropped.
+	Reason string
+}
+
+// ConversionReport lists the values of a gnostic document dropped by its
+// conversion, in document order.
+type ConversionReport struct {
+	Losses []ConversionLoss
+}
+
+// OK returns true if no value was dropped.
+func (r *ConversionReport) OK() bool {
+	return r == nil || len(r.Losses) == 0
+}
+
+func (r *ConversionReport) add(path, reason string) {
+	r.Losses = append(r.Losses, ConversionLoss{Path: path, Reason: reason})
+}
+
+// FromGnosticWithReport converts a gnostic v2 Document like FromGnostic, and
+// reports the values that were dropped. As with FromGnostic, zero
+// validations are lost without being reported, since gnostic does not tell
+// them apart from missing ones.
+func (k *Swagger) FromGnosticWithReport(g *openapi_v2.Document) (*ConversionReport, error) {
+	return k.fromGnosticWithLosses(g, false)
+}
+
+// FromGnosticLossless converts a gnostic v2 Document like FromGnostic, but
+// preserves the vendor extensions that kube-openapi types cannot hold under
+// x-kubernetes-gnostic-* vendor extensions of the nearest object that can:
+// the extensions of contacts and licenses are kept by their info, and the
+// ones of external documentation by their document, tag, operation or schema.
+//
+// ok is false if data was dropped anyway, i.e. responses whose status code
+// is not a number. As with FromGnostic, zero validations are always lost.
+func (k *Swagger) FromGnosticLossless(g *openapi_v2.Document) (ok bool, err error) {
+	report, err := k.fromGnosticWithLosses(g, true)
+	if err != nil {
+		return false, err
+	}
+	return report.OK(), nil
+}
+
+// fromGnosticWithLosses converts g, then visits the values dropped by the
+// conversion to report them or, if lossless, to preserve the ones that can
+// be.
+func (k *Swagger) fromGnosticWithLosses(g *openapi_v2.Document, lossless bool) (*ConversionReport, error) {
+	if _, err := k.FromGnostic(g); err != nil {
+		return nil, err
+	}
+	l := &gnosticLosses{lossless: lossless, report: &ConversionReport{}}
+	if g == nil {
+		return l.report, nil
+	}
+
+	if g.Info != nil && k.Info != nil {
+		l.extensions(&k.Info.VendorExtensible, GnosticContactExtension, "/info/contact", "contacts", g.Info.Contact.GetVendorExtension())
+		l.extensions(&k.Info.VendorExtensible, GnosticLicenseExtension, "/info/license", "licenses", g.Info.License.GetVendorExtension())
+	}
+	l.externalDocs(&k.VendorExtensible, "", g.ExternalDocs)
+	for i, t := range g.Tags {
+		if t != nil {
+			l.externalDocs(&k.Tags[i].VendorExtensible, "/tags/"+strconv.Itoa(i), t.ExternalDocs)
+		}
+	}
+	for _, named := range g.GetDefinitions().GetAdditionalProperties() {
+		if s, found := k.Definitions[named.GetName()]; found {
+			l.schema(&s, "/definitions/"+escapePointerToken(named.Name), named.Value)
+			k.Definitions[named.Name] = s
+		}
+	}
+	for _, named := range g.GetParameters().GetAdditionalProperties() {
+		if p, found := k.Parameters[named.GetName()]; found {
+			l.parameter(&p, "/parameters/"+escapePointerToken(named.Name), named.Value)
+			k.Parameters[named.Name] = p
+		}
+	}
+	for _, named := range g.GetResponses().GetAdditionalProperties() {
+		if r, found := k.Responses[named.GetName()]; found {
+			l.response(&r, "/responses/"+escapePointerToken(named.Name), named.Value)
+			k.Responses[named.Name] = r
+		}
+	}
+	if k.Paths != nil {
+		for _, named := range g.GetPaths().GetPath() {
+			if item, found := k.Paths.Paths[named.GetName()]; found {
+				l.pathItem(&item, "/paths/"+escapePointerToken(named.Name), named.Value)
+				k.Paths.Paths[named.Name] = item
+			}
+		}
+	}
+	if l.err != nil {
+		return nil, l.err
+	}
+	return l.report, nil
+}
+
+// gnosticLosses visits the values of a gnostic document dropped by
+// FromGnostic, reporting them or, if lossless, adding them to the converted
+// objects.
+type gnosticLosses struct {
+	lossless bool
+	report   *ConversionReport
+	err      error
+}
+
+// extensions handles the gnostic vendor extensions ext of the object at
+// path, whose kube-openapi type cannot hold them. If lossless, they are added
+// to v under key, otherwise they are reported.
+func (l *gnosticLosses) extensions(v *VendorExtensible, key, path, objects string, ext []*openapi_v2.NamedAny) {
+	if len(ext) == 0 || l.err != nil {
+		return
+	}
+	if !l.lossless {
+		for _, e := range ext {
+			l.report.add(path+"/"+escapePointerToken(e.GetName()), "vendor extensions of "+objects+" are not supported")
+		}
+		return
+	}
+	var stashed VendorExtensible
+	if err := stashed.FromGnostic(ext); err != nil {
+		l.err = err
+		return
+	}
+	v.AddExtension(key, map[string]interface{}(stashed.Extensions))
+}
+
+func (l *gnosticLosses) externalDocs(v *VendorExtensible, path string, g *openapi_v2.ExternalDocs) {
+	l.extensions(v, GnosticExternalDocsExtension, path+"/externalDocs", "external documentation", g.GetVendorExtension())
+}
+
+func (l *gnosticLosses) pathItem(k *PathItem, path string, g *openapi_v2.PathItem) {
+	for _, op := range []struct {
+		name string
+		k    *Operation
+		g    *openapi_v2.Operation
+	}{
+		{"get", k.Get, g.GetGet()},
+		{"put", k.Put, g.GetPut()},
+		{"post", k.Post, g.GetPost()},
+		{"delete", k.Delete, g.GetDelete()},
+		{"options", k.Options, g.GetOptions()},
+		{"head", k.Head, g.GetHead()},
+		{"patch", k.Patch, g.GetPatch()},
+	} {
+		if op.k != nil && op.g != nil {
+			l.operation(op.k, path+"/"+op.name, op.g)
+		}
+	}
+	l.parameters(k.Parameters, path, g.GetParameters())
+}
+
+func (l *gnosticLosses) operation(k *Operation, path string, g *openapi_v2.Operation) {
+	l.externalDocs(&k.VendorExtensible, path, g.ExternalDocs)
+	l.parameters(k.Parameters, path, g.Parameters)
+	if k.Responses == nil {
+		return
+	}
+	for _, named := range g.GetResponses().GetResponseCode() {
+		r := named.GetValue().GetResponse()
+		responsePath := path + "/responses/" + escapePointerToken(named.GetName())
+		if named.GetName() == "default" {
+			if r != nil && k.Responses.Default != nil {
+				l.response(k.Responses.Default, responsePath, r)
+			}
+			continue
+		}
+		code, err := strconv.Atoi(named.GetName())
+		if err != nil {
+			l.report.add(responsePath, "status code is not a number")
+			continue
+		}
+		if converted, found := k.Responses.StatusCodeResponses[code]; found && r != nil {
+			l.response(&converted, responsePath, r)
+			k.Responses.StatusCodeResponses[code] = converted
+		}
+	}
+}
+
+func (l *gnosticLosses) parameters(k []Parameter, path string, g []*openapi_v2.ParametersItem) {
+	for i, item := range g {
+		if p := item.GetParameter(); p != nil && i < len(k) {
+			l.parameter(&k[i], path+"/parameters/"+strconv.Itoa(i), p)
+		}
+	}
+}
+
+func (l *gnosticLosses) parameter(k *Parameter, path string, g *openapi_v2.Parameter) {
+	if body := g.GetBodyParameter(); body != nil && k.Schema != nil {
+		l.schema(k.Schema, path+"/schema", body.Schema)
+	}
+}
+
+func (l *gnosticLosses) response(k *Response, path string, g *openapi_v2.Response) {
+	if k.Schema == nil {
+		return
+	}
+	if s := g.GetSchema().GetSchema(); s != nil {
+		l.schema(k.Schema, path+"/schema", s)
+	} else if f := g.GetSchema().GetFileSchema(); f != nil {
+		l.externalDocs(&k.Schema.VendorExtensible, path+"/schema", f.ExternalDocs)
+	}
+}
+
+func (l *gnosticLosses) schema(k *Schema, path string, g *openapi_v2.Schema) {
+	if g == nil {
+		return
+	}
+	l.externalDocs(&k.VendorExtensible, path, g.ExternalDocs)
+	for _, named := range g.GetProperties().GetAdditionalProperties() {
+		if p, found := k.Properties[named.GetName()]; found {
+			l.schema(&p, path+"/properties/"+escapePointerToken(named.Name), named.Value)
+			k.Properties[named.Name] = p
+		}
+	}
+	if items := g.GetItems().GetSchema(); k.Items != nil {
+		if k.Items.Schema != nil && len(items) == 1 {
+			l.schema(k.Items.Schema, path+"/items", items[0])
+		} else if len(items) == len(k.Items.Schemas) {
+			for i := range items {
+				l.schema(&k.Items.Schemas[i], path+"/items/"+strconv.Itoa(i), items[i])
+			}
+		}
+	}
+	if len(g.AllOf) == len(k.AllOf) {
+		for i := range g.AllOf {
+			l.schema(&k.AllOf[i], path+"/allOf/"+strconv.Itoa(i), g.AllOf[i])
+		}
+	}
+	if s := g.GetAdditionalProperties().GetSchema(); s != nil && k.AdditionalProperties != nil && k.AdditionalProperties.Schema != nil {
+		l.schema(k.AdditionalProperties.Schema, path+"/additionalProperties", s)
+	}
+}
+
+var pointerTokenEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// escapePointerToken escapes a JSON pointer token, see RFC 6901.
+func escapePointerToken(token string) string {
+	return pointerTokenEscaper.Replace(token)
+}
@@ -21,6 +21,7 @@ import (
 	"io"
 	"os"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -596,3 +597,156 @@ func TestBadStatusCode(t *testing.T) {
 		t.Errorf("encoded json values for bad and fixed tests are not identical: %v", cmp.Diff(string(badConvertedJSON), string(droppedConvertedJSON)))
 	}
 }
+
+func TestGnosticLossless(t *testing.T) {
+	const doc = `{
+  "swagger": "2.0",
+  "info": {
+    "title": "test",
+    "version": "1.0",
+    "contact": {"name": "bill", "x-contact": "kept"},
+    "license": {"name": "MIT", "x-license": {"spdx": "MIT"}}
+  },
+  "externalDocs": {"url": "https://example.com", "x-docs": "document"},
+  "tags": [{"name": "pods", "externalDocs": {"url": "https://example.com", "x-docs": "tag"}}],
+  "paths": {
+    "/pods": {
+      "post": {
+        "externalDocs": {"url": "https://example.com", "x-docs": "operation"},
+        "parameters": [{"name": "body", "in": "body", "schema": {"type": "object", "externalDocs": {"url": "https://example.com", "x-docs": "body"}}}],
+        "responses": {"200": {"description": "OK", "schema": {"type": "array", "items": {"type": "string", "externalDocs": {"url": "https://example.com", "x-docs": "items"}}}}}
+      }
+    }
+  },
+  "definitions": {
+    "Pod": {
+      "type": "object",
+      "properties": {
+        "spec": {"type": "object", "externalDocs": {"url": "https://example.com", "x-docs": "property"}}
+      }
+    }
+  }
+}`
+	g, err := openapi_v2.ParseDocument([]byte(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lossy := Swagger{}
+	if ok, err := lossy.FromGnostic(g); err != nil {
+		t.Fatal(err)
+	} else if ok {
+		t.Fatalf("expected data loss without the lossless mode")
+	}
+
+	converted := Swagger{}
+	if ok, err := converted.FromGnosticLossless(g); err != nil {
+		t.Fatal(err)
+	} else if !ok {
+		t.Fatalf("expected no data loss in the lossless mode")
+	}
+	op := converted.Paths.Paths["/pods"].Post
+	for _, tc := range []struct {
+		name     string
+		ext      Extensions
+		key      string
+		expected map[string]interface{}
+	}{
+		{"contact", converted.Info.Extensions, GnosticContactExtension, map[string]interface{}{"x-contact": "kept"}},
+		{"license", converted.Info.Extensions, GnosticLicenseExtension, map[string]interface{}{"x-license": map[string]interface{}{"spdx": "MIT"}}},
+		{"document", converted.Extensions, GnosticExternalDocsExtension, map[string]interface{}{"x-docs": "document"}},
+		{"tag", converted.Tags[0].Extensions, GnosticExternalDocsExtension, map[string]interface{}{"x-docs": "tag"}},
+		{"operation", op.Extensions, GnosticExternalDocsExtension, map[string]interface{}{"x-docs": "operation"}},
+		{"body", op.Parameters[0].Schema.Extensions, GnosticExternalDocsExtension, map[string]interface{}{"x-docs": "body"}},
+		{"items", op.Responses.StatusCodeResponses[200].Schema.Items.Schema.Extensions, GnosticExternalDocsExtension, map[string]interface{}{"x-docs": "items"}},
+		{"property", converted.Definitions["Pod"].Properties["spec"].Extensions, GnosticExternalDocsExtension, map[string]interface{}{"x-docs": "property"}},
+	} {
+		if got := tc.ext[tc.key]; !reflect.DeepEqual(tc.expected, got) {
+			t.Errorf("%s: expected %s to be %v, got %v", tc.name, tc.key, tc.expected, got)
+		}
+	}
+
+	// only the lossless conversion carries the preserved extensions
+	lossyJSON, err := json.Marshal(lossy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	convertedJSON, err := json.Marshal(converted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(convertedJSON), GnosticExternalDocsExtension) || strings.Contains(string(lossyJSON), GnosticExternalDocsExtension) {
+		t.Errorf("expected only the lossless conversion to have %s", GnosticExternalDocsExtension)
+	}
+
+	g.Paths.Path[0].Value.Post.Responses.ResponseCode[0].Name = "bad"
+	if ok, err := (&Swagger{}).FromGnosticLossless(g); err != nil {
+		t.Fatal(err)
+	} else if ok {
+		t.Errorf("expected data loss converting a response code 'bad'")
+	}
+}
+
+func TestGnosticConversionReport(t *testing.T) {
+	const doc = `{
+  "swagger": "2.0",
+  "info": {
+    "title": "test",
+    "version": "1.0",
+    "contact": {"name": "bill", "x-contact": "dropped"}
+  },
+  "paths": {
+    "/pods/{name}": {
+      "get": {
+        "responses": {
+          "299": {"description": "dropped"},
+          "200": {"description": "OK", "schema": {"type": "object", "externalDocs": {"url": "https://example.com", "x-docs": "dropped"}}}
+        }
+      }
+    }
+  },
+  "definitions": {
+    "a/b": {"type": "object", "externalDocs": {"url": "https://example.com", "x-docs~": "dropped"}}
+  }
+}`
+	g, err := openapi_v2.ParseDocument([]byte(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// gnostic only parses numeric status codes, but allows building others.
+	g.Paths.Path[0].Value.Get.Responses.ResponseCode[0].Name = "2XX"
+
+	converted := Swagger{}
+	report, err := converted.FromGnosticWithReport(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []ConversionLoss{
+		{Path: "/info/contact/x-contact", Reason: "vendor extensions of contacts are not supported"},
+		{Path: "/definitions/a~1b/externalDocs/x-docs~0", Reason: "vendor extensions of external documentation are not supported"},
+		{Path: "/paths/~1pods~1{name}/get/responses/2XX", Reason: "status code is not a number"},
+		{Path: "/paths/~1pods~1{name}/get/responses/200/schema/externalDocs/x-docs", Reason: "vendor extensions of external documentation are not supported"},
+	}
+	if !reflect.DeepEqual(report.Losses, expected) {
+		t.Errorf("expected losses %v, got %v", expected, report.Losses)
+	}
+	if report.OK() {
+		t.Errorf("expected the report not to be OK")
+	}
+
+	lossy := Swagger{}
+	if ok, err := lossy.FromGnostic(g); err != nil {
+		t.Fatal(err)
+	} else if ok != report.OK() {
+		t.Errorf("expected FromGnostic to return ok %t, got %t", report.OK(), ok)
+	}
+	if !reflect.DeepEqual(lossy, converted) {
+		t.Errorf("expected the reported conversion to equal the one of FromGnostic")
+	}
+
+	if ok, err := (&Swagger{}).FromGnosticLossless(g); err != nil {
+		t.Fatal(err)
+	} else if ok {
+		t.Errorf("expected non-numeric status codes to be lost in the lossless mode")
+	}
+}
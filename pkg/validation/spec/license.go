@@ -20,4 +20,7 @@ package spec
 type License struct {
 	Name string `json:"name,omitempty"`
 	URL  string `json:"url,omitempty"`
+	// Identifier is the SPDX license expression of the API, only defined by
+	// OpenAPI 3.1.
+	Identifier string `json:"identifier,omitempty"`
 }
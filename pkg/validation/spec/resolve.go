@@ -0,0 +1,254 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ResolveRef resolves a local ref, such as "#/definitions/io.k8s.api.core.v1.Pod"
+// or "#/definitions/io.k8s.api.core.v1.Pod/properties/spec", to the schema it
+// points to in doc.
+func ResolveRef(doc *Swagger, ref *Ref) (*Schema, error) {
+	return (&RefCache{}).ResolveRef(doc, ref)
+}
+
+// ResolveParameter resolves a local ref, such as "#/parameters/body", to the
+// parameter it points to in doc.
+func ResolveParameter(doc *Swagger, ref *Ref) (*Parameter, error) {
+	return (&RefCache{}).ResolveParameter(doc, ref)
+}
+
+// ResolveResponse resolves a local ref, such as "#/responses/notFound", to
+// the response it points to in doc.
+func ResolveResponse(doc *Swagger, ref *Ref) (*Response, error) {
+	return (&RefCache{}).ResolveResponse(doc, ref)
+}
+
+// RefCache caches the resolution of the local refs of a document. It is
+// safe for concurrent use, and the zero value is ready to use.
+//
+// The cache is bound to the document it last resolved refs against, and
+// starts over when given another one. Documents changed through the
+// schemamutation walker, which copies what it modifies, are therefore
+// picked up automatically; a document modified in place requires a call
+// to Invalidate.
+//
+// Resolved objects are shared between callers and must not be modified.
+type RefCache struct {
+	mu      sync.RWMutex
+	doc     *Swagger
+	targets map[string]interface{}
+}
+
+// ResolveRef is like the ResolveRef function, with caching.
+func (c *RefCache) ResolveRef(doc *Swagger, ref *Ref) (*Schema, error) {
+	target, err := c.resolve(doc, ref)
+	if err != nil {
+		return nil, err
+	}
+	schema, ok := target.(*Schema)
+	if !ok {
+		return nil, fmt.Errorf("ref %q does not point to a schema", ref.String())
+	}
+	return schema, nil
+}
+
+// ResolveParameter is like the ResolveParameter function, with caching.
+func (c *RefCache) ResolveParameter(doc *Swagger, ref *Ref) (*Parameter, error) {
+	target, err := c.resolve(doc, ref)
+	if err != nil {
+		return nil, err
+	}
+	param, ok := target.(*Parameter)
+	if !ok {
+		return nil, fmt.Errorf("ref %q does not point to a parameter", ref.String())
+	}
+	return param, nil
+}
+
+// ResolveResponse is like the ResolveResponse function, with caching.
+func (c *RefCache) ResolveResponse(doc *Swagger, ref *Ref) (*Response, error) {
+	target, err := c.resolve(doc, ref)
+	if err != nil {
+		return nil, err
+	}
+	resp, ok := target.(*Response)
+	if !ok {
+		return nil, fmt.Errorf("ref %q does not point to a response", ref.String())
+	}
+	return resp, nil
+}
+
+// Invalidate drops the cached resolutions.
+func (c *RefCache) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.doc = nil
+	c.targets = nil
+}
+
+func (c *RefCache) resolve(doc *Swagger, ref *Ref) (interface{}, error) {
+	if doc == nil {
+		return nil, fmt.Errorf("no document to resolve refs against")
+	}
+	if ref == nil || ref.String() == "" {
+		return nil, fmt.Errorf("empty ref")
+	}
+	if ref.HasFullURL || ref.HasURLPathOnly || ref.HasFileScheme || ref.HasFullFilePath {
+		return nil, fmt.Errorf("ref %q is not local to the document", ref.String())
+	}
+	pointer := ref.GetPointer().String()
+
+	c.mu.RLock()
+	target, ok := c.targets[pointer]
+	ok = ok && c.doc == doc
+	c.mu.RUnlock()
+	if ok {
+		return target, nil
+	}
+
+	target, err := lookup(doc, ref.GetPointer().DecodedTokens())
+	if err != nil {
+		return nil, fmt.Errorf("cannot resolve ref %q: %v", ref.String(), err)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.doc != doc || c.targets == nil {
+		c.doc = doc
+		c.targets = map[string]interface{}{}
+	}
+	if existing, ok := c.targets[pointer]; ok {
+		return existing, nil
+	}
+	c.targets[pointer] = target
+	return target, nil
+}
+
+// lookup walks the tokens of a JSON pointer from the root of s.
+func lookup(s *Swagger, tokens []string) (interface{}, error) {
+	if len(tokens) < 2 {
+		return nil, fmt.Errorf("unsupported pointer /%s", strings.Join(tokens, "/"))
+	}
+	switch tokens[0] {
+	case "definitions":
+		schema, ok := s.Definitions[tokens[1]]
+		if !ok {
+			return nil, fmt.Errorf("definition %q not found", tokens[1])
+		}
+		return lookupSchema(&schema, tokens[2:])
+	case "parameters":
+		param, ok := s.Parameters[tokens[1]]
+		if !ok {
+			return nil, fmt.Errorf("parameter %q not found", tokens[1])
+		}
+		if len(tokens) == 2 {
+			return &param, nil
+		}
+		if tokens[2] == "schema" && param.Schema != nil {
+			return lookupSchema(param.Schema, tokens[3:])
+		}
+	case "responses":
+		resp, ok := s.Responses[tokens[1]]
+		if !ok {
+			return nil, fmt.Errorf("response %q not found", tokens[1])
+		}
+		if len(tokens) == 2 {
+			return &resp, nil
+		}
+		if tokens[2] == "schema" && resp.Schema != nil {
+			return lookupSchema(resp.Schema, tokens[3:])
+		}
+	}
+	return nil, fmt.Errorf("unsupported pointer /%s", strings.Join(tokens, "/"))
+}
+
+// lookupSchema walks the tokens of a JSON pointer from schema.
+func lookupSchema(schema *Schema, tokens []string) (*Schema, error) {
+	for len(tokens) > 0 {
+		var next *Schema
+		switch tokens[0] {
+		case "properties", "patternProperties", "definitions":
+			if len(tokens) < 2 {
+				return nil, fmt.Errorf("missing name after %q", tokens[0])
+			}
+			var m map[string]Schema
+			switch tokens[0] {
+			case "properties":
+				m = schema.Properties
+			case "patternProperties":
+				m = schema.PatternProperties
+			default:
+				m = schema.Definitions
+			}
+			if v, ok := m[tokens[1]]; ok {
+				next = &v
+			}
+			tokens = tokens[1:]
+		case "allOf", "anyOf", "oneOf":
+			if len(tokens) < 2 {
+				return nil, fmt.Errorf("missing index after %q", tokens[0])
+			}
+			var l []Schema
+			switch tokens[0] {
+			case "allOf":
+				l = schema.AllOf
+			case "anyOf":
+				l = schema.AnyOf
+			default:
+				l = schema.OneOf
+			}
+			if i, err := strconv.Atoi(tokens[1]); err == nil && i >= 0 && i < len(l) {
+				next = &l[i]
+			}
+			tokens = tokens[1:]
+		case "items":
+			if schema.Items == nil {
+				break
+			}
+			if schema.Items.Schema != nil {
+				next = schema.Items.Schema
+			} else if len(tokens) > 1 {
+				if i, err := strconv.Atoi(tokens[1]); err == nil && i >= 0 && i < len(schema.Items.Schemas) {
+					next = &schema.Items.Schemas[i]
+				}
+				tokens = tokens[1:]
+			}
+		case "additionalProperties":
+			if schema.AdditionalProperties != nil {
+				next = schema.AdditionalProperties.Schema
+			}
+		case "additionalItems":
+			if schema.AdditionalItems != nil {
+				next = schema.AdditionalItems.Schema
+			}
+		case "not":
+			next = schema.Not
+		default:
+			return nil, fmt.Errorf("unsupported schema keyword %q", tokens[0])
+		}
+		if next == nil {
+			return nil, fmt.Errorf("%q not found", tokens[0])
+		}
+		schema = next
+		tokens = tokens[1:]
+	}
+	return schema, nil
+}
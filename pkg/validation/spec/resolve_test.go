@@ -0,0 +1,207 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func resolveTestDocument() *Swagger {
+	return &Swagger{SwaggerProps: SwaggerProps{
+		Definitions: Definitions{
+			"io.k8s.Pod": {SchemaProps: SchemaProps{
+				Type: []string{"object"},
+				Properties: map[string]Schema{
+					"spec": {SchemaProps: SchemaProps{Ref: MustCreateRef("#/definitions/io.k8s.PodSpec")}},
+					"a/b":  {SchemaProps: SchemaProps{Type: []string{"string"}}},
+					"list": {SchemaProps: SchemaProps{
+						Type:  []string{"array"},
+						Items: &SchemaOrArray{Schema: &Schema{SchemaProps: SchemaProps{Type: []string{"integer"}}}},
+					}},
+					"union": {SchemaProps: SchemaProps{
+						OneOf: []Schema{{SchemaProps: SchemaProps{Type: []string{"string"}}}, {SchemaProps: SchemaProps{Type: []string{"number"}}}},
+					}},
+				},
+				AdditionalProperties: &SchemaOrBool{Allows: true, Schema: &Schema{SchemaProps: SchemaProps{Type: []string{"boolean"}}}},
+			}},
+			"io.k8s.PodSpec": {SchemaProps: SchemaProps{Type: []string{"object"}}},
+		},
+		Parameters: map[string]Parameter{
+			"body": {ParamProps: ParamProps{Name: "body", In: "body", Schema: &Schema{SchemaProps: SchemaProps{Type: []string{"object"}}}}},
+		},
+		Responses: map[string]Response{
+			"notFound": {ResponseProps: ResponseProps{Description: "not found"}},
+		},
+	}}
+}
+
+func TestResolveRef(t *testing.T) {
+	doc := resolveTestDocument()
+	var tests = []struct {
+		ref      string
+		expected string
+		err      string
+	}{
+		{ref: "#/definitions/io.k8s.PodSpec", expected: "object"},
+		{ref: "#/definitions/io.k8s.Pod/properties/a~1b", expected: "string"},
+		{ref: "#/definitions/io.k8s.Pod/properties/list/items", expected: "integer"},
+		{ref: "#/definitions/io.k8s.Pod/properties/union/oneOf/1", expected: "number"},
+		{ref: "#/definitions/io.k8s.Pod/additionalProperties", expected: "boolean"},
+		{ref: "#/parameters/body/schema", expected: "object"},
+		{ref: "#/definitions/missing", err: `definition "missing" not found`},
+		{ref: "#/definitions/io.k8s.Pod/properties/missing", err: `"missing" not found`},
+		{ref: "#/definitions/io.k8s.Pod/properties/union/oneOf/2", err: `"2" not found`},
+		{ref: "#/definitions/io.k8s.Pod/enum", err: `unsupported schema keyword "enum"`},
+		{ref: "#/parameters/body", err: "does not point to a schema"},
+		{ref: "#/info", err: "unsupported pointer /info"},
+		{ref: "other.json#/definitions/io.k8s.PodSpec", err: "not local"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			ref := MustCreateRef(tt.ref)
+			s, err := ResolveRef(doc, &ref)
+			if tt.err != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, []string{tt.expected}, []string(s.Type))
+		})
+	}
+}
+
+func TestResolveParameterAndResponse(t *testing.T) {
+	doc := resolveTestDocument()
+
+	ref := MustCreateRef("#/parameters/body")
+	p, err := ResolveParameter(doc, &ref)
+	require.NoError(t, err)
+	assert.Equal(t, "body", p.Name)
+
+	ref = MustCreateRef("#/responses/notFound")
+	r, err := ResolveResponse(doc, &ref)
+	require.NoError(t, err)
+	assert.Equal(t, "not found", r.Description)
+
+	_, err = ResolveParameter(doc, &ref)
+	assert.Error(t, err)
+}
+
+func TestRefCache(t *testing.T) {
+	doc := resolveTestDocument()
+	ref := MustCreateRef("#/definitions/io.k8s.PodSpec")
+
+	c := &RefCache{}
+	first, err := c.ResolveRef(doc, &ref)
+	require.NoError(t, err)
+	second, err := c.ResolveRef(doc, &ref)
+	require.NoError(t, err)
+	assert.Same(t, first, second, "resolution should be cached")
+
+	// in place modification
+	doc.Definitions["io.k8s.PodSpec"] = Schema{SchemaProps: SchemaProps{Type: []string{"string"}}}
+	cached, err := c.ResolveRef(doc, &ref)
+	require.NoError(t, err)
+	assert.Same(t, first, cached)
+	c.Invalidate()
+	s, err := c.ResolveRef(doc, &ref)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"string"}, []string(s.Type))
+
+	// copy on write, as done by the schemamutation walker
+	modified := *doc
+	modified.Definitions = Definitions{"io.k8s.PodSpec": {SchemaProps: SchemaProps{Type: []string{"integer"}}}}
+	s, err = c.ResolveRef(&modified, &ref)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"integer"}, []string(s.Type))
+}
+
+func TestRefCacheConcurrent(t *testing.T) {
+	doc := resolveTestDocument()
+	c := &RefCache{}
+	refs := []string{
+		"#/definitions/io.k8s.PodSpec",
+		"#/definitions/io.k8s.Pod/properties/list/items",
+		"#/definitions/io.k8s.Pod/properties/union/oneOf/0",
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if i%7 == 0 {
+				c.Invalidate()
+			}
+			ref := MustCreateRef(refs[i%len(refs)])
+			if _, err := c.ResolveRef(doc, &ref); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func benchmarkResolveRefDocument(b *testing.B) (*Swagger, []Ref) {
+	data, err := os.ReadFile("../../schemaconv/testdata/swagger.json")
+	if err != nil {
+		b.Fatal(err)
+	}
+	doc := &Swagger{}
+	if err := json.Unmarshal(data, doc); err != nil {
+		b.Fatal(err)
+	}
+	var refs []Ref
+	for name, def := range doc.Definitions {
+		refs = append(refs, MustCreateRef("#/definitions/"+name))
+		for prop := range def.Properties {
+			refs = append(refs, MustCreateRef("#/definitions/"+name+"/properties/"+prop))
+		}
+	}
+	return doc, refs
+}
+
+func BenchmarkResolveRef(b *testing.B) {
+	doc, refs := benchmarkResolveRefDocument(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := ResolveRef(doc, &refs[i%len(refs)]); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkRefCache(b *testing.B) {
+	doc, refs := benchmarkResolveRefDocument(b)
+	c := &RefCache{}
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			if _, err := c.ResolveRef(doc, &refs[i%len(refs)]); err != nil {
+				b.Fatal(err)
+			}
+			i++
+		}
+	})
+}
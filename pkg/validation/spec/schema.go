@@ -165,6 +165,7 @@ type SchemaProps struct {
 	Description          string            `json:"description,omitempty"`
 	Type                 StringOrArray     `json:"type,omitempty"`
 	Nullable             bool              `json:"nullable,omitempty"`
+	Deprecated           bool              `json:"deprecated,omitempty"`
 	Format               string            `json:"format,omitempty"`
 	Title                string            `json:"title,omitempty"`
 	Default              interface{}       `json:"default,omitempty"`
@@ -194,14 +195,24 @@ type SchemaProps struct {
 	Dependencies         Dependencies      `json:"dependencies,omitempty"`
 	AdditionalItems      *SchemaOrBool     `json:"additionalItems,omitempty"`
 	Definitions          Definitions       `json:"definitions,omitempty"`
+
+	// PrefixItems, UnevaluatedItems and UnevaluatedProperties are only
+	// defined by JSON schema 2020-12, as used by OpenAPI 3.1. They are
+	// v3-only and are stripped from served v2 specs.
+	PrefixItems           []Schema      `json:"prefixItems,omitempty"`
+	UnevaluatedItems      *SchemaOrBool `json:"unevaluatedItems,omitempty"`
+	UnevaluatedProperties *SchemaOrBool `json:"unevaluatedProperties,omitempty"`
 }
 
 // SwaggerSchemaProps are additional properties supported by swagger schemas, but not JSON-schema (draft 4)
 type SwaggerSchemaProps struct {
-	Discriminator string                 `json:"discriminator,omitempty"`
-	ReadOnly      bool                   `json:"readOnly,omitempty"`
-	ExternalDocs  *ExternalDocumentation `json:"externalDocs,omitempty"`
-	Example       interface{}            `json:"example,omitempty"`
+	Discriminator string `json:"discriminator,omitempty"`
+	ReadOnly      bool   `json:"readOnly,omitempty"`
+	// WriteOnly is only defined by OpenAPI v3. It is stripped from served
+	// v2 specs.
+	WriteOnly    bool                   `json:"writeOnly,omitempty"`
+	ExternalDocs *ExternalDocumentation `json:"externalDocs,omitempty"`
+	Example      interface{}            `json:"example,omitempty"`
 }
 
 // Schema the schema object allows the definition of input and output data types.
@@ -408,6 +419,12 @@ func (s *Schema) AsWritable() *Schema {
 	return s
 }
 
+// AsWriteOnly flags this schema as write-only
+func (s *Schema) AsWriteOnly() *Schema {
+	s.WriteOnly = true
+	return s
+}
+
 // WithExample sets the example for this schema
 func (s *Schema) WithExample(example interface{}) *Schema {
 	s.Example = example
@@ -474,10 +491,14 @@ func (s *Schema) UnmarshalJSON(data []byte) error {
 	props := struct {
 		SchemaProps
 		SwaggerSchemaProps
+		ExclusiveMaximum exclusiveBound `json:"exclusiveMaximum,omitempty"`
+		ExclusiveMinimum exclusiveBound `json:"exclusiveMinimum,omitempty"`
 	}{}
 	if err := json.Unmarshal(data, &props); err != nil {
 		return err
 	}
+	props.ExclusiveMaximum.apply(&props.Maximum, &props.SchemaProps.ExclusiveMaximum, false)
+	props.ExclusiveMinimum.apply(&props.Minimum, &props.SchemaProps.ExclusiveMinimum, true)
 
 	sch := Schema{
 		SchemaProps:        props.SchemaProps,
@@ -523,10 +544,14 @@ func (s *Schema) UnmarshalNextJSON(opts jsonv2.UnmarshalOptions, dec *jsonv2.Dec
 		Extensions
 		SchemaProps
 		SwaggerSchemaProps
+		ExclusiveMaximum exclusiveBound `json:"exclusiveMaximum,omitempty"`
+		ExclusiveMinimum exclusiveBound `json:"exclusiveMinimum,omitempty"`
 	}
 	if err := opts.UnmarshalNext(dec, &x); err != nil {
 		return err
 	}
+	x.ExclusiveMaximum.apply(&x.Maximum, &x.SchemaProps.ExclusiveMaximum, false)
+	x.ExclusiveMinimum.apply(&x.Minimum, &x.SchemaProps.ExclusiveMinimum, true)
 
 	if err := x.Ref.fromMap(x.Extensions); err != nil {
 		return err
@@ -552,3 +577,35 @@ func (s *Schema) UnmarshalNextJSON(opts jsonv2.UnmarshalOptions, dec *jsonv2.Dec
 	s.SwaggerSchemaProps = x.SwaggerSchemaProps
 	return nil
 }
+
+// exclusiveBound reads exclusiveMaximum or exclusiveMinimum, either as the
+// boolean of JSON schema draft 4 or as the number of JSON schema 2020-12, as
+// used by OpenAPI 3.1.
+type exclusiveBound struct {
+	exclusive bool
+	value     *float64
+}
+
+func (b *exclusiveBound) UnmarshalJSON(data []byte) error {
+	*b = exclusiveBound{}
+	if err := json.Unmarshal(data, &b.exclusive); err == nil {
+		return nil
+	}
+	return json.Unmarshal(data, &b.value)
+}
+
+// apply sets bound and exclusive from b. A number becomes an exclusive bound,
+// unless bound is an inclusive one which is stricter, so that the schema
+// accepts the same values.
+func (b exclusiveBound) apply(bound **float64, exclusive *bool, minimum bool) {
+	if b.value == nil {
+		*exclusive = b.exclusive
+		return
+	}
+	if *bound != nil && (minimum && **bound > *b.value || !minimum && **bound < *b.value) {
+		*exclusive = false
+		return
+	}
+	*bound = b.value
+	*exclusive = true
+}
@@ -18,8 +18,10 @@ import (
 	"encoding/json"
 	"testing"
 
+	"github.com/go-openapi/swag"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"k8s.io/kube-openapi/pkg/internal"
 	jsontesting "k8s.io/kube-openapi/pkg/util/jsontesting"
 )
 
@@ -234,6 +236,48 @@ func TestSchemaRoundtrip(t *testing.T) {
 	}
 }
 
+func TestSchemaNumericExclusiveBounds(t *testing.T) {
+	cases := []struct {
+		name     string
+		json     string
+		expected SchemaProps
+	}{
+		{
+			name:     "boolean",
+			json:     `{"minimum": 1, "exclusiveMinimum": true, "maximum": 5, "exclusiveMaximum": false}`,
+			expected: SchemaProps{Minimum: swag.Float64(1), ExclusiveMinimum: true, Maximum: swag.Float64(5)},
+		},
+		{
+			name:     "numeric",
+			json:     `{"exclusiveMinimum": 1, "exclusiveMaximum": 5}`,
+			expected: SchemaProps{Minimum: swag.Float64(1), ExclusiveMinimum: true, Maximum: swag.Float64(5), ExclusiveMaximum: true},
+		},
+		{
+			name:     "numeric stricter than inclusive",
+			json:     `{"minimum": 1, "exclusiveMinimum": 2, "maximum": 5, "exclusiveMaximum": 4}`,
+			expected: SchemaProps{Minimum: swag.Float64(2), ExclusiveMinimum: true, Maximum: swag.Float64(4), ExclusiveMaximum: true},
+		},
+		{
+			name:     "inclusive stricter than numeric",
+			json:     `{"minimum": 3, "exclusiveMinimum": 2, "maximum": 3, "exclusiveMaximum": 4}`,
+			expected: SchemaProps{Minimum: swag.Float64(3), Maximum: swag.Float64(3)},
+		},
+	}
+
+	for _, optimized := range []bool{false, true} {
+		for _, tcase := range cases {
+			t.Run(tcase.name, func(t *testing.T) {
+				internal.UseOptimizedJSONUnmarshaling = optimized
+				defer func() { internal.UseOptimizedJSONUnmarshaling = false }()
+
+				var actual Schema
+				require.NoError(t, json.Unmarshal([]byte(tcase.json), &actual))
+				assert.Equal(t, Schema{SchemaProps: tcase.expected}, actual)
+			})
+		}
+	}
+}
+
 func BenchmarkSchemaUnmarshal(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		sch := &Schema{}
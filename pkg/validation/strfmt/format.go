@@ -92,6 +92,52 @@ func NewSeededFormats(seeds []knownFormat, normalizer NameNormalizer) Registry {
 	}
 }
 
+// Clone returns an independent copy of a registry created by this package.
+// Formats added to or removed from the copy do not affect the original, which
+// makes it suitable for tests and embedded servers that need to register
+// formats without mutating Default. It returns an error for registries
+// implemented elsewhere, whose formats cannot be listed.
+func Clone(r Registry) (Registry, error) {
+	f, ok := r.(*defaultFormats)
+	if !ok {
+		return nil, fmt.Errorf("cannot clone format registry of type %T", r)
+	}
+	return NewSeededFormats(f.snapshot(), f.normalizeName), nil
+}
+
+// Snapshot records the formats currently registered in Default and returns a
+// function that restores Default to that state. Tests registering custom
+// formats can use it to avoid leaking them into other tests:
+//
+//	defer strfmt.Snapshot()()
+//
+// If Default was replaced by a registry implemented elsewhere, restoring only
+// puts that registry back in Default.
+func Snapshot() (restore func()) {
+	r := Default
+	f, ok := r.(*defaultFormats)
+	if !ok {
+		return func() {
+			Default = r
+		}
+	}
+	data := f.snapshot()
+	return func() {
+		f.Lock()
+		defer f.Unlock()
+		// copy, so that restoring again after further changes still
+		// restores the recorded formats
+		f.data = append([]knownFormat(nil), data...)
+		Default = f
+	}
+}
+
+func (f *defaultFormats) snapshot() []knownFormat {
+	f.Lock()
+	defer f.Unlock()
+	return append([]knownFormat(nil), f.data...)
+}
+
 // MapStructureHookFunc is a decode hook function for mapstructure
 func (f *defaultFormats) MapStructureHookFunc() mapstructure.DecodeHookFunc {
 	return func(from reflect.Type, to reflect.Type, data interface{}) (interface{}, error) {
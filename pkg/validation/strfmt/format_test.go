@@ -21,6 +21,7 @@ import (
 
 	"github.com/mitchellh/mapstructure"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type testFormat string
@@ -127,6 +128,62 @@ func TestFormatRegistry(t *testing.T) {
 	assert.False(t, registry.Validates("unknown", ""))
 }
 
+func TestCloneRegistry(t *testing.T) {
+	f2 := tf2("")
+	registry := NewFormats()
+	clone, err := Clone(registry)
+	require.NoError(t, err)
+
+	assert.True(t, clone.ContainsName("testformat"))
+	assert.True(t, clone.Add("tf2", &f2, istf2))
+	assert.True(t, clone.ContainsName("tf2"))
+	assert.False(t, registry.ContainsName("tf2"))
+
+	assert.True(t, registry.DelByName("testformat"))
+	assert.True(t, clone.ContainsName("testformat"))
+}
+
+func TestSnapshotDefault(t *testing.T) {
+	f2 := tf2("")
+	restore := Snapshot()
+
+	assert.True(t, Default.Add("snapshot-format", &f2, istf2))
+	assert.True(t, Default.ContainsName("snapshot-format"))
+	assert.True(t, Default.DelByName("testformat"))
+
+	restore()
+	assert.False(t, Default.ContainsName("snapshot-format"))
+	assert.True(t, Default.ContainsName("testformat"))
+
+	// restoring twice restores the recorded formats again
+	assert.True(t, Default.Add("snapshot-format", &f2, istf2))
+	restore()
+	assert.False(t, Default.ContainsName("snapshot-format"))
+	assert.True(t, Default.ContainsName("testformat"))
+}
+
+// foreignRegistry is a Registry implemented outside of this package.
+type foreignRegistry struct {
+	Registry
+}
+
+func TestCloneForeignRegistry(t *testing.T) {
+	_, err := Clone(foreignRegistry{NewFormats()})
+	assert.EqualError(t, err, "cannot clone format registry of type strfmt.foreignRegistry")
+}
+
+func TestSnapshotForeignDefault(t *testing.T) {
+	original := Default
+	defer func() { Default = original }()
+
+	foreign, other := foreignRegistry{NewFormats()}, NewFormats()
+	Default = foreign
+	restore := Snapshot()
+	Default = other
+	restore()
+	assert.Equal(t, Registry(foreign), Default)
+}
+
 type testStruct struct {
 	D          Date       `json:"d,omitempty"`
 	DT         DateTime   `json:"dt,omitempty"`
@@ -0,0 +1,231 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validate
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	"k8s.io/kube-openapi/pkg/validation/strfmt"
+)
+
+// serializedSchemaVersion is the version of the form serialized by
+// MarshalSchemaJSON, bumped whenever it changes incompatibly.
+const serializedSchemaVersion = 1
+
+// CompiledSchema is a schema prepared for validation. Compiling checks the
+// schema once, and validating against a compiled schema reuses the
+// validators of its subschemas across calls instead of building them for
+// every value. It is safe for concurrent use.
+type CompiledSchema struct {
+	schema  *spec.Schema
+	formats strfmt.Registry
+	options []Option
+
+	// validators holds idle *SchemaValidator trees. A tree caches the
+	// validators of its subschemas and is only used by one call at a time.
+	validators sync.Pool
+}
+
+// Compile checks that schema can be validated against and prepares it for
+// validation. Unlike NewSchemaValidator, which panics on references and
+// reports invalid patterns while validating, Compile returns an error for
+// them.
+//
+// The schema must not be modified afterwards.
+func Compile(schema *spec.Schema, formats strfmt.Registry, options ...Option) (*CompiledSchema, error) {
+	if schema == nil {
+		return nil, fmt.Errorf("no schema to compile")
+	}
+	if err := checkCompilable(schema, ""); err != nil {
+		return nil, err
+	}
+	return newCompiledSchema(schema, formats, options), nil
+}
+
+func newCompiledSchema(schema *spec.Schema, formats strfmt.Registry, options []Option) *CompiledSchema {
+	c := &CompiledSchema{
+		schema:  schema,
+		formats: formats,
+		options: append(append([]Option(nil), options...), withValidatorReuse()),
+	}
+	c.validators.New = func() interface{} {
+		return NewSchemaValidator(c.schema, nil, "", c.formats, c.options...)
+	}
+	return c
+}
+
+// Schema returns the compiled schema.
+func (c *CompiledSchema) Schema() *spec.Schema {
+	return c.schema
+}
+
+// Validate validates data against the compiled schema.
+func (c *CompiledSchema) Validate(data interface{}) *Result {
+	v := c.validators.Get().(*SchemaValidator)
+	defer c.validators.Put(v)
+	return v.Validate(data)
+}
+
+type serializedSchema struct {
+	Version int          `json:"version"`
+	Schema  *spec.Schema `json:"schema"`
+}
+
+// MarshalSchemaJSON serializes the schema of c, so that it can be compiled
+// again with CompileSchemaJSON, e.g. after a restart. Only the schema is
+// serialized: the validators of a compiled schema are not, and neither are
+// formats and options.
+func (c *CompiledSchema) MarshalSchemaJSON() ([]byte, error) {
+	return json.Marshal(serializedSchema{Version: serializedSchemaVersion, Schema: c.schema})
+}
+
+// CompileSchemaJSON compiles a schema serialized by MarshalSchemaJSON like
+// Compile, validating with the given formats and options.
+func CompileSchemaJSON(data []byte, formats strfmt.Registry, options ...Option) (*CompiledSchema, error) {
+	var serialized serializedSchema
+	if err := json.Unmarshal(data, &serialized); err != nil {
+		return nil, fmt.Errorf("invalid serialized schema: %v", err)
+	}
+	if serialized.Version != serializedSchemaVersion {
+		return nil, fmt.Errorf("unsupported serialized schema version %d, expected %d", serialized.Version, serializedSchemaVersion)
+	}
+	return Compile(serialized.Schema, formats, options...)
+}
+
+// checkCompilable checks that schema and its subschemas have no references
+// and only valid patterns. The patterns are cached for validation.
+func checkCompilable(schema *spec.Schema, path string) error {
+	if ref := schema.Ref.String(); ref != "" {
+		return fmt.Errorf("%s: schema references not supported: %s", displayPath(path), ref)
+	}
+	if schema.Pattern != "" {
+		if _, err := compileRegexp(schema.Pattern); err != nil {
+			return fmt.Errorf("%s: invalid pattern %q: %v", displayPath(path), schema.Pattern, err)
+		}
+	}
+	for pattern := range schema.PatternProperties {
+		if _, err := compileRegexp(pattern); err != nil {
+			return fmt.Errorf("%s: invalid pattern property %q: %v", displayPath(path), pattern, err)
+		}
+	}
+
+	var err error
+	check := func(sub *spec.Schema, subPath string) {
+		if sub != nil && err == nil {
+			err = checkCompilable(sub, path+subPath)
+		}
+	}
+	for _, name := range sortedSchemaKeys(schema.Properties) {
+		sub := schema.Properties[name]
+		check(&sub, "/properties/"+name)
+	}
+	for _, name := range sortedSchemaKeys(schema.PatternProperties) {
+		sub := schema.PatternProperties[name]
+		check(&sub, "/patternProperties/"+name)
+	}
+	if schema.AdditionalProperties != nil {
+		check(schema.AdditionalProperties.Schema, "/additionalProperties")
+	}
+	if schema.UnevaluatedProperties != nil {
+		check(schema.UnevaluatedProperties.Schema, "/unevaluatedProperties")
+	}
+	if schema.Items != nil {
+		check(schema.Items.Schema, "/items")
+		for i := range schema.Items.Schemas {
+			check(&schema.Items.Schemas[i], fmt.Sprintf("/items/%d", i))
+		}
+	}
+	for i := range schema.PrefixItems {
+		check(&schema.PrefixItems[i], fmt.Sprintf("/prefixItems/%d", i))
+	}
+	if schema.AdditionalItems != nil {
+		check(schema.AdditionalItems.Schema, "/additionalItems")
+	}
+	if schema.UnevaluatedItems != nil {
+		check(schema.UnevaluatedItems.Schema, "/unevaluatedItems")
+	}
+	for i := range schema.AllOf {
+		check(&schema.AllOf[i], fmt.Sprintf("/allOf/%d", i))
+	}
+	for i := range schema.AnyOf {
+		check(&schema.AnyOf[i], fmt.Sprintf("/anyOf/%d", i))
+	}
+	for i := range schema.OneOf {
+		check(&schema.OneOf[i], fmt.Sprintf("/oneOf/%d", i))
+	}
+	check(schema.Not, "/not")
+	deps := make([]string, 0, len(schema.Dependencies))
+	for name := range schema.Dependencies {
+		deps = append(deps, name)
+	}
+	sort.Strings(deps)
+	for _, name := range deps {
+		check(schema.Dependencies[name].Schema, "/dependencies/"+name)
+	}
+	return err
+}
+
+func displayPath(path string) string {
+	if path == "" {
+		return "schema"
+	}
+	return "schema" + path
+}
+
+func sortedSchemaKeys(m map[string]spec.Schema) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// subValidatorKey identifies a subschema by the keyword holding it and its
+// name or index under that keyword.
+type subValidatorKey struct {
+	keyword string
+	name    string
+}
+
+// subValidators caches the validators of the subschemas of a schema. It is
+// only used when validators are reused, see Compile.
+type subValidators map[interface{}]*SchemaValidator
+
+// get returns a validator of schema, the subschema identified by key, for the
+// value at path. When options enable reuse, the validator is built once and
+// retargeted to path afterwards, like the items validator of a slice.
+func (c *subValidators) get(key interface{}, schema *spec.Schema, root interface{}, path string, formats strfmt.Registry, options SchemaValidatorOptions) *SchemaValidator {
+	if !options.reuseValidators {
+		return NewSchemaValidator(schema, root, path, formats, options.Options()...)
+	}
+	if v, ok := (*c)[key]; ok {
+		v.SetPath(path)
+		return v
+	}
+	sch := *schema
+	v := NewSchemaValidator(&sch, root, path, formats, options.Options()...)
+	if *c == nil {
+		*c = subValidators{}
+	}
+	(*c)[key] = v
+	return v
+}
@@ -0,0 +1,230 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validate
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	"k8s.io/kube-openapi/pkg/validation/strfmt"
+)
+
+func resultErrors(r *Result) []string {
+	errs := make([]string, 0, len(r.Errors))
+	for _, err := range r.Errors {
+		errs = append(errs, err.Error())
+	}
+	sort.Strings(errs)
+	return errs
+}
+
+// TestCompiledSchemaSuite checks that compiled schemas validate the
+// jsonschema-suite fixtures like SchemaValidator does, including when their
+// validators are reused.
+func TestCompiledSchemaSuite(t *testing.T) {
+	files, err := os.ReadDir(jsonSchemaFixturesPath)
+	require.NoError(t, err)
+
+	for _, f := range files {
+		specName := strings.TrimSuffix(f.Name(), filepath.Ext(f.Name()))
+		if f.IsDir() || !isEnabled(specName) {
+			continue
+		}
+		t.Run(specName, func(t *testing.T) {
+			b, err := os.ReadFile(filepath.Join(jsonSchemaFixturesPath, f.Name()))
+			require.NoError(t, err)
+			var testDescriptions []schemaTestT
+			require.NoError(t, json.Unmarshal(b, &testDescriptions))
+
+			for _, testDescription := range testDescriptions {
+				compiled, err := Compile(testDescription.Schema, strfmt.Default)
+				if err != nil {
+					assert.Contains(t, err.Error(), "schema references not supported", testDescription.Description)
+					continue
+				}
+				validator := NewSchemaValidator(testDescription.Schema, nil, "", strfmt.Default)
+				for round := 0; round < 2; round++ {
+					for _, test := range testDescription.Tests {
+						expected := resultErrors(validator.Validate(test.Data))
+						got := resultErrors(compiled.Validate(test.Data))
+						assert.Equal(t, expected, got, testDescription.Description+": "+test.Description)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	var tests = []struct {
+		name   string
+		schema string
+		err    string
+	}{
+		{
+			name:   "nested reference",
+			schema: `{"properties": {"a": {"items": {"$ref": "#/definitions/b"}}}}`,
+			err:    `schema/properties/a/items: schema references not supported: #/definitions/b`,
+		},
+		{
+			name:   "invalid pattern",
+			schema: `{"allOf": [{}, {"pattern": "a("}]}`,
+			err:    `schema/allOf/1: invalid pattern "a("`,
+		},
+		{
+			name:   "invalid pattern property",
+			schema: `{"additionalProperties": {"patternProperties": {"[": {}}}}`,
+			err:    `schema/additionalProperties: invalid pattern property "["`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var schema spec.Schema
+			require.NoError(t, json.Unmarshal([]byte(tt.schema), &schema))
+			_, err := Compile(&schema, strfmt.Default)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+
+	_, err := Compile(nil, strfmt.Default)
+	assert.Error(t, err)
+}
+
+func compileTestSchema(t testing.TB) *spec.Schema {
+	var schema spec.Schema
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"type": "object",
+		"required": ["name"],
+		"properties": {
+			"name": {"type": "string", "pattern": "^[a-z]+$"},
+			"replicas": {"type": "integer", "minimum": 0},
+			"containers": {
+				"type": "array",
+				"items": {
+					"type": "object",
+					"properties": {
+						"image": {"type": "string", "minLength": 1},
+						"ports": {"type": "array", "items": {"type": "integer", "maximum": 65535}}
+					}
+				}
+			},
+			"labels": {"type": "object", "additionalProperties": {"type": "string", "maxLength": 63}}
+		}
+	}`), &schema))
+	return &schema
+}
+
+func compileTestData(i int) map[string]interface{} {
+	data := map[string]interface{}{
+		"name":     "web",
+		"replicas": int64(3),
+		"containers": []interface{}{
+			map[string]interface{}{"image": "nginx", "ports": []interface{}{int64(80), int64(443)}},
+			map[string]interface{}{"image": "sidecar"},
+		},
+		"labels": map[string]interface{}{"app": "web"},
+	}
+	if i%2 == 1 {
+		data["name"] = "Web"
+		data["containers"] = []interface{}{map[string]interface{}{"image": "", "ports": []interface{}{int64(70000)}}}
+	}
+	return data
+}
+
+func TestCompiledSchemaConcurrent(t *testing.T) {
+	schema := compileTestSchema(t)
+	compiled, err := Compile(schema, strfmt.Default)
+	require.NoError(t, err)
+	validator := NewSchemaValidator(schema, nil, "", strfmt.Default)
+	expected := [][]string{
+		resultErrors(validator.Validate(compileTestData(0))),
+		resultErrors(validator.Validate(compileTestData(1))),
+	}
+	require.Empty(t, expected[0])
+	require.Equal(t, []string{
+		`containers[0].image in body should be at least 1 chars long`,
+		`containers[0].ports[0] in body should be less than or equal to 65535`,
+		`name in body should match '^[a-z]+$'`,
+	}, expected[1])
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				got := resultErrors(compiled.Validate(compileTestData(i + j)))
+				if !assert.Equal(t, expected[(i+j)%2], got) {
+					return
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestCompiledSchemaJSON(t *testing.T) {
+	compiled, err := Compile(compileTestSchema(t), strfmt.Default)
+	require.NoError(t, err)
+	data, err := compiled.MarshalSchemaJSON()
+	require.NoError(t, err)
+
+	loaded, err := CompileSchemaJSON(data, strfmt.Default)
+	require.NoError(t, err)
+	assert.Equal(t, compiled.Schema(), loaded.Schema())
+	for i := 0; i < 2; i++ {
+		assert.Equal(t, resultErrors(compiled.Validate(compileTestData(i))), resultErrors(loaded.Validate(compileTestData(i))))
+	}
+
+	_, err = CompileSchemaJSON([]byte(`{"version": 0, "schema": {}}`), strfmt.Default)
+	assert.EqualError(t, err, "unsupported serialized schema version 0, expected 1")
+	_, err = CompileSchemaJSON([]byte(`{"version": 1}`), strfmt.Default)
+	assert.EqualError(t, err, "no schema to compile")
+	// the serialized schema is checked again when compiled
+	_, err = CompileSchemaJSON([]byte(`{"version": 1, "schema": {"pattern": "["}}`), strfmt.Default)
+	assert.Error(t, err)
+}
+
+func BenchmarkSchemaValidator(b *testing.B) {
+	validator := NewSchemaValidator(compileTestSchema(b), nil, "", strfmt.Default)
+	data := compileTestData(0)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		validator.Validate(data)
+	}
+}
+
+func BenchmarkCompiledSchema(b *testing.B) {
+	compiled, err := Compile(compileTestSchema(b), strfmt.Default)
+	require.NoError(b, err)
+	data := compileTestData(0)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		compiled.Validate(data)
+	}
+}
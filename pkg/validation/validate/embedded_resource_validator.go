@@ -0,0 +1,101 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validate
+
+import (
+	"reflect"
+
+	"k8s.io/kube-openapi/pkg/validation/errors"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+const embeddedResourceExtension = "x-kubernetes-embedded-resource"
+
+// EmbeddedMetadataValidator validates the metadata of an embedded resource.
+// path is the path of the metadata field itself, e.g. "spec.template.metadata".
+type EmbeddedMetadataValidator func(path string, metadata map[string]interface{}) []error
+
+// embeddedResourceValidator checks that objects whose schema carries
+// x-kubernetes-embedded-resource look like Kubernetes objects: apiVersion and
+// kind are non-empty strings and metadata, if set, is an object.
+type embeddedResourceValidator struct {
+	Path     string
+	In       string
+	Embedded bool
+	Options  SchemaValidatorOptions
+}
+
+func (e *embeddedResourceValidator) SetPath(path string) {
+	e.Path = path
+}
+
+func (e *embeddedResourceValidator) Applies(source interface{}, kind reflect.Kind) bool {
+	r := e.Embedded && reflect.TypeOf(source) == specSchemaType && kind == reflect.Map
+	debugLog("embedded resource validator for %q applies %t for %T (kind: %v)\n", e.Path, r, source, kind)
+	return r
+}
+
+func (e *embeddedResourceValidator) Validate(data interface{}) *Result {
+	obj, ok := data.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	res := new(Result)
+	for _, field := range []string{"apiVersion", "kind"} {
+		path := e.childPath(field)
+		v, found := obj[field]
+		if !found || v == nil {
+			res.AddErrors(errors.Required(path, e.In))
+			continue
+		}
+		s, isString := v.(string)
+		if !isString {
+			res.AddErrors(errors.InvalidType(path, e.In, stringType, jsonTypeOf(v)))
+			continue
+		}
+		if s == "" {
+			res.AddErrors(errors.Required(path, e.In))
+		}
+	}
+	if v, found := obj["metadata"]; found && v != nil {
+		path := e.childPath("metadata")
+		metadata, isObject := v.(map[string]interface{})
+		if !isObject {
+			res.AddErrors(errors.InvalidType(path, e.In, objectType, jsonTypeOf(v)))
+		} else if e.Options.embeddedMetadataValidator != nil {
+			res.AddErrors(e.Options.embeddedMetadataValidator(path, metadata)...)
+		}
+	}
+	return res
+}
+
+func (e *embeddedResourceValidator) childPath(field string) string {
+	if e.Path == "" {
+		return field
+	}
+	return e.Path + "." + field
+}
+
+func jsonTypeOf(v interface{}) string {
+	schType, _ := new(typeValidator).schemaInfoForType(v)
+	return schType
+}
+
+func isEmbeddedResource(schema *spec.Schema) bool {
+	embedded, _ := schema.Extensions.GetBool(embeddedResourceExtension)
+	return embedded
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validate
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	"k8s.io/kube-openapi/pkg/validation/strfmt"
+)
+
+func TestEmbeddedResourceValidator(t *testing.T) {
+	schema := new(spec.Schema)
+	require.NoError(t, json.Unmarshal([]byte(`{
+  "type": "object",
+  "properties": {
+    "template": {
+      "type": "object",
+      "x-kubernetes-embedded-resource": true,
+      "x-kubernetes-preserve-unknown-fields": true
+    }
+  }
+}`), schema))
+
+	nameRequired := func(path string, metadata map[string]interface{}) []error {
+		if _, ok := metadata["name"]; !ok {
+			return []error{fmt.Errorf("%s.name is required", path)}
+		}
+		return nil
+	}
+
+	cases := []struct {
+		name     string
+		template interface{}
+		options  []Option
+		errors   []string
+	}{
+		{
+			name:     "disabled",
+			template: map[string]interface{}{"kind": ""},
+		},
+		{
+			name:     "valid",
+			template: map[string]interface{}{"apiVersion": "v1", "kind": "Pod", "metadata": map[string]interface{}{}},
+			options:  []Option{WithEmbeddedResourceValidation()},
+		},
+		{
+			name:     "missing apiVersion and empty kind",
+			template: map[string]interface{}{"kind": ""},
+			options:  []Option{WithEmbeddedResourceValidation()},
+			errors:   []string{"template.apiVersion in body is required", "template.kind in body is required"},
+		},
+		{
+			name:     "wrong types",
+			template: map[string]interface{}{"apiVersion": int64(1), "kind": "Pod", "metadata": "foo"},
+			options:  []Option{WithEmbeddedResourceValidation()},
+			errors:   []string{"template.apiVersion in body must be of type string: \"integer\"", "template.metadata in body must be of type object: \"string\""},
+		},
+		{
+			name:     "metadata validator",
+			template: map[string]interface{}{"apiVersion": "v1", "kind": "Pod", "metadata": map[string]interface{}{}},
+			options:  []Option{WithEmbeddedResourceValidation(), WithEmbeddedMetadataValidator(nameRequired)},
+			errors:   []string{"template.metadata.name is required"},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			res := NewSchemaValidator(schema, nil, "", strfmt.Default, tc.options...).Validate(map[string]interface{}{"template": tc.template})
+			var errs []string
+			for _, err := range res.Errors {
+				errs = append(errs, err.Error())
+			}
+			assert.ElementsMatch(t, tc.errors, errs)
+		})
+	}
+}
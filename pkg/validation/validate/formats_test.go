@@ -2,9 +2,11 @@ package validate
 
 import (
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"k8s.io/kube-openapi/pkg/validation/spec"
 	"k8s.io/kube-openapi/pkg/validation/strfmt"
 )
@@ -33,3 +35,20 @@ func TestFormatValidator_EdgeCases(t *testing.T) {
 	assert.False(t, v.Applies("A string", reflect.String))
 	assert.False(t, v.Applies(nil, reflect.String))
 }
+
+func TestFormatValidator_WithFormats(t *testing.T) {
+	registry, err := strfmt.Clone(strfmt.Default)
+	require.NoError(t, err)
+	registry.Add("lowercase", new(strfmt.Hostname), func(s string) bool {
+		return strings.ToLower(s) == s
+	})
+
+	s := spec.Schema{}
+	s.Typed(stringType, "lowercase")
+	sch := spec.MapProperty(&s)
+
+	input := map[string]interface{}{"key": "NotLower"}
+	assert.NoError(t, AgainstSchema(sch, input, strfmt.Default))
+	assert.Error(t, AgainstSchema(sch, input, strfmt.Default, WithFormats(registry)))
+	assert.False(t, strfmt.Default.ContainsName("lowercase"))
+}
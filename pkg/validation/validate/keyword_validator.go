@@ -0,0 +1,102 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validate
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+// KeywordContext is the context in which a custom keyword is validated. It
+// is only valid during the call to the KeywordValidator.
+type KeywordContext struct {
+	// Path is the path of the validated value, e.g. "spec.replicas".
+	Path string
+	// In is where the value is located, e.g. "body".
+	In string
+	// Schema is the schema carrying the keyword.
+	Schema *spec.Schema
+	// Root is the root schema of the validation, to resolve references.
+	Root interface{}
+}
+
+// KeywordValidator validates a value against the value of a custom keyword
+// of its schema, e.g. the rules of x-kubernetes-validations. It returns the
+// errors found, which are added to the validation result.
+type KeywordValidator func(ctx KeywordContext, keywordValue interface{}, data interface{}) []error
+
+// keywordValidator runs the keyword validators registered for the custom
+// keywords, extensions or not, present in a schema.
+type keywordValidator struct {
+	Path       string
+	In         string
+	Schema     *spec.Schema
+	Root       interface{}
+	validators map[string]KeywordValidator
+}
+
+func (k *keywordValidator) SetPath(path string) {
+	k.Path = path
+}
+
+func (k *keywordValidator) Applies(source interface{}, kind reflect.Kind) bool {
+	if reflect.TypeOf(source) != specSchemaType {
+		return false
+	}
+	for keyword := range k.validators {
+		if _, ok := keywordValue(k.Schema, keyword); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (k *keywordValidator) Validate(data interface{}) *Result {
+	keywords := make([]string, 0, len(k.validators))
+	for keyword := range k.validators {
+		keywords = append(keywords, keyword)
+	}
+	sort.Strings(keywords)
+
+	res := new(Result)
+	ctx := KeywordContext{Path: k.Path, In: k.In, Schema: k.Schema, Root: k.Root}
+	for _, keyword := range keywords {
+		if v, ok := keywordValue(k.Schema, keyword); ok {
+			res.AddErrors(k.validators[keyword](ctx, v, data)...)
+		}
+	}
+	return res
+}
+
+// keywordValue returns the value of keyword in schema, looking it up in the
+// extensions if it is one and in the extra properties otherwise.
+func keywordValue(schema *spec.Schema, keyword string) (interface{}, bool) {
+	if schema == nil {
+		return nil, false
+	}
+	if v, ok := schema.Extensions[keyword]; ok {
+		return v, true
+	}
+	if v, ok := schema.Extensions[strings.ToLower(keyword)]; ok {
+		return v, true
+	}
+	v, ok := schema.ExtraProps[keyword]
+	return v, ok
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validate
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	"k8s.io/kube-openapi/pkg/validation/strfmt"
+)
+
+func TestKeywordValidator(t *testing.T) {
+	schema := new(spec.Schema)
+	require.NoError(t, json.Unmarshal([]byte(`{
+  "type": "object",
+  "properties": {
+    "replicas": {
+      "type": "integer",
+      "x-kubernetes-validations": [{"rule": "odd"}]
+    },
+    "items": {
+      "type": "array",
+      "items": {"type": "string", "maxCost": 3}
+    }
+  }
+}`), schema))
+
+	var contexts []KeywordContext
+	var types []string
+	odd := func(ctx KeywordContext, keywordValue interface{}, data interface{}) []error {
+		contexts = append(contexts, ctx)
+		types = append(types, ctx.Schema.Type[0])
+		rules, ok := keywordValue.([]interface{})
+		if !ok || len(rules) != 1 {
+			return []error{fmt.Errorf("%s: unexpected rules %v", ctx.Path, keywordValue)}
+		}
+		if n, ok := data.(int64); ok && n%2 == 0 {
+			return []error{fmt.Errorf("%s must be odd", ctx.Path)}
+		}
+		return nil
+	}
+	maxCost := func(ctx KeywordContext, keywordValue interface{}, data interface{}) []error {
+		if s, ok := data.(string); ok && float64(len(s)) > keywordValue.(float64) {
+			return []error{fmt.Errorf("%s is too expensive", ctx.Path)}
+		}
+		return nil
+	}
+	options := []Option{
+		WithKeywordValidator("x-kubernetes-validations", odd),
+		WithKeywordValidator("maxCost", maxCost),
+	}
+
+	data := map[string]interface{}{
+		"replicas": json.Number("2"),
+		"items":    []interface{}{"abc", "abcd"},
+	}
+	res := NewSchemaValidator(schema, nil, "", strfmt.Default, options...).Validate(data)
+	var errs []string
+	for _, err := range res.Errors {
+		errs = append(errs, err.Error())
+	}
+	assert.ElementsMatch(t, []string{"replicas must be odd", "items[1] is too expensive"}, errs)
+
+	require.Len(t, contexts, 1)
+	assert.Equal(t, "replicas", contexts[0].Path)
+	assert.Equal(t, "body", contexts[0].In)
+	assert.Same(t, schema, contexts[0].Root)
+	assert.Equal(t, []string{"integer"}, types)
+
+	res = NewSchemaValidator(schema, nil, "", strfmt.Default).Validate(data)
+	assert.True(t, res.IsValid(), "keywords without validators should be ignored")
+}
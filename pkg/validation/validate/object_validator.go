@@ -35,6 +35,7 @@ type objectValidator struct {
 	Root                 interface{}
 	KnownFormats         strfmt.Registry
 	Options              SchemaValidatorOptions
+	subValidators        subValidators
 }
 
 func (o *objectValidator) SetPath(path string) {
@@ -99,7 +100,7 @@ func (o *objectValidator) Validate(data interface{}) *Result {
 				// Cases: properties which are not regular properties and have not been matched by the PatternProperties validator
 				if o.AdditionalProperties != nil && o.AdditionalProperties.Schema != nil {
 					// AdditionalProperties as Schema
-					res.Merge(NewSchemaValidator(o.AdditionalProperties.Schema, o.Root, o.Path+"."+key, o.KnownFormats, o.Options.Options()...).Validate(value))
+					res.Merge(o.subValidators.get(subValidatorKey{keyword: "additionalProperties"}, o.AdditionalProperties.Schema, o.Root, o.Path+"."+key, o.KnownFormats, o.Options).Validate(value))
 				} else if regularProperty && !(matched || succeededOnce) {
 					// TODO: this is dead code since regularProperty=false here
 					res.AddErrors(errors.FailedAllPatternProperties(o.Path, o.In, key))
@@ -121,7 +122,7 @@ func (o *objectValidator) Validate(data interface{}) *Result {
 
 		// Recursively validates each property against its schema
 		if v, ok := val[pName]; ok {
-			r := NewSchemaValidator(&pSchema, o.Root, rName, o.KnownFormats, o.Options.Options()...).Validate(v)
+			r := o.subValidators.get(subValidatorKey{keyword: "properties", name: pName}, &pSchema, o.Root, rName, o.KnownFormats, o.Options).Validate(v)
 			res.Merge(r)
 		}
 	}
@@ -129,7 +130,7 @@ func (o *objectValidator) Validate(data interface{}) *Result {
 	// Check required properties
 	if len(o.Required) > 0 {
 		for _, k := range o.Required {
-			if _, ok := val[k]; !ok && !createdFromDefaults[k] {
+			if _, ok := val[k]; !ok && !createdFromDefaults[k] && o.Options.requires(o.Properties[k]) {
 				res.AddErrors(errors.Required(o.Path+"."+k, o.In))
 				continue
 			}
@@ -144,7 +145,7 @@ func (o *objectValidator) Validate(data interface{}) *Result {
 		if !regularProperty && (matched /*|| succeededOnce*/) {
 			for _, pName := range patterns {
 				if v, ok := o.PatternProperties[pName]; ok {
-					res.Merge(NewSchemaValidator(&v, o.Root, o.Path+"."+key, o.KnownFormats, o.Options.Options()...).Validate(value))
+					res.Merge(o.subValidators.get(subValidatorKey{keyword: "patternProperties", name: pName}, &v, o.Root, o.Path+"."+key, o.KnownFormats, o.Options).Validate(value))
 				}
 			}
 		}
@@ -163,7 +164,7 @@ func (o *objectValidator) validatePatternProperty(key string, value interface{},
 		if match, _ := regexp.MatchString(k, key); match {
 			patterns = append(patterns, k)
 			matched = true
-			validator := NewSchemaValidator(&sch, o.Root, o.Path+"."+key, o.KnownFormats, o.Options.Options()...)
+			validator := o.subValidators.get(subValidatorKey{keyword: "patternProperties", name: k}, &sch, o.Root, o.Path+"."+key, o.KnownFormats, o.Options)
 
 			res := validator.Validate(value)
 			result.Merge(res)
@@ -0,0 +1,93 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validate
+
+import (
+	"reflect"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+// ValidationContext tells whether the validated data is sent in a request or
+// in a response, which decides how readOnly and writeOnly are enforced.
+type ValidationContext int
+
+const (
+	// AnyContext does not enforce readOnly and writeOnly. It is the default.
+	AnyContext ValidationContext = iota
+	// RequestContext rejects values whose schema is readOnly, and does not
+	// require readOnly properties.
+	RequestContext
+	// ResponseContext rejects values whose schema is writeOnly, and does not
+	// require writeOnly properties.
+	ResponseContext
+)
+
+// readWriteValidator rejects the values whose schema is readOnly in requests,
+// or writeOnly in responses.
+type readWriteValidator struct {
+	Path      string
+	In        string
+	ReadOnly  bool
+	WriteOnly bool
+	Options   SchemaValidatorOptions
+}
+
+func (r *readWriteValidator) SetPath(path string) {
+	r.Path = path
+}
+
+func (r *readWriteValidator) Applies(source interface{}, kind reflect.Kind) bool {
+	applies := reflect.TypeOf(source) == specSchemaType && r.Options.disallows(r.ReadOnly, r.WriteOnly)
+	debugLog("read/write validator for %q applies %t for %T (kind: %v)\n", r.Path, applies, source, kind)
+	return applies
+}
+
+func (r *readWriteValidator) Validate(data interface{}) *Result {
+	res := new(Result)
+	var err error
+	if r.ReadOnly && r.Options.validationContext == RequestContext {
+		err = readOnlyInRequestMsg(r.Path)
+	} else {
+		err = writeOnlyInResponseMsg(r.Path)
+	}
+	if r.Options.readWriteWarnings {
+		res.AddWarnings(err)
+	} else {
+		res.AddErrors(err)
+	}
+	return res
+}
+
+// disallows returns true if values of a schema with the given readOnly and
+// writeOnly must not be sent in the validation context.
+func (svo SchemaValidatorOptions) disallows(readOnly, writeOnly bool) bool {
+	switch svo.validationContext {
+	case RequestContext:
+		return readOnly
+	case ResponseContext:
+		return writeOnly
+	}
+	return false
+}
+
+// requires returns true if the required property with the given schema must
+// be present in the validation context: readOnly properties are only required
+// in responses, and writeOnly properties in requests.
+func (svo SchemaValidatorOptions) requires(schema spec.Schema) bool {
+	return !svo.disallows(schema.ReadOnly, schema.WriteOnly)
+}
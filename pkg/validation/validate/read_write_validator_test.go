@@ -0,0 +1,113 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validate
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	"k8s.io/kube-openapi/pkg/validation/strfmt"
+)
+
+func TestReadWriteValidator(t *testing.T) {
+	schema := new(spec.Schema)
+	require.NoError(t, json.Unmarshal([]byte(`{
+  "type": "object",
+  "required": ["id", "password", "name"],
+  "properties": {
+    "id": {"type": "string", "readOnly": true},
+    "password": {"type": "string", "writeOnly": true},
+    "name": {"type": "string"},
+    "status": {
+      "type": "object",
+      "properties": {"phase": {"type": "string", "readOnly": true}}
+    }
+  }
+}`), schema))
+	assert.True(t, schema.Properties["password"].WriteOnly)
+
+	full := map[string]interface{}{
+		"id":       "1",
+		"password": "secret",
+		"name":     "foo",
+		"status":   map[string]interface{}{"phase": "Running"},
+	}
+	cases := []struct {
+		name     string
+		data     map[string]interface{}
+		options  []Option
+		errors   []string
+		warnings []string
+	}{
+		{
+			name: "any context",
+			data: full,
+		},
+		{
+			name:   "any context requires everything",
+			data:   map[string]interface{}{"name": "foo"},
+			errors: []string{".id in body is required", ".password in body is required"},
+		},
+		{
+			name:    "request",
+			data:    full,
+			options: []Option{WithValidationContext(RequestContext)},
+			errors:  []string{`"id" is read only and must not be sent in requests`, `"status.phase" is read only and must not be sent in requests`},
+		},
+		{
+			name:    "request does not require readOnly",
+			data:    map[string]interface{}{"name": "foo"},
+			options: []Option{WithValidationContext(RequestContext)},
+			errors:  []string{".password in body is required"},
+		},
+		{
+			name:    "response",
+			data:    full,
+			options: []Option{WithValidationContext(ResponseContext)},
+			errors:  []string{`"password" is write only and must not be sent in responses`},
+		},
+		{
+			name:    "response does not require writeOnly",
+			data:    map[string]interface{}{"id": "1", "name": "foo"},
+			options: []Option{WithValidationContext(ResponseContext)},
+		},
+		{
+			name:     "warnings",
+			data:     full,
+			options:  []Option{WithValidationContext(ResponseContext), WithReadWriteWarnings()},
+			warnings: []string{`"password" is write only and must not be sent in responses`},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			res := NewSchemaValidator(schema, nil, "", strfmt.Default, tc.options...).Validate(tc.data)
+			var errs, warnings []string
+			for _, err := range res.Errors {
+				errs = append(errs, err.Error())
+			}
+			for _, w := range res.Warnings {
+				warnings = append(warnings, w.Error())
+			}
+			assert.ElementsMatch(t, tc.errors, errs)
+			assert.ElementsMatch(t, tc.warnings, warnings)
+		})
+	}
+}
@@ -78,6 +78,9 @@ func NewSchemaValidator(schema *spec.Schema, rootSchema interface{}, root string
 	for _, o := range options {
 		o(&s.Options)
 	}
+	if s.Options.formats != nil {
+		s.KnownFormats = s.Options.formats
+	}
 	s.validators = []valueValidator{
 		s.typeValidator(),
 		s.schemaPropsValidator(),
@@ -87,6 +90,12 @@ func NewSchemaValidator(schema *spec.Schema, rootSchema interface{}, root string
 		s.sliceValidator(),
 		s.commonValidator(),
 		s.objectValidator(),
+		s.readWriteValidator(),
+		s.unevaluatedValidator(),
+		s.keywordValidator(),
+	}
+	if s.Options.embeddedResourceValidation {
+		s.validators = append(s.validators, s.embeddedResourceValidator())
 	}
 	return &s
 }
@@ -195,6 +204,7 @@ func (s *SchemaValidator) sliceValidator() valueValidator {
 		UniqueItems:     s.Schema.UniqueItems,
 		AdditionalItems: s.Schema.AdditionalItems,
 		Items:           s.Schema.Items,
+		PrefixItems:     s.Schema.PrefixItems,
 		Root:            s.Root,
 		KnownFormats:    s.KnownFormats,
 		Options:         s.Options,
@@ -238,6 +248,46 @@ func (s *SchemaValidator) schemaPropsValidator() valueValidator {
 	return newSchemaPropsValidator(s.Path, s.in, sch.AllOf, sch.OneOf, sch.AnyOf, sch.Not, sch.Dependencies, s.Root, s.KnownFormats, s.Options.Options()...)
 }
 
+func (s *SchemaValidator) embeddedResourceValidator() valueValidator {
+	return &embeddedResourceValidator{
+		Path:     s.Path,
+		In:       s.in,
+		Embedded: isEmbeddedResource(s.Schema),
+		Options:  s.Options,
+	}
+}
+
+func (s *SchemaValidator) readWriteValidator() valueValidator {
+	return &readWriteValidator{
+		Path:      s.Path,
+		In:        s.in,
+		ReadOnly:  s.Schema.ReadOnly,
+		WriteOnly: s.Schema.WriteOnly,
+		Options:   s.Options,
+	}
+}
+
+func (s *SchemaValidator) unevaluatedValidator() valueValidator {
+	return &unevaluatedValidator{
+		Path:         s.Path,
+		In:           s.in,
+		Schema:       s.Schema,
+		Root:         s.Root,
+		KnownFormats: s.KnownFormats,
+		Options:      s.Options,
+	}
+}
+
+func (s *SchemaValidator) keywordValidator() valueValidator {
+	return &keywordValidator{
+		Path:       s.Path,
+		In:         s.in,
+		Schema:     s.Schema,
+		Root:       s.Root,
+		validators: s.Options.keywordValidators,
+	}
+}
+
 func (s *SchemaValidator) objectValidator() valueValidator {
 	return &objectValidator{
 		Path:                 s.Path,
@@ -25,6 +25,9 @@ const (
 	// TODO: should move to package go-openapi/errors
 	ArrayDoesNotAllowAdditionalItemsError = "array doesn't allow for additional items"
 
+	// ArrayDoesNotAllowUnevaluatedItemsError when an unevaluatedItems construct is not verified by the array values provided.
+	ArrayDoesNotAllowUnevaluatedItemsError = "%q doesn't allow for unevaluated items"
+
 	// HasDependencyError indicates that a dependencies construct was not verified
 	HasDependencyError = "%q has a dependency on %s"
 
@@ -44,6 +47,12 @@ const (
 
 	// MustNotValidateSchemaError indicates that in a Not construct, the schema constraint specified was verified
 	MustNotValidateSchemaError = "%q must not validate the schema (not)"
+
+	// ReadOnlyInRequestError indicates that a readOnly value was sent in a request
+	ReadOnlyInRequestError = "%q is read only and must not be sent in requests"
+
+	// WriteOnlyInResponseError indicates that a writeOnly value was sent in a response
+	WriteOnlyInResponseError = "%q is write only and must not be sent in responses"
 )
 
 // Warning messages related to schema validation and returned as results
@@ -70,3 +79,12 @@ func hasADependencyMsg(path, depkey string) errors.Error {
 func arrayDoesNotAllowAdditionalItemsMsg() errors.Error {
 	return errors.New(errors.CompositeErrorCode, ArrayDoesNotAllowAdditionalItemsError)
 }
+func arrayDoesNotAllowUnevaluatedItemsMsg(path string) errors.Error {
+	return errors.New(errors.CompositeErrorCode, ArrayDoesNotAllowUnevaluatedItemsError, path)
+}
+func readOnlyInRequestMsg(path string) errors.Error {
+	return errors.New(errors.CompositeErrorCode, ReadOnlyInRequestError, path)
+}
+func writeOnlyInResponseMsg(path string) errors.Error {
+	return errors.New(errors.CompositeErrorCode, WriteOnlyInResponseError, path)
+}
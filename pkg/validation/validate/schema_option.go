@@ -14,15 +14,116 @@
 
 package validate
 
+import "k8s.io/kube-openapi/pkg/validation/strfmt"
+
 // SchemaValidatorOptions defines optional rules for schema validation
 type SchemaValidatorOptions struct {
-	validationRulesEnabled bool
+	validationRulesEnabled     bool
+	formats                    strfmt.Registry
+	embeddedResourceValidation bool
+	embeddedMetadataValidator  EmbeddedMetadataValidator
+	keywordValidators          map[string]KeywordValidator
+	reuseValidators            bool
+	validationContext          ValidationContext
+	readWriteWarnings          bool
 }
 
 // Option sets optional rules for schema validation
 type Option func(*SchemaValidatorOptions)
 
+// WithFormats makes the validator use the given format registry instead of
+// the one passed to NewSchemaValidator or AgainstSchema. Combined with
+// strfmt.Clone, this lets callers validate against an isolated registry
+// without touching strfmt.Default.
+func WithFormats(formats strfmt.Registry) Option {
+	return func(svo *SchemaValidatorOptions) {
+		svo.formats = formats
+	}
+}
+
+// WithEmbeddedResourceValidation checks that objects whose schema has
+// x-kubernetes-embedded-resource set have non-empty apiVersion and kind
+// strings, and an object as metadata if it is set.
+func WithEmbeddedResourceValidation() Option {
+	return func(svo *SchemaValidatorOptions) {
+		svo.embeddedResourceValidation = true
+	}
+}
+
+// WithEmbeddedMetadataValidator sets the validator invoked on the metadata of
+// embedded resources when WithEmbeddedResourceValidation is given. Without it,
+// only the presence of apiVersion and kind and the type of metadata are
+// checked.
+func WithEmbeddedMetadataValidator(v EmbeddedMetadataValidator) Option {
+	return func(svo *SchemaValidatorOptions) {
+		svo.embeddedMetadataValidator = v
+	}
+}
+
+// WithKeywordValidator registers the validator invoked on values whose schema
+// carries the given custom keyword, e.g. x-kubernetes-validations, with the
+// value of the keyword. It can be given several times, once per keyword, and
+// replaces any validator previously registered for that keyword.
+func WithKeywordValidator(keyword string, v KeywordValidator) Option {
+	return func(svo *SchemaValidatorOptions) {
+		validators := make(map[string]KeywordValidator, len(svo.keywordValidators)+1)
+		for k, kv := range svo.keywordValidators {
+			validators[k] = kv
+		}
+		validators[keyword] = v
+		svo.keywordValidators = validators
+	}
+}
+
+// WithValidationContext enforces readOnly and writeOnly for data sent in the
+// given context: readOnly values are rejected in requests and writeOnly values
+// in responses, and are not required there.
+func WithValidationContext(c ValidationContext) Option {
+	return func(svo *SchemaValidatorOptions) {
+		svo.validationContext = c
+	}
+}
+
+// WithReadWriteWarnings reports the readOnly and writeOnly values rejected in
+// the validation context as warnings instead of errors.
+func WithReadWriteWarnings() Option {
+	return func(svo *SchemaValidatorOptions) {
+		svo.readWriteWarnings = true
+	}
+}
+
+// withValidatorReuse makes validators cache the validators of subschemas
+// across calls, which is only safe if they are not used concurrently. It is
+// set by Compile.
+func withValidatorReuse() Option {
+	return func(svo *SchemaValidatorOptions) {
+		svo.reuseValidators = true
+	}
+}
+
 // Options returns current options
 func (svo SchemaValidatorOptions) Options() []Option {
-	return []Option{}
+	opts := []Option{}
+	if svo.formats != nil {
+		opts = append(opts, WithFormats(svo.formats))
+	}
+	if svo.embeddedResourceValidation {
+		opts = append(opts, WithEmbeddedResourceValidation())
+	}
+	if svo.embeddedMetadataValidator != nil {
+		opts = append(opts, WithEmbeddedMetadataValidator(svo.embeddedMetadataValidator))
+	}
+	for keyword, v := range svo.keywordValidators {
+		opts = append(opts, WithKeywordValidator(keyword, v))
+	}
+	if svo.reuseValidators {
+		opts = append(opts, withValidatorReuse())
+	}
+	if svo.validationContext != AnyContext {
+		opts = append(opts, WithValidationContext(svo.validationContext))
+	}
+	if svo.readWriteWarnings {
+		opts = append(opts, WithReadWriteWarnings())
+	}
+	return opts
 }
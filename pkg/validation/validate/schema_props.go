@@ -37,18 +37,19 @@ type schemaPropsValidator struct {
 	Root            interface{}
 	KnownFormats    strfmt.Registry
 	Options         SchemaValidatorOptions
+	subValidators   subValidators
 }
 
 func (s *schemaPropsValidator) SetPath(path string) {
 	s.Path = path
-	for _, v := range s.anyOfValidators {
-		v.SetPath(path)
+	for i := range s.anyOfValidators {
+		s.anyOfValidators[i].SetPath(path)
 	}
-	for _, v := range s.allOfValidators {
-		v.SetPath(path)
+	for i := range s.allOfValidators {
+		s.allOfValidators[i].SetPath(path)
 	}
-	for _, v := range s.oneOfValidators {
-		v.SetPath(path)
+	for i := range s.oneOfValidators {
+		s.oneOfValidators[i].SetPath(path)
 	}
 	if s.notValidator != nil {
 		s.notValidator.SetPath(path)
@@ -230,7 +231,7 @@ func (s *schemaPropsValidator) Validate(data interface{}) *Result {
 			if dep, ok := s.Dependencies[key]; ok {
 
 				if dep.Schema != nil {
-					mainResult.Merge(NewSchemaValidator(dep.Schema, s.Root, s.Path+"."+key, s.KnownFormats, s.Options.Options()...).Validate(data))
+					mainResult.Merge(s.subValidators.get(subValidatorKey{keyword: "dependencies", name: key}, dep.Schema, s.Root, s.Path+"."+key, s.KnownFormats, s.Options).Validate(data))
 					continue
 				}
 
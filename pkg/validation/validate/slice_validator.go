@@ -17,6 +17,7 @@ package validate
 import (
 	"fmt"
 	"reflect"
+	"strconv"
 
 	"k8s.io/kube-openapi/pkg/validation/spec"
 	"k8s.io/kube-openapi/pkg/validation/strfmt"
@@ -30,9 +31,11 @@ type schemaSliceValidator struct {
 	UniqueItems     bool
 	AdditionalItems *spec.SchemaOrBool
 	Items           *spec.SchemaOrArray
+	PrefixItems     []spec.Schema
 	Root            interface{}
 	KnownFormats    strfmt.Registry
 	Options         SchemaValidatorOptions
+	subValidators   subValidators
 }
 
 func (s *schemaSliceValidator) SetPath(path string) {
@@ -53,9 +56,15 @@ func (s *schemaSliceValidator) Validate(data interface{}) *Result {
 	val := reflect.ValueOf(data)
 	size := val.Len()
 
+	for i := 0; i < len(s.PrefixItems) && i < size; i++ {
+		validator := s.subValidators.get(subValidatorKey{keyword: "prefixItems", name: strconv.Itoa(i)}, &s.PrefixItems[i], s.Root, fmt.Sprintf("%s[%d]", s.Path, i), s.KnownFormats, s.Options)
+		result.Merge(validator.Validate(val.Index(i).Interface()))
+	}
+
 	if s.Items != nil && s.Items.Schema != nil {
-		validator := NewSchemaValidator(s.Items.Schema, s.Root, s.Path, s.KnownFormats, s.Options.Options()...)
-		for i := 0; i < size; i++ {
+		// with prefixItems, items only applies to the items that follow them
+		validator := s.subValidators.get(subValidatorKey{keyword: "items"}, s.Items.Schema, s.Root, s.Path, s.KnownFormats, s.Options)
+		for i := len(s.PrefixItems); i < size; i++ {
 			validator.SetPath(fmt.Sprintf("%s[%d]", s.Path, i))
 			value := val.Index(i)
 			result.Merge(validator.Validate(value.Interface()))
@@ -66,7 +75,7 @@ func (s *schemaSliceValidator) Validate(data interface{}) *Result {
 	if s.Items != nil && len(s.Items.Schemas) > 0 {
 		itemsSize = len(s.Items.Schemas)
 		for i := 0; i < itemsSize; i++ {
-			validator := NewSchemaValidator(&s.Items.Schemas[i], s.Root, fmt.Sprintf("%s[%d]", s.Path, i), s.KnownFormats, s.Options.Options()...)
+			validator := s.subValidators.get(subValidatorKey{keyword: "items", name: strconv.Itoa(i)}, &s.Items.Schemas[i], s.Root, fmt.Sprintf("%s[%d]", s.Path, i), s.KnownFormats, s.Options)
 			if val.Len() <= i {
 				break
 			}
@@ -78,8 +87,8 @@ func (s *schemaSliceValidator) Validate(data interface{}) *Result {
 			result.AddErrors(arrayDoesNotAllowAdditionalItemsMsg())
 		}
 		if s.AdditionalItems.Schema != nil {
-			for i := itemsSize; i < size-itemsSize+1; i++ {
-				validator := NewSchemaValidator(s.AdditionalItems.Schema, s.Root, fmt.Sprintf("%s[%d]", s.Path, i), s.KnownFormats, s.Options.Options()...)
+			for i := itemsSize; i < size; i++ {
+				validator := s.subValidators.get(subValidatorKey{keyword: "additionalItems"}, s.AdditionalItems.Schema, s.Root, fmt.Sprintf("%s[%d]", s.Path, i), s.KnownFormats, s.Options)
 				result.Merge(validator.Validate(val.Index(i).Interface()))
 			}
 		}
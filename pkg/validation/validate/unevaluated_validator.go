@@ -0,0 +1,189 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validate
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+
+	"k8s.io/kube-openapi/pkg/validation/errors"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	"k8s.io/kube-openapi/pkg/validation/strfmt"
+)
+
+// unevaluatedValidator validates the unevaluatedProperties and
+// unevaluatedItems keywords of JSON schema 2020-12.
+//
+// Unlike additionalProperties and additionalItems, these keywords apply to
+// the properties and items that no other keyword evaluated, including the
+// keywords of the allOf, anyOf, oneOf and dependencies subschemas the value
+// is valid against. The evaluated properties and items are only computed
+// for schemas using these keywords.
+type unevaluatedValidator struct {
+	Path         string
+	In           string
+	Schema       *spec.Schema
+	Root         interface{}
+	KnownFormats strfmt.Registry
+	Options      SchemaValidatorOptions
+
+	subValidators subValidators
+}
+
+func (u *unevaluatedValidator) SetPath(path string) {
+	u.Path = path
+}
+
+func (u *unevaluatedValidator) Applies(source interface{}, kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Map:
+		return u.Schema.UnevaluatedProperties != nil
+	case reflect.Slice:
+		return u.Schema.UnevaluatedItems != nil
+	}
+	return false
+}
+
+func (u *unevaluatedValidator) Validate(data interface{}) *Result {
+	result := new(Result)
+	if data == nil {
+		return result
+	}
+
+	if val, ok := data.(map[string]interface{}); ok {
+		evaluated := map[string]bool{}
+		if !u.evaluatedProperties(u.Schema, val, evaluated) {
+			for key, value := range val {
+				if evaluated[key] {
+					continue
+				}
+				if u.Schema.UnevaluatedProperties.Schema != nil {
+					validator := u.subValidators.get(subValidatorKey{keyword: "unevaluatedProperties"}, u.Schema.UnevaluatedProperties.Schema, u.Root, u.Path+"."+key, u.KnownFormats, u.Options)
+					result.Merge(validator.Validate(value))
+				} else if !u.Schema.UnevaluatedProperties.Allows {
+					result.AddErrors(errors.PropertyNotAllowed(u.Path, u.In, key))
+				}
+			}
+		}
+	} else if val := reflect.ValueOf(data); val.Kind() == reflect.Slice {
+		size := val.Len()
+		evaluated := u.evaluatedItems(u.Schema, data, size)
+		if evaluated < size {
+			if u.Schema.UnevaluatedItems.Schema != nil {
+				for i := evaluated; i < size; i++ {
+					validator := u.subValidators.get(subValidatorKey{keyword: "unevaluatedItems"}, u.Schema.UnevaluatedItems.Schema, u.Root, fmt.Sprintf("%s[%d]", u.Path, i), u.KnownFormats, u.Options)
+					result.Merge(validator.Validate(val.Index(i).Interface()))
+				}
+			} else if !u.Schema.UnevaluatedItems.Allows {
+				result.AddErrors(arrayDoesNotAllowUnevaluatedItemsMsg(u.Path))
+			}
+		}
+	}
+
+	result.Inc()
+	return result
+}
+
+// evaluatedProperties adds the properties of val evaluated by sch to
+// evaluated, and returns true if sch evaluates all of them.
+func (u *unevaluatedValidator) evaluatedProperties(sch *spec.Schema, val map[string]interface{}, evaluated map[string]bool) bool {
+	if sch.AdditionalProperties != nil || (sch != u.Schema && sch.UnevaluatedProperties != nil) {
+		return true
+	}
+	for key := range val {
+		if _, ok := sch.Properties[key]; ok {
+			evaluated[key] = true
+			continue
+		}
+		for pattern := range sch.PatternProperties {
+			if matches, _ := regexp.MatchString(pattern, key); matches {
+				evaluated[key] = true
+				break
+			}
+		}
+	}
+
+	for i := range sch.AllOf {
+		if u.evaluatedProperties(&sch.AllOf[i], val, evaluated) {
+			return true
+		}
+	}
+	for _, subs := range [][]spec.Schema{sch.AnyOf, sch.OneOf} {
+		for i := range subs {
+			if u.isValid(&subs[i], val) && u.evaluatedProperties(&subs[i], val, evaluated) {
+				return true
+			}
+		}
+	}
+	for key, dep := range sch.Dependencies {
+		if _, ok := val[key]; !ok || dep.Schema == nil {
+			continue
+		}
+		if u.isValid(dep.Schema, val) && u.evaluatedProperties(dep.Schema, val, evaluated) {
+			return true
+		}
+	}
+	return false
+}
+
+// evaluatedItems returns the number of leading items of data, a slice of
+// the given size, that sch evaluates.
+func (u *unevaluatedValidator) evaluatedItems(sch *spec.Schema, data interface{}, size int) int {
+	if sch != u.Schema && sch.UnevaluatedItems != nil {
+		return size
+	}
+	evaluated := len(sch.PrefixItems)
+	if sch.Items != nil {
+		if sch.Items.Schema != nil {
+			return size
+		}
+		if len(sch.Items.Schemas) > 0 {
+			if sch.AdditionalItems != nil {
+				return size
+			}
+			if len(sch.Items.Schemas) > evaluated {
+				evaluated = len(sch.Items.Schemas)
+			}
+		}
+	}
+
+	subs := make([]*spec.Schema, 0, len(sch.AllOf))
+	for i := range sch.AllOf {
+		subs = append(subs, &sch.AllOf[i])
+	}
+	for _, alternatives := range [][]spec.Schema{sch.AnyOf, sch.OneOf} {
+		for i := range alternatives {
+			if u.isValid(&alternatives[i], data) {
+				subs = append(subs, &alternatives[i])
+			}
+		}
+	}
+	for _, sub := range subs {
+		if evaluated >= size {
+			break
+		}
+		if n := u.evaluatedItems(sub, data, size); n > evaluated {
+			evaluated = n
+		}
+	}
+	return evaluated
+}
+
+func (u *unevaluatedValidator) isValid(sch *spec.Schema, data interface{}) bool {
+	return u.subValidators.get(sch, sch, u.Root, u.Path, u.KnownFormats, u.Options).Validate(data).IsValid()
+}
@@ -0,0 +1,164 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validate
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	"k8s.io/kube-openapi/pkg/validation/strfmt"
+)
+
+func TestUnevaluatedAndPrefixItems(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		schema string
+		valid  []string
+		errors []string
+	}{
+		{
+			name: "unevaluatedProperties false",
+			schema: `{
+				"type": "object",
+				"properties": {"a": {"type": "string"}},
+				"patternProperties": {"^x-": {}},
+				"unevaluatedProperties": false
+			}`,
+			valid:  []string{`{}`, `{"a": "x", "x-b": 1}`},
+			errors: []string{`{"a": "x", "b": 1}`},
+		},
+		{
+			name: "unevaluatedProperties sees through allOf and anyOf",
+			schema: `{
+				"type": "object",
+				"properties": {"a": {"type": "string"}},
+				"allOf": [{"properties": {"b": {"type": "integer"}}}],
+				"anyOf": [
+					{"properties": {"c": {"type": "string"}}, "required": ["c"]},
+					{"properties": {"d": {"type": "string"}}, "required": ["d"]}
+				],
+				"unevaluatedProperties": false
+			}`,
+			valid:  []string{`{"a": "x", "b": 1, "c": "y"}`, `{"c": "y", "d": "z"}`},
+			errors: []string{`{"c": "y", "e": 1}`, `{"c": 1, "d": "z", "c2": 1}`},
+		},
+		{
+			name: "unevaluatedProperties ignores invalid oneOf branches",
+			schema: `{
+				"type": "object",
+				"oneOf": [
+					{"properties": {"kind": {"enum": ["a"]}, "a": {}}, "required": ["kind"]},
+					{"properties": {"kind": {"enum": ["b"]}, "b": {}}, "required": ["kind"]}
+				],
+				"unevaluatedProperties": false
+			}`,
+			valid:  []string{`{"kind": "a", "a": 1}`},
+			errors: []string{`{"kind": "a", "b": 1}`},
+		},
+		{
+			name: "unevaluatedProperties schema",
+			schema: `{
+				"type": "object",
+				"properties": {"a": {"type": "string"}},
+				"unevaluatedProperties": {"type": "integer"}
+			}`,
+			valid:  []string{`{"a": "x", "b": 1}`},
+			errors: []string{`{"a": "x", "b": "y"}`},
+		},
+		{
+			name: "additionalProperties evaluates everything",
+			schema: `{
+				"type": "object",
+				"additionalProperties": true,
+				"unevaluatedProperties": false
+			}`,
+			valid: []string{`{"a": 1}`},
+		},
+		{
+			name: "prefixItems",
+			schema: `{
+				"type": "array",
+				"prefixItems": [{"type": "string"}, {"type": "integer"}],
+				"items": {"type": "boolean"}
+			}`,
+			valid:  []string{`[]`, `["a"]`, `["a", 1, true, false]`},
+			errors: []string{`[1]`, `["a", 1, "b"]`},
+		},
+		{
+			name: "unevaluatedItems false",
+			schema: `{
+				"type": "array",
+				"prefixItems": [{"type": "string"}],
+				"allOf": [{"prefixItems": [{}, {"type": "integer"}]}],
+				"unevaluatedItems": false
+			}`,
+			valid:  []string{`["a"]`, `["a", 1]`},
+			errors: []string{`["a", 1, 2]`},
+		},
+		{
+			name: "unevaluatedItems schema",
+			schema: `{
+				"type": "array",
+				"prefixItems": [{"type": "string"}],
+				"unevaluatedItems": {"type": "integer"}
+			}`,
+			valid:  []string{`["a", 1, 2]`},
+			errors: []string{`["a", 1, "b"]`},
+		},
+		{
+			name: "items evaluates everything",
+			schema: `{
+				"type": "array",
+				"items": {"type": "integer"},
+				"unevaluatedItems": false
+			}`,
+			valid: []string{`[1, 2, 3]`},
+		},
+		{
+			name: "additionalItems",
+			schema: `{
+				"type": "array",
+				"items": [{"type": "string"}, {"type": "string"}],
+				"additionalItems": {"type": "integer"}
+			}`,
+			valid:  []string{`["a", "b", 1, 2]`},
+			errors: []string{`["a", "b", 1, "c"]`},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			schema := new(spec.Schema)
+			require.NoError(t, json.Unmarshal([]byte(tc.schema), schema))
+			validator := NewSchemaValidator(schema, nil, "", strfmt.Default)
+
+			for _, data := range tc.valid {
+				var v interface{}
+				require.NoError(t, json.Unmarshal([]byte(data), &v))
+				res := validator.Validate(v)
+				assert.True(t, res.IsValid(), "%s: %v", data, res.Errors)
+			}
+			for _, data := range tc.errors {
+				var v interface{}
+				require.NoError(t, json.Unmarshal([]byte(data), &v))
+				assert.False(t, validator.Validate(v).IsValid(), data)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validate
+
+import (
+	"fmt"
+	"sort"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	"k8s.io/kube-openapi/pkg/validation/strfmt"
+)
+
+// VersionedSchemas holds the compiled schemas of the versions an object can
+// be converted to, e.g. the served versions of a CustomResourceDefinition,
+// to validate objects against all of them at once. It is safe for concurrent
+// use.
+type VersionedSchemas struct {
+	// versions holds the names of the versions, sorted.
+	versions []string
+	schemas  map[string]*CompiledSchema
+}
+
+// CompileVersions compiles the schemas of the given versions, see Compile.
+func CompileVersions(schemas map[string]*spec.Schema, formats strfmt.Registry, options ...Option) (*VersionedSchemas, error) {
+	v := &VersionedSchemas{schemas: make(map[string]*CompiledSchema, len(schemas))}
+	for version, schema := range schemas {
+		compiled, err := Compile(schema, formats, options...)
+		if err != nil {
+			return nil, fmt.Errorf("version %s: %v", version, err)
+		}
+		v.versions = append(v.versions, version)
+		v.schemas[version] = compiled
+	}
+	sort.Strings(v.versions)
+	return v, nil
+}
+
+// Versions returns the names of the versions, sorted.
+func (v *VersionedSchemas) Versions() []string {
+	return append([]string(nil), v.versions...)
+}
+
+// Validate validates data against the schema of every version.
+func (v *VersionedSchemas) Validate(data interface{}) VersionResults {
+	results := make(VersionResults, len(v.versions))
+	for _, version := range v.versions {
+		results[version] = v.schemas[version].Validate(data)
+	}
+	return results
+}
+
+// ValidateAll validates each of objects against the schema of every version,
+// and returns their results in the same order.
+func (v *VersionedSchemas) ValidateAll(objects []interface{}) VersionMatrix {
+	matrix := make(VersionMatrix, len(objects))
+	for i, data := range objects {
+		matrix[i] = v.Validate(data)
+	}
+	return matrix
+}
+
+// VersionResults holds the results of validating an object by version.
+type VersionResults map[string]*Result
+
+// ValidVersions returns the versions the object is valid in, sorted.
+func (r VersionResults) ValidVersions() []string {
+	var valid []string
+	for version, result := range r {
+		if result.IsValid() {
+			valid = append(valid, version)
+		}
+	}
+	sort.Strings(valid)
+	return valid
+}
+
+// VersionMatrix holds the results of validating several objects by version,
+// one row per object.
+type VersionMatrix []VersionResults
+
+// ValidVersions returns the versions that all objects are valid in, sorted,
+// i.e. the versions they can all be converted to. It returns nil if there
+// are no objects.
+func (m VersionMatrix) ValidVersions() []string {
+	if len(m) == 0 {
+		return nil
+	}
+	var valid []string
+	for _, version := range m[0].ValidVersions() {
+		inAll := true
+		for _, results := range m[1:] {
+			if result, found := results[version]; !found || !result.IsValid() {
+				inAll = false
+				break
+			}
+		}
+		if inAll {
+			valid = append(valid, version)
+		}
+	}
+	return valid
+}
+
+// Invalid returns the indexes of the objects that are not valid in version,
+// in increasing order.
+func (m VersionMatrix) Invalid(version string) []int {
+	var invalid []int
+	for i, results := range m {
+		if result, found := results[version]; !found || !result.IsValid() {
+			invalid = append(invalid, i)
+		}
+	}
+	return invalid
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	"k8s.io/kube-openapi/pkg/validation/strfmt"
+)
+
+func TestVersionedSchemas(t *testing.T) {
+	replicas := func(maximum float64) *spec.Schema {
+		return &spec.Schema{SchemaProps: spec.SchemaProps{
+			Type: spec.StringOrArray{"object"},
+			Properties: map[string]spec.Schema{
+				"replicas": *spec.Int64Property().WithMaximum(maximum, false),
+			},
+		}}
+	}
+	v1 := replicas(10)
+	v1.Required = []string{"replicas"}
+	versions, err := CompileVersions(map[string]*spec.Schema{
+		"v1":      v1,
+		"v1beta1": replicas(100),
+		"v1beta2": replicas(5),
+	}, strfmt.Default)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"v1", "v1beta1", "v1beta2"}, versions.Versions())
+
+	results := versions.Validate(map[string]interface{}{"replicas": int64(7)})
+	assert.Equal(t, []string{"v1", "v1beta1"}, results.ValidVersions())
+	assert.Equal(t, []string{"replicas in body should be less than or equal to 5"}, resultErrors(results["v1beta2"]))
+
+	matrix := versions.ValidateAll([]interface{}{
+		map[string]interface{}{"replicas": int64(3)},
+		map[string]interface{}{"replicas": int64(50)},
+		map[string]interface{}{},
+	})
+	require.Len(t, matrix, 3)
+	assert.Equal(t, []string{"v1", "v1beta1", "v1beta2"}, matrix[0].ValidVersions())
+	assert.Equal(t, []string{"v1beta1"}, matrix[1].ValidVersions())
+	assert.Equal(t, []string{"v1beta1", "v1beta2"}, matrix[2].ValidVersions())
+	assert.Equal(t, []string{"v1beta1"}, matrix.ValidVersions())
+	assert.Equal(t, []int{1, 2}, matrix.Invalid("v1"))
+	assert.Equal(t, []int{1}, matrix.Invalid("v1beta2"))
+	assert.Nil(t, matrix.Invalid("v1beta1"))
+	assert.Equal(t, []int{0, 1, 2}, matrix.Invalid("v2"))
+
+	assert.Nil(t, VersionMatrix(nil).ValidVersions())
+}
+
+func TestCompileVersionsErrors(t *testing.T) {
+	_, err := CompileVersions(map[string]*spec.Schema{
+		"v1": spec.RefSchema("#/definitions/Foo"),
+	}, strfmt.Default)
+	assert.EqualError(t, err, "version v1: schema: schema references not supported: #/definitions/Foo")
+}